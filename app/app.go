@@ -0,0 +1,286 @@
+// Package app 负责构建应用依赖的配置、日志、数据库、Redis、指标和追踪，并管理它们的生命周期。
+//
+// 依赖在 New 中通过构造函数显式创建，然后作为参数传递给路由和控制器，而不是在 init 中初始化包级全局变量。
+// 需要在启动时运行或在停止时释放的资源通过 Append 注册生命周期钩子。
+package app
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"io"
+	"orca/conf"
+	"orca/middleware"
+	"orca/models"
+	"orca/pkg/cache"
+	"orca/pkg/cors"
+	"orca/pkg/db"
+	"orca/pkg/errors"
+	"orca/pkg/health"
+	"orca/pkg/idempotency"
+	"orca/pkg/metrics"
+	"orca/pkg/ratelimit"
+	"orca/pkg/softdelete"
+	"orca/pkg/tracing"
+	"sync"
+)
+
+// Hook 生命周期钩子，Start 在应用启动时按注册顺序调用，Stop 在应用停止时按相反顺序调用，两者都可以为空
+type Hook struct {
+	Name  string
+	Start func(ctx context.Context) error
+	Stop  func(ctx context.Context) error
+}
+
+// App 应用容器，持有所有共享的依赖
+type App struct {
+	Env    string
+	Logger *zap.Logger
+	Mysql  *gorm.DB
+	Redis  *redis.Client
+	// Health 就绪检查的检查项，模块可以在注册路由时添加自己的检查项
+	Health *health.Registry
+	// Metrics 导出到 /metrics 的指标，数据库和 Redis 的耗时由 New 中注册的插件和钩子记录
+	Metrics *metrics.Metrics
+	// Tracing 追踪的 TracerProvider，数据库和 Redis 的调用由 New 中注册的插件和钩子创建 span
+	Tracing *tracing.Provider
+	// RateLimiter 在 Redis 中执行限流脚本，RateLimits 为配置中的限流策略，未启用限流时为空
+	RateLimiter *ratelimit.Limiter
+	RateLimits  []*ratelimit.Policy
+	// Idempotency 保存幂等键和响应，未启用幂等性时为空
+	Idempotency *idempotency.Store
+	// Cors 跨域策略，配置文件变化时重新加载
+	Cors *cors.CORS
+	// Cache 响应缓存。未启用时路由不读写缓存，但资源变化时仍然通过它使缓存失效，避免启用后读取到旧的响应
+	Cache *cache.Cache
+
+	// logFile 日志文件，停止时在 Logger.Sync 之后关闭
+	logFile io.Closer
+	hooks   []Hook
+	// started 已经成功启动的钩子数量，Stop 只停止这些钩子
+	started int
+}
+
+// New 加载 env 对应的配置并创建所有依赖，任何依赖创建失败时释放已创建的依赖并返回错误
+func New(env string) (*App, error) {
+	conf.InitConfig(env)
+	a := &App{
+		Env:     env,
+		Health:  health.New(conf.GetDuration("health.timeout", "2s")),
+		Metrics: metrics.New(),
+	}
+	if err := a.build(); err != nil {
+		a.Close()
+		return nil, err
+	}
+
+	a.Append(Hook{Name: "logger", Stop: func(ctx context.Context) error {
+		// lumberjack 的 Sync 不做任何事，关闭文件才能确保日志落盘
+		_ = a.Logger.Sync()
+		return a.logFile.Close()
+	}})
+	// 追踪在数据库和 Redis 之后停止，导出停止过程中产生的 span
+	a.Append(Hook{Name: "tracing", Stop: func(ctx context.Context) error {
+		return a.Tracing.Shutdown(ctx)
+	}})
+	a.Append(Hook{Name: "mysql", Stop: func(ctx context.Context) error {
+		sqlDB, err := a.Mysql.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.Close()
+	}})
+	a.Append(Hook{Name: "redis", Stop: func(ctx context.Context) error {
+		return a.Redis.Close()
+	}})
+	a.Append(a.purger())
+
+	a.Health.Register("mysql", func(ctx context.Context) error {
+		sqlDB, err := a.Mysql.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+	a.Health.Register("redis", func(ctx context.Context) error {
+		return a.Redis.Ping(ctx).Err()
+	})
+	return a, nil
+}
+
+// build 依次创建日志、追踪、数据库、Redis、限流、幂等性、响应缓存和跨域策略，并为数据库和 Redis 注册指标和追踪
+func (a *App) build() error {
+	var err error
+	if a.Logger, a.logFile, err = middleware.NewLogger(); err != nil {
+		return errors.Wrap(err, "初始化日志失败")
+	}
+	// 替换zap包中全局的logger实例，后续在其他包中只需使用zap.L()调用即可
+	zap.ReplaceGlobals(a.Logger)
+
+	a.Tracing, err = tracing.NewProvider(context.Background(), tracing.Config{
+		ServiceName: conf.GetString("tracing.serviceName", "orca"),
+		Exporter:    conf.GetString("tracing.exporter", tracing.ExporterNone),
+		Endpoint:    conf.GetString("tracing.endpoint", "localhost:4318"),
+		Insecure:    conf.GetBool("tracing.insecure"),
+		File:        conf.GetString("tracing.file", "./logs/traces.json"),
+		SampleRatio: conf.GetFloat64("tracing.sampleRatio", 1),
+	})
+	if err != nil {
+		return errors.Wrap(err, "初始化追踪失败")
+	}
+
+	if a.Mysql, err = db.NewMysql(); err != nil {
+		return err
+	}
+	if err = a.Mysql.Use(metrics.NewGormPlugin(a.Metrics)); err != nil {
+		return err
+	}
+	if err = a.Mysql.Use(tracing.NewGormPlugin(a.Tracing)); err != nil {
+		return err
+	}
+
+	if a.Redis, err = db.NewRedis(context.Background()); err != nil {
+		return err
+	}
+	a.Redis.AddHook(metrics.NewRedisHook(a.Metrics))
+	a.Redis.AddHook(tracing.NewRedisHook(a.Tracing))
+
+	a.RateLimiter = ratelimit.New(a.Redis, conf.GetString("rateLimit.prefix", "orca:ratelimit:"))
+	if conf.GetBool("rateLimit.enabled") {
+		if err = conf.UnmarshalKey("rateLimit.policies", &a.RateLimits); err != nil {
+			return errors.Wrap(err, "读取限流策略失败")
+		}
+		for _, policy := range a.RateLimits {
+			if err = policy.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	if conf.GetBool("idempotency.enabled", true) {
+		a.Idempotency = idempotency.NewStore(a.Redis, conf.GetString("idempotency.prefix", "orca:idempotency:"),
+			conf.GetDuration("idempotency.lockTimeout", "1m"), conf.GetDuration("idempotency.retention", "24h"))
+	}
+	a.Cache = cache.New(a.Redis, conf.GetString("cache.prefix", "orca:cache:"), conf.GetDuration("cache.ttl", "5m"))
+
+	corsConfig, err := middleware.LoadCorsConfig()
+	if err != nil {
+		return errors.Wrap(err, "读取跨域策略失败")
+	}
+	if a.Cors, err = cors.New(corsConfig); err != nil {
+		return err
+	}
+	conf.OnChange(a.reloadCors)
+	return nil
+}
+
+// reloadCors 在配置文件变化后重新加载跨域策略，新的策略不正确时继续使用原来的策略
+func (a *App) reloadCors() {
+	corsConfig, err := middleware.LoadCorsConfig()
+	if err == nil {
+		err = a.Cors.Reload(corsConfig)
+	}
+	if err != nil {
+		a.Logger.Error("重新加载跨域策略失败，继续使用原来的策略", zap.Error(err))
+		return
+	}
+	a.Logger.Info("已重新加载跨域策略")
+}
+
+// Append 注册生命周期钩子，需要在 Start 之前调用
+func (a *App) Append(hook Hook) {
+	a.hooks = append(a.hooks, hook)
+}
+
+// Start 按注册顺序启动所有钩子，某个钩子启动失败时停止已经启动的钩子并返回错误
+func (a *App) Start(ctx context.Context) error {
+	for _, hook := range a.hooks[a.started:] {
+		if hook.Start != nil {
+			if err := hook.Start(ctx); err != nil {
+				_ = a.Stop(ctx)
+				return errors.Wrapf(err, "启动 %s 失败", hook.Name)
+			}
+		}
+		a.started++
+	}
+	return nil
+}
+
+// Stop 按注册的相反顺序停止已经启动的钩子，某个钩子停止失败时继续停止其余的钩子，并返回第一个错误
+func (a *App) Stop(ctx context.Context) error {
+	var first error
+	for ; a.started > 0; a.started-- {
+		hook := a.hooks[a.started-1]
+		if hook.Stop == nil {
+			continue
+		}
+		if err := hook.Stop(ctx); err != nil {
+			zap.L().Error(fmt.Sprintf("停止 %s 失败", hook.Name), zap.Error(err))
+			if first == nil {
+				first = errors.Wrapf(err, "停止 %s 失败", hook.Name)
+			}
+		}
+	}
+	return first
+}
+
+// Close 释放 New 中已经创建的连接，用于 New 失败时的清理，以及不启动应用、只执行一次性命令后的清理
+func (a *App) Close() {
+	if a.Redis != nil {
+		_ = a.Redis.Close()
+	}
+	if a.Mysql != nil {
+		if sqlDB, err := a.Mysql.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+	if a.Tracing != nil {
+		_ = a.Tracing.Shutdown(context.Background())
+	}
+	if a.logFile != nil {
+		_ = a.Logger.Sync()
+		_ = a.logFile.Close()
+	}
+}
+
+// purger 定期清理回收站的后台任务，停止时等待正在进行的清理结束
+func (a *App) purger() Hook {
+	var cancel context.CancelFunc
+	var wg sync.WaitGroup
+	return Hook{
+		Name: "purger",
+		Start: func(context.Context) error {
+			var trashable []any
+			for _, model := range models.Trashable() {
+				trashable = append(trashable, model)
+			}
+
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				softdelete.RunPurger(ctx, a.Mysql,
+					conf.GetDuration("trash.retention", "720h"),
+					conf.GetDuration("trash.purgeInterval", "1h"),
+					trashable...)
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			cancel()
+			done := make(chan struct{})
+			go func() {
+				wg.Wait()
+				close(done)
+			}()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
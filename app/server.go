@@ -0,0 +1,85 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"net/http"
+	"orca/conf"
+	"orca/pkg/errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// newServer 根据配置创建 HTTP 服务器，超时时间为 0 表示不限制
+func newServer(handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%s", conf.GetString("server.port")),
+		Handler:           handler,
+		ReadTimeout:       conf.GetDuration("server.readTimeout", "15s"),
+		ReadHeaderTimeout: conf.GetDuration("server.readHeaderTimeout", "5s"),
+		WriteTimeout:      conf.GetDuration("server.writeTimeout", "30s"),
+		IdleTimeout:       conf.GetDuration("server.idleTimeout", "60s"),
+	}
+}
+
+// Run 启动所有钩子并在 server.port 上提供 handler 的服务，直到收到 SIGINT 或 SIGTERM。
+// 收到信号后就绪检查立即返回未就绪，等待 server.drainDelay 让负载均衡器摘除该实例，
+// 然后停止接受新的连接，在 server.shutdownTimeout 内等待正在处理的请求完成，
+// 然后按相反顺序停止后台任务、关闭 Redis 和数据库连接，最后刷新日志。
+func (a *App) Run(handler http.Handler) error {
+	server := newServer(handler)
+	// serveErr 在服务器意外退出时接收错误，正常关闭时 ListenAndServe 返回 http.ErrServerClosed
+	serveErr := make(chan error, 1)
+	a.Append(Hook{
+		Name: "http",
+		Start: func(context.Context) error {
+			go func() {
+				if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+					serveErr <- err
+				}
+			}()
+			zap.L().Info("HTTP 服务已启动", zap.String("addr", server.Addr))
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			if err := server.Shutdown(ctx); err != nil {
+				// 超过期限仍未完成的请求直接断开
+				_ = server.Close()
+				return err
+			}
+			return nil
+		},
+	})
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	if err := a.Start(context.Background()); err != nil {
+		return err
+	}
+
+	var err error
+	select {
+	case sig := <-signals:
+		zap.L().Info("收到退出信号，开始关闭服务", zap.String("signal", sig.String()))
+		a.Health.Drain()
+		select {
+		case <-time.After(conf.GetDuration("server.drainDelay", "0s")):
+		case <-signals:
+			// 再次收到信号时不再等待
+		}
+	case err = <-serveErr:
+		zap.L().Error("HTTP 服务异常退出", zap.Error(err))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), conf.GetDuration("server.shutdownTimeout", "30s"))
+	defer cancel()
+	if stopErr := a.Stop(ctx); err == nil {
+		err = stopErr
+	}
+	return err
+}
@@ -1,9 +1,11 @@
 package conf
 
 import (
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/cast"
 	viperlib "github.com/spf13/viper" // 自定义包名，避免与内置 viper 实例冲突
 	"os"
+	"sync"
 	"time"
 )
 
@@ -22,6 +24,12 @@ type ConfigFunc func() map[string]interface{}
 // ConfigFuncs 先加载到此数组，loadConfig 再动态生成配置信息
 var ConfigFuncs map[string]ConfigFunc
 
+// changeHandlers 配置文件变化后依次调用的回调
+var (
+	changeHandlers []func()
+	changeMutex    sync.Mutex
+)
+
 func init() {
 	// 1. 初始化 Viper 库
 	viper = viperlib.New()
@@ -75,9 +83,24 @@ func loadYamlConfig(envSuffix string) {
 	}
 
 	// 监控配置文件变化并自动重新加载
+	viper.OnConfigChange(func(fsnotify.Event) {
+		changeMutex.Lock()
+		handlers := append([]func(){}, changeHandlers...)
+		changeMutex.Unlock()
+		for _, handler := range handlers {
+			handler()
+		}
+	})
 	viper.WatchConfig()
 }
 
+// OnChange 注册配置文件变化并重新加载后的回调，回调在监听配置文件的协程中执行
+func OnChange(handler func()) {
+	changeMutex.Lock()
+	defer changeMutex.Unlock()
+	changeHandlers = append(changeHandlers, handler)
+}
+
 // 通用的内部获取配置值函数
 func internalGet(path string, defaultValue ...interface{}) interface{} {
 	// 如果配置不存在，则返回默认值
@@ -139,3 +162,8 @@ func GetStringMap(path string, defaultValue ...interface{}) map[string]interface
 func GetStringMapString(path string, defaultValue ...interface{}) map[string]string {
 	return cast.ToStringMapString(internalGet(path, defaultValue...))
 }
+
+// UnmarshalKey 将 path 下的配置解码到 rawVal 中，用于列表等结构化的配置，字符串形式的时长会被解析为 time.Duration
+func UnmarshalKey(path string, rawVal interface{}) error {
+	return viper.UnmarshalKey(path, rawVal)
+}
@@ -0,0 +1,12 @@
+package health
+
+import "orca/pkg/health"
+
+type healthController struct {
+	checks *health.Registry
+}
+
+// New 创建健康检查控制器，就绪检查执行 checks 中注册的所有检查项
+func New(checks *health.Registry) *healthController {
+	return &healthController{checks: checks}
+}
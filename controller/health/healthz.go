@@ -0,0 +1,12 @@
+package health
+
+import (
+	"github.com/gin-gonic/gin"
+	"orca/pkg/health"
+	"orca/pkg/response"
+)
+
+// Healthz 存活检查，进程能够处理请求即返回成功，不检查任何依赖
+func (h *healthController) Healthz(c *gin.Context) {
+	response.Success(c, gin.H{"status": health.StatusUp}, "服务正在运行")
+}
@@ -0,0 +1,24 @@
+package health
+
+import (
+	"github.com/gin-gonic/gin"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/health"
+	"orca/pkg/response"
+)
+
+// Readyz 就绪检查，返回每个依赖的状态和检查耗时。
+// 任一依赖不可用或服务正在关闭时返回 503，负载均衡器据此停止转发请求。
+func (h *healthController) Readyz(c *gin.Context) {
+	report := h.checks.Check(c)
+	if report.Status != health.StatusUp {
+		if report.Draining {
+			response.FailWithData(c, errors.WithCode(code.ErrServiceUnavailable, "服务正在关闭"), report)
+			return
+		}
+		response.FailWithData(c, errors.WithCode(code.ErrServiceUnavailable, "存在不可用的依赖"), report)
+		return
+	}
+	response.Success(c, report, "服务已就绪")
+}
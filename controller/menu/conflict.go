@@ -0,0 +1,18 @@
+package menu
+
+import (
+	"github.com/gin-gonic/gin"
+	"orca/pkg/etag"
+	"orca/pkg/response"
+)
+
+// failConflict 返回 ETag 校验失败的错误，并在 data 中返回菜单的最新状态，便于客户端合并修改后重试
+func (m *menuController) failConflict(c *gin.Context, err error, menuID uint64) {
+	current, getErr := m.menus.GetByID(c, menuID)
+	if getErr != nil {
+		response.Fail(c, err)
+		return
+	}
+	c.Header(etag.HeaderETag, current.ETag())
+	response.FailWithData(c, err, current)
+}
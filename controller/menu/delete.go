@@ -2,35 +2,70 @@ package menu
 
 import (
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 	"orca/models"
 	"orca/pkg/code"
-	"orca/pkg/db"
 	"orca/pkg/errors"
+	"orca/pkg/etag"
 	"orca/pkg/response"
+	"orca/service"
+	"strconv"
 )
 
+// Delete 删除菜单。默认情况下菜单存在子菜单或角色绑定时拒绝删除，
+// 可以通过 mode=cascade 级联删除子孙菜单，或通过 mode=reparent 将子菜单移动到上级菜单下；
+// dryRun=true 时只返回将要执行的变更。
 func (m *menuController) Delete(c *gin.Context) {
-	var menu []models.Menu
 	codes := c.QueryArray("codes")
 	if len(codes) == 0 {
 		response.Fail(c, errors.WithCode(code.ErrValidate, "无效的菜单ID"))
 		return
 	}
-	if db.Mysql.Model(&models.Menu{}).Where("code in ?", codes).Find(&menu).RowsAffected != int64(len(codes)) {
-		response.Fail(c, errors.WithCode(code.ErrValidate, "存在无效的菜单ID"))
+
+	mode := models.MenuDeleteMode(c.DefaultQuery("mode", string(models.EnumMenuDeleteModeRestrict)))
+	switch mode {
+	case models.EnumMenuDeleteModeRestrict, models.EnumMenuDeleteModeCascade, models.EnumMenuDeleteModeReparent:
+	default:
+		response.Fail(c, errors.WithCode(code.ErrValidate, "无效的删除模式：%s", mode))
 		return
 	}
-	err := db.Mysql.Transaction(func(tx *gorm.DB) error {
-		if err := db.Mysql.Where("code in ?", codes).Delete(&models.Menu{}).Error; err != nil {
-			return errors.WithCode(code.ErrValidate, "删除菜单时，发生错误")
-		}
-		return nil
-	})
 
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dryRun", "false"))
 	if err != nil {
-		response.Fail(c, err)
+		response.Fail(c, errors.WithCode(code.ErrValidate, "无效的dryRun参数"))
+		return
 	}
 
-	response.Success(c, nil, "删除菜单成功")
+	// data 在删除失败时返回给客户端，包括阻止删除的依赖项或 ETag 校验失败时菜单的最新状态
+	var data any
+	plan, blockers, err := m.menus.Delete(actor(c), codes, service.MenuDeleteOptions{
+		Mode:   mode,
+		DryRun: dryRun,
+		Precondition: func(menus []*models.Menu) error {
+			tags := make([]string, 0, len(menus))
+			for _, menu := range menus {
+				tags = append(tags, menu.ETag())
+			}
+			if err := etag.Precondition(c, tags...); err != nil {
+				data = menus
+				return err
+			}
+			return nil
+		},
+	})
+	if blockers != nil {
+		data = blockers
+	}
+	if err != nil {
+		response.FailWithData(c, err, data)
+		return
+	}
+
+	if dryRun {
+		response.Success(c, plan, "预览删除菜单成功")
+		return
+	}
+
+	m.invalidate(c)
+
+	response.Success(c, plan, "删除菜单成功")
 }
@@ -0,0 +1,49 @@
+package menu
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+	"orca/models"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"strings"
+)
+
+const (
+	formatJSON = "json"
+	formatYAML = "yaml"
+)
+
+// documentFormat 解析文档格式，优先使用查询参数 format，其次根据 Content-Type 判断，默认为 JSON
+func documentFormat(c *gin.Context) (string, error) {
+	format := strings.ToLower(c.Query("format"))
+	if format == "" {
+		format = formatJSON
+		if strings.Contains(c.ContentType(), "yaml") {
+			format = formatYAML
+		}
+	}
+	switch format {
+	case formatJSON, formatYAML:
+		return format, nil
+	case "yml":
+		return formatYAML, nil
+	default:
+		return "", errors.WithCode(code.ErrValidate, "不支持的文档格式：%s", format)
+	}
+}
+
+func marshalDocument(doc *models.MenuDocument, format string) ([]byte, error) {
+	if format == formatYAML {
+		return yaml.Marshal(doc)
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func unmarshalDocument(data []byte, format string, doc *models.MenuDocument) error {
+	if format == formatYAML {
+		return yaml.Unmarshal(data, doc)
+	}
+	return json.Unmarshal(data, doc)
+}
@@ -0,0 +1,38 @@
+package menu
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/response"
+)
+
+// Export 以 JSON 或 YAML 格式导出完整的菜单树及其角色绑定和翻译
+func (m *menuController) Export(c *gin.Context) {
+	format, err := documentFormat(c)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	doc, err := m.menus.Export(c)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	data, err := marshalDocument(doc, format)
+	if err != nil {
+		response.Fail(c, errors.WithCode(code.ErrInternalServer, "导出菜单时，序列化文档发生错误"))
+		return
+	}
+
+	contentType := "application/json; charset=utf-8"
+	if format == formatYAML {
+		contentType = "application/yaml; charset=utf-8"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="menus.%s"`, format))
+	c.Data(http.StatusOK, contentType, data)
+}
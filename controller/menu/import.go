@@ -0,0 +1,56 @@
+package menu
+
+import (
+	"github.com/gin-gonic/gin"
+	"orca/models"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/response"
+	"strconv"
+)
+
+// Import 导入菜单文档，按 Code 匹配已有菜单进行创建或更新。
+// deleteMissing=true 时删除文档中不存在的菜单；dryRun=true 时在事务中执行导入并回滚，只返回变更结果。
+func (m *menuController) Import(c *gin.Context) {
+	format, err := documentFormat(c)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dryRun", "false"))
+	if err != nil {
+		response.Fail(c, errors.WithCode(code.ErrValidate, "无效的dryRun参数"))
+		return
+	}
+	deleteMissing, err := strconv.ParseBool(c.DefaultQuery("deleteMissing", "false"))
+	if err != nil {
+		response.Fail(c, errors.WithCode(code.ErrValidate, "无效的deleteMissing参数"))
+		return
+	}
+
+	var doc models.MenuDocument
+	data, err := c.GetRawData()
+	if err == nil {
+		err = unmarshalDocument(data, format, &doc)
+	}
+	if err != nil {
+		response.Fail(c, errors.WithCode(code.ErrBind, "导入菜单时，文档解析错误"))
+		return
+	}
+
+	result, err := m.menus.Import(actor(c), &doc, deleteMissing, dryRun)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	if dryRun {
+		response.Success(c, result, "预览导入菜单成功")
+		return
+	}
+
+	m.invalidate(c)
+
+	response.Success(c, result, "导入菜单成功")
+}
@@ -3,33 +3,40 @@ package menu
 import (
 	"github.com/gin-gonic/gin"
 	"orca/models"
-	"orca/pkg/code"
-	"orca/pkg/db"
 	"orca/pkg/errors"
+	"orca/pkg/query"
 	"orca/pkg/response"
-	"strconv"
 )
 
-func (m *menuController) List(c *gin.Context) {
-	var menuList models.MenuList
-	code_ := c.Query("code")
-	label := c.Query("label")
-	type_ := c.Query("type")
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	offset := (page - 1) * limit
-
-	query := db.Mysql.Table("menu").Model(&models.Menu{}).
-		Where("code like ?", "%"+code_+"%").
-		Where("label like ?", "%"+label+"%").
-		Where("type like ?", "%"+type_+"%")
+// ListOptions 菜单列表允许的排序和过滤字段，也用于生成接口文档中的查询参数
+var ListOptions = &query.Options{
+	Model: &models.Menu{},
+	Fields: map[string]query.Field{
+		"code":      {Column: "code", Operators: []query.Operator{query.Eq, query.In, query.Like}, Sortable: true},
+		"label":     {Column: "label", Operators: []query.Operator{query.Eq, query.Like}, Sortable: true},
+		"type":      {Column: "type", Operators: []query.Operator{query.Eq, query.In}},
+		"parentId":  {Column: "parent_id", Operators: []query.Operator{query.Eq, query.In}},
+		"show":      {Column: "show", Operators: []query.Operator{query.Eq}},
+		"status":    {Column: "status", Operators: []query.Operator{query.Eq}},
+		"order":     {Column: "order", Operators: query.Range, Sortable: true},
+		"createdAt": {Column: "created_at", Operators: query.Range, Sortable: true},
+		"updatedAt": {Column: "updated_at", Operators: query.Range, Sortable: true},
+	},
+	Key:  "menu_id",
+	Sort: "order",
+}
 
-	if err := query.Count(&menuList.Total).Error; err != nil {
-		response.Fail(c, errors.WithCode(code.ErrInternalServer, "查询菜单总数时发生错误："))
+// List 分页查询菜单列表，支持页码和游标分页，参数格式见 query 包
+func (m *menuController) List(c *gin.Context) {
+	req, err := query.Parse(c.Request.URL.Query(), ListOptions)
+	if err != nil {
+		response.FailWithData(c, err, errors.Cause(err))
+		return
 	}
 
-	if err := query.Offset(offset).Limit(limit).Find(&menuList.Items).Error; err != nil {
-		response.Fail(c, errors.WithCode(code.ErrInternalServer, "查询菜单列表时发生错误："))
+	menuList, err := m.menus.List(c, req)
+	if err != nil {
+		response.Fail(c, err)
 		return
 	}
 
@@ -1,5 +1,55 @@
 package menu
 
-var Controller = &menuController{}
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"orca/controller/navigation"
+	"orca/middleware"
+	"orca/pkg/cache"
+	"orca/pkg/errors"
+	"orca/pkg/response"
+	"orca/pkg/tracing"
+	"orca/pkg/validation"
+	"orca/service"
+)
 
-type menuController struct{}
+// CacheTag 菜单的响应缓存使用的标签
+const CacheTag = "menu"
+
+type menuController struct {
+	menus      *service.MenuService
+	navigation *navigation.Cache
+	responses  *cache.Cache
+}
+
+// New 创建菜单控制器，控制器只负责解析请求和返回响应，业务逻辑由菜单服务实现。
+// 菜单发生变化后通过 navigation 和 responses 使导航缓存和菜单的响应缓存失效。
+func New(menus *service.MenuService, navigation *navigation.Cache, responses *cache.Cache) *menuController {
+	return &menuController{menus: menus, navigation: navigation, responses: responses}
+}
+
+// invalidate 使导航缓存和菜单的响应缓存失效，失败时只记录警告，缓存在过期后自然更新
+func (m *menuController) invalidate(c *gin.Context) {
+	if err := m.navigation.Invalidate(c); err != nil {
+		tracing.Logger(c).Warn("清除导航缓存失败", zap.Error(err))
+	}
+	if err := m.responses.Invalidate(c, CacheTag); err != nil {
+		tracing.Logger(c).Warn("清除菜单的响应缓存失败", zap.Error(err))
+	}
+}
+
+// fail 返回服务的错误，字段验证错误放在响应的 data 中
+func fail(c *gin.Context, err error) {
+	var errs validation.Errors
+	if errors.As(err, &errs) {
+		response.FailWithData(c, err, errs)
+		return
+	}
+	response.Fail(c, err)
+}
+
+// actor 返回携带当前用户ID的 context，服务在记录修订时从中读取操作人
+func actor(c *gin.Context) context.Context {
+	return service.WithActor(c, middleware.GetUserID(c))
+}
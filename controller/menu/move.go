@@ -0,0 +1,33 @@
+package menu
+
+import (
+	"github.com/gin-gonic/gin"
+	"orca/models"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/response"
+)
+
+// Move 批量调整菜单的父级和排序，用于前端拖拽菜单后一次性提交新的位置
+func (m *menuController) Move(c *gin.Context) {
+	var req models.MenuMoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errors.WithCode(code.ErrBind, "移动菜单时，数据绑定错误"))
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		fail(c, errors.WrapC(err, code.ErrValidate, "移动菜单时，字段验证错误"))
+		return
+	}
+
+	tree, err := m.menus.Move(actor(c), req.Positions)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	m.invalidate(c)
+
+	response.Success(c, tree, "移动菜单成功")
+}
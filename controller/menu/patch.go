@@ -0,0 +1,52 @@
+package menu
+
+import (
+	"github.com/gin-gonic/gin"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/etag"
+	"orca/pkg/patch"
+	"orca/pkg/response"
+)
+
+// Patch 部分更新菜单，支持 application/merge-patch+json 和 application/json-patch+json，
+// 只有发生变化的列会被写入数据库。
+func (m *menuController) Patch(c *gin.Context) {
+	// 菜单的翻译会被一起加载，补丁中的 translations 与已有的翻译合并
+	menu, err := m.menus.Get(c, c.Param("code"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	if err := etag.Precondition(c, menu.ETag()); err != nil {
+		m.failConflict(c, err, menu.MenuID)
+		return
+	}
+
+	version := menu.Version
+	fields, err := patch.Apply(c, menu)
+	if err != nil {
+		response.Fail(c, errors.WrapC(err, code.ErrBind, "更新菜单时，补丁应用错误：%s", err.Error()))
+		return
+	}
+	if len(fields) == 0 {
+		response.Success(c, menu, "菜单没有变化")
+		return
+	}
+
+	err = m.menus.Patch(actor(c), menu, version, fields)
+	if errors.IsCode(err, code.ErrPreconditionFailed) {
+		m.failConflict(c, err, menu.MenuID)
+		return
+	}
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	m.invalidate(c)
+
+	c.Header(etag.HeaderETag, menu.ETag())
+	response.Success(c, menu, "更新菜单成功")
+}
@@ -0,0 +1,38 @@
+package menu
+
+import (
+	"github.com/gin-gonic/gin"
+	"orca/models"
+	"orca/pkg/errors"
+	"orca/pkg/query"
+	"orca/pkg/response"
+)
+
+// RevisionOptions 菜单修订记录列表允许的排序和过滤字段，也用于生成接口文档中的查询参数
+var RevisionOptions = &query.Options{
+	Model: &models.MenuRevision{},
+	Fields: map[string]query.Field{
+		"revisionId": {Column: "revision_id", Operators: query.Range, Sortable: true},
+		"action":     {Column: "action", Operators: []query.Operator{query.Eq, query.In}},
+		"actorId":    {Column: "actor_id", Operators: []query.Operator{query.Eq}},
+		"createdAt":  {Column: "created_at", Operators: query.Range, Sortable: true},
+	},
+	Key:  "revision_id",
+	Sort: "-revisionId",
+}
+
+// Revisions 分页查询菜单的修订记录，默认按时间倒序排列。已删除的菜单也可以查询。
+func (m *menuController) Revisions(c *gin.Context) {
+	req, err := query.Parse(c.Request.URL.Query(), RevisionOptions)
+	if err != nil {
+		response.FailWithData(c, err, errors.Cause(err))
+		return
+	}
+
+	revisions, err := m.menus.Revisions(c, c.Param("code"), req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c, revisions, "查询菜单修订记录成功")
+}
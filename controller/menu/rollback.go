@@ -0,0 +1,37 @@
+package menu
+
+import (
+	"github.com/gin-gonic/gin"
+	"orca/models"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/etag"
+	"orca/pkg/response"
+	"strconv"
+)
+
+// Rollback 将菜单恢复到指定修订时的状态，包括父级菜单、角色绑定和翻译，已删除的菜单会被恢复
+func (m *menuController) Rollback(c *gin.Context) {
+	revisionID, err := strconv.ParseUint(c.Param("revision"), 10, 64)
+	if err != nil {
+		response.Fail(c, errors.WithCode(code.ErrValidate, "无效的修订ID"))
+		return
+	}
+
+	menu, err := m.menus.Rollback(actor(c), c.Param("code"), revisionID, func(menu *models.Menu) error {
+		return etag.Precondition(c, menu.ETag())
+	})
+	if errors.IsCode(err, code.ErrPreconditionFailed) {
+		m.failConflict(c, err, menu.MenuID)
+		return
+	}
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	m.invalidate(c)
+
+	c.Header(etag.HeaderETag, menu.ETag())
+	response.Success(c, menu, "回滚菜单成功")
+}
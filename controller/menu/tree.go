@@ -0,0 +1,16 @@
+package menu
+
+import (
+	"github.com/gin-gonic/gin"
+	"orca/pkg/locale"
+	"orca/pkg/response"
+)
+
+func (m *menuController) Tree(c *gin.Context) {
+	tree, err := m.menus.Tree(c, locale.FromRequest(c))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c, tree, "查询菜单树成功")
+}
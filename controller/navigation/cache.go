@@ -0,0 +1,76 @@
+package navigation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"orca/models"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	cacheKeyPrefix  = "orca:navigation:"
+	cacheVersionKey = "orca:navigation:version"
+	cacheTTL        = 30 * time.Minute
+)
+
+// Cache 导航缓存，以角色集合和语言为键缓存导航信息
+type Cache struct {
+	redis *redis.Client
+}
+
+// NewCache 创建导航缓存
+func NewCache(client *redis.Client) *Cache {
+	return &Cache{redis: client}
+}
+
+// key 根据角色集合和语言生成缓存键，拥有相同角色集合且使用相同语言的用户共享同一份缓存。
+// 键中包含缓存版本号，Invalidate 递增版本号后旧的缓存自然失效。
+func (c *Cache) key(ctx context.Context, roleIDs []uint64, locale string) (string, error) {
+	version, err := c.redis.Get(ctx, cacheVersionKey).Int64()
+	if err != nil && err != redis.Nil {
+		return "", err
+	}
+
+	ids := make([]uint64, len(roleIDs))
+	copy(ids, roleIDs)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatUint(id, 10)
+	}
+	return fmt.Sprintf("%s%d:%s:%s", cacheKeyPrefix, version, locale, strings.Join(parts, ",")), nil
+}
+
+// get 读取缓存的导航信息，缓存不存在时返回 nil
+func (c *Cache) get(ctx context.Context, key string) (*models.Navigation, error) {
+	data, err := c.redis.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var nav models.Navigation
+	if err := json.Unmarshal(data, &nav); err != nil {
+		return nil, err
+	}
+	return &nav, nil
+}
+
+func (c *Cache) set(ctx context.Context, key string, nav *models.Navigation) error {
+	data, err := json.Marshal(nav)
+	if err != nil {
+		return err
+	}
+	return c.redis.Set(ctx, key, data, cacheTTL).Err()
+}
+
+// Invalidate 使所有角色集合的导航缓存失效，菜单或 role_menu 发生变化后需要调用
+func (c *Cache) Invalidate(ctx context.Context) error {
+	return c.redis.Incr(ctx, cacheVersionKey).Err()
+}
@@ -0,0 +1,48 @@
+package navigation
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"orca/middleware"
+	"orca/models"
+	"orca/pkg/locale"
+	"orca/pkg/response"
+	"orca/pkg/tracing"
+)
+
+func (n *navigationController) Get(c *gin.Context) {
+	roleIDs, err := n.users.RoleIDs(c, middleware.GetUserID(c))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if len(roleIDs) == 0 {
+		response.Success(c, models.BuildNavigation(nil), "查询导航成功")
+		return
+	}
+
+	// 缓存不可用时降级为直接查询数据库
+	locale_ := locale.FromRequest(c)
+	key, err := n.cache.key(c, roleIDs, locale_)
+	if err != nil {
+		tracing.Logger(c).Warn("生成导航缓存键失败", zap.Error(err))
+	} else if nav, err := n.cache.get(c, key); err != nil {
+		tracing.Logger(c).Warn("读取导航缓存失败", zap.Error(err))
+	} else if nav != nil {
+		response.Success(c, nav, "查询导航成功")
+		return
+	}
+
+	nav, err := n.menus.Navigation(c, roleIDs, locale_)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	if key != "" {
+		if err := n.cache.set(c, key, nav); err != nil {
+			tracing.Logger(c).Warn("写入导航缓存失败", zap.Error(err))
+		}
+	}
+	response.Success(c, nav, "查询导航成功")
+}
@@ -0,0 +1,14 @@
+package navigation
+
+import "orca/service"
+
+type navigationController struct {
+	users *service.UserService
+	menus *service.MenuService
+	cache *Cache
+}
+
+// New 创建导航控制器
+func New(users *service.UserService, menus *service.MenuService, cache *Cache) *navigationController {
+	return &navigationController{users: users, menus: menus, cache: cache}
+}
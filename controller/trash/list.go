@@ -0,0 +1,45 @@
+package trash
+
+import (
+	"github.com/gin-gonic/gin"
+	"orca/models"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/response"
+	"orca/pkg/softdelete"
+	"orca/pkg/uow"
+	"strconv"
+)
+
+func (t *trashController) List(c *gin.Context) {
+	model, err := resource(c)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		response.Fail(c, errors.WithCode(code.ErrValidate, "无效的页码"))
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 100 {
+		response.Fail(c, errors.WithCode(code.ErrValidate, "每页数量必须在1到100之间"))
+		return
+	}
+
+	trashList := models.TrashList{Items: newSlice(model)}
+	query := softdelete.Trashed(uow.Conn(c, t.db)).Model(model)
+	if err := query.Count(&trashList.Total).Error; err != nil {
+		response.Fail(c, errors.WithCode(code.ErrInternalServer, "查询回收站总数时发生错误"))
+		return
+	}
+
+	if err := query.Order("deleted_at desc").Offset((page - 1) * limit).Limit(limit).Find(trashList.Items).Error; err != nil {
+		response.Fail(c, errors.WithCode(code.ErrInternalServer, "查询回收站列表时发生错误"))
+		return
+	}
+
+	response.Success(c, trashList, "查询回收站成功")
+}
@@ -0,0 +1,35 @@
+package trash
+
+import (
+	"github.com/gin-gonic/gin"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/response"
+	"orca/pkg/softdelete"
+	"orca/pkg/uow"
+	"time"
+)
+
+// Purge 物理删除回收站中的记录，未指定 ids 时清空该资源的回收站。
+// 菜单等树形资源只清理整个子树都在回收站中的记录，返回的数量可能少于指定的记录数。
+func (t *trashController) Purge(c *gin.Context) {
+	model, err := resource(c)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	ids, err := queryIDs(c)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	count, err := softdelete.Purge(uow.Conn(c, t.db), model, ids, time.Now())
+	if err != nil {
+		response.Fail(c, errors.WithCode(code.ErrInternalServer, "清理回收站时发生错误"))
+		return
+	}
+
+	response.Successf(c, nil, "已清理%d条记录", count)
+}
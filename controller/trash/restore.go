@@ -0,0 +1,75 @@
+package trash
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"orca/middleware"
+	"orca/models"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/response"
+	"orca/pkg/softdelete"
+	"orca/pkg/tracing"
+	"orca/pkg/uow"
+	"orca/service"
+)
+
+func (t *trashController) Restore(c *gin.Context) {
+	model, err := resource(c)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	ids, err := queryIDs(c)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if len(ids) == 0 {
+		response.Fail(c, errors.WithCode(code.ErrValidate, "请指定需要恢复的记录"))
+		return
+	}
+
+	if _, ok := model.(*models.Menu); ok {
+		err = t.menus.Restore(service.WithActor(c, middleware.GetUserID(c)), ids)
+	} else {
+		err = t.restore(c, model, ids)
+	}
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	if err := t.navigation.Invalidate(c); err != nil {
+		tracing.Logger(c).Warn("清除导航缓存失败", zap.Error(err))
+	}
+	if err := t.responses.Invalidate(c, model.TableName()); err != nil {
+		tracing.Logger(c).Warn("清除响应缓存失败", zap.Error(err))
+	}
+
+	response.Success(c, nil, "恢复记录成功")
+}
+
+// restore 恢复没有专门的服务处理的记录
+func (t *trashController) restore(ctx context.Context, model schema.Tabler, ids []uint64) error {
+	return t.unit.Do(ctx, func(ctx context.Context) error {
+		count, err := softdelete.Restore(uow.Conn(ctx, t.db), model, ids)
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return errors.WithCode(code.ErrConflict, "恢复的记录与现有记录冲突")
+		}
+		if errors.Is(err, softdelete.ErrParentTrashed) {
+			return errors.WithCode(code.ErrConflict, "父级记录仍在回收站中，请一起恢复")
+		}
+		if err != nil {
+			return errors.WithCode(code.ErrInternalServer, "恢复记录时发生错误")
+		}
+		if count != int64(len(ids)) {
+			return errors.WithCode(code.ErrNotFound, "存在不在回收站中的记录")
+		}
+		return nil
+	})
+}
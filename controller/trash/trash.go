@@ -0,0 +1,62 @@
+package trash
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"orca/controller/navigation"
+	"orca/models"
+	"orca/pkg/cache"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/uow"
+	"orca/service"
+	"reflect"
+	"strconv"
+)
+
+// trashController 回收站中的模型在请求时才能确定，因此直接使用数据库连接而不是仓储。
+// 菜单的恢复需要记录修订，由菜单服务处理。
+type trashController struct {
+	db         *gorm.DB
+	unit       *uow.UnitOfWork
+	menus      *service.MenuService
+	navigation *navigation.Cache
+	responses  *cache.Cache
+}
+
+// New 创建回收站控制器，恢复菜单等记录后通过 navigation 使导航缓存失效，
+// 并通过 responses 使以表名为标签的响应缓存失效
+func New(db *gorm.DB, menus *service.MenuService, navigation *navigation.Cache, responses *cache.Cache) *trashController {
+	return &trashController{db: db, unit: uow.New(db), menus: menus, navigation: navigation, responses: responses}
+}
+
+// resource 根据路径参数 resource（表名）查找对应的模型
+func resource(c *gin.Context) (schema.Tabler, error) {
+	name := c.Param("resource")
+	for _, model := range models.Trashable() {
+		if model.TableName() == name {
+			return model, nil
+		}
+	}
+	return nil, errors.WithCode(code.ErrNotFound, "资源（%s）不支持回收站", name)
+}
+
+// newSlice 创建用于接收查询结果的切片指针，例如 *[]*models.Menu
+func newSlice(model schema.Tabler) any {
+	return reflect.New(reflect.SliceOf(reflect.TypeOf(model))).Interface()
+}
+
+// queryIDs 解析查询参数中的 ids
+func queryIDs(c *gin.Context) ([]uint64, error) {
+	values := c.QueryArray("ids")
+	ids := make([]uint64, 0, len(values))
+	for _, value := range values {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, errors.WithCode(code.ErrValidate, "无效的ID：%s", value)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
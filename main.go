@@ -1,27 +1,64 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"orca/app"
 	"orca/conf"
-	"orca/middleware"
-	"orca/pkg/db"
+	"orca/pkg/route"
 	"orca/router"
+	"os"
+	"strings"
 )
 
-func init() {
-	conf.InitConfig(conf.Development)
-	middleware.InitLogger()
-	db.InitMysql()
-	db.InitRedis()
-}
-
 func main() {
+	syncPermissions := flag.Bool("sync-permissions", false, "将路由声明的权限同步为菜单中的按钮后退出")
+	dryRun := flag.Bool("dry-run", false, "与 -sync-permissions 一起使用，只输出将要创建的菜单")
+	flag.Parse()
+
+	a, err := app.New(conf.Development)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize the application: %s", err.Error()))
+	}
+
 	gin.SetMode(conf.GetString("server.mode"))
 	server := gin.Default()
-	router.Add(server)
-	err := server.Run(fmt.Sprintf(":%s", conf.GetString("server.port")))
+	// 只信任配置中的代理转发的 X-Forwarded-For，否则客户端可以伪造 IP 绕过按 IP 的限流
+	if err := server.SetTrustedProxies(conf.GetStringSlice("server.trustedProxies")); err != nil {
+		panic(fmt.Sprintf("Failed to set the trusted proxies: %s", err.Error()))
+	}
+	r := router.Add(server, a)
+
+	if *syncPermissions {
+		err := runSyncPermissions(a, r, *dryRun)
+		a.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to sync permissions: %+v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := a.Run(server); err != nil {
+		fmt.Fprintf(os.Stderr, "The service stopped with an error: %+v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runSyncPermissions 同步权限并输出新建的目录、按钮、孤立的按钮和冲突的模块
+func runSyncPermissions(a *app.App, r *route.Router, dryRun bool) error {
+	result, err := router.SyncPermissions(context.Background(), a, r, dryRun)
 	if err != nil {
-		panic(fmt.Sprintf("Failed to start the service: %s", err.Error()))
+		return err
+	}
+	if result.DryRun {
+		fmt.Println("Dry run, nothing was written.")
 	}
+	fmt.Printf("Directories (%d): %s\n", len(result.Directories), strings.Join(result.Directories, ", "))
+	fmt.Printf("Buttons (%d): %s\n", len(result.Created), strings.Join(result.Created, ", "))
+	fmt.Printf("Orphans (%d): %s\n", len(result.Orphans), strings.Join(result.Orphans, ", "))
+	fmt.Printf("Conflicts (%d): %s\n", len(result.Conflicts), strings.Join(result.Conflicts, ", "))
+	return nil
 }
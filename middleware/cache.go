@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"orca/pkg/cache"
+	"orca/pkg/tracing"
+)
+
+// HeaderCache 响应来自缓存时为 HIT，否则为 MISS
+const HeaderCache = "X-Cache"
+
+// Cache 缓存路由的 GET 响应，只缓存状态码为 200 的响应，需要在路由上单独启用。
+// 缓存键由路径、查询参数、Accept 和 Accept-Language 请求头以及 scope 返回的权限范围组成，
+// 权限不同的用户不会共享缓存；tags 为响应涉及的资源，资源变化后通过 cache.Invalidate 使缓存失效。
+// 同一个进程中相同缓存键的并发请求只有一个会执行处理函数，其余请求等待并使用它的响应。
+// scope 或 Redis 失败时不使用缓存，直接处理请求。
+func Cache(store *cache.Cache, scope func(c *gin.Context) (string, error), tags ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+		permissions, err := scope(c)
+		if err != nil {
+			tracing.Logger(c).Warn("获取缓存的权限范围失败", zap.Error(err))
+			c.Next()
+			return
+		}
+		key, err := store.Key(c, tags, c.Request.URL.Path, c.Request.URL.Query().Encode(),
+			c.GetHeader("Accept"), c.GetHeader("Accept-Language"), permissions)
+		if err != nil {
+			tracing.Logger(c).Warn("生成缓存键失败", zap.Error(err))
+			c.Next()
+			return
+		}
+
+		if entry, err := store.Get(c, key); err != nil {
+			tracing.Logger(c).Warn("读取响应缓存失败", zap.Error(err))
+		} else if entry != nil {
+			writeEntry(c, entry)
+			return
+		}
+
+		leader := false
+		entry, _, err := store.Do(key, func() (*cache.Entry, error) {
+			leader = true
+			return handleAndCache(c, store, key), nil
+		})
+		if leader {
+			return
+		}
+		if err != nil || entry == nil {
+			// 合并的请求没有可以使用的响应，例如处理失败，由当前请求自己处理
+			c.Next()
+			return
+		}
+		writeEntry(c, entry)
+	}
+}
+
+// handleAndCache 处理请求并在状态码为 200 时保存响应，返回 nil 表示响应不能被缓存
+func handleAndCache(c *gin.Context, store *cache.Cache, key string) *cache.Entry {
+	c.Header(HeaderCache, "MISS")
+	// 之前的中间件设置的响应头（例如请求ID和限流）属于当前请求，不需要缓存
+	before := make(map[string]bool, len(c.Writer.Header()))
+	for name := range c.Writer.Header() {
+		before[name] = true
+	}
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+	c.Next()
+
+	if recorder.Status() != http.StatusOK {
+		return nil
+	}
+	entry := &cache.Entry{Status: http.StatusOK, Header: http.Header{}, Body: recorder.body.Bytes()}
+	for name, values := range recorder.Header() {
+		if !before[name] {
+			entry.Header[name] = values
+		}
+	}
+	if err := store.Set(c, key, entry); err != nil {
+		tracing.Logger(c).Warn("写入响应缓存失败", zap.Error(err))
+	}
+	return entry
+}
+
+// writeEntry 使用缓存的响应响应请求，并跳过之后的处理函数
+func writeEntry(c *gin.Context, entry *cache.Entry) {
+	for name, values := range entry.Header {
+		c.Writer.Header()[name] = values
+	}
+	c.Header(HeaderCache, "HIT")
+	c.Writer.WriteHeader(entry.Status)
+	_, _ = c.Writer.Write(entry.Body)
+	c.Abort()
+}
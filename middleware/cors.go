@@ -1,26 +1,38 @@
 package middleware
 
 import (
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"time"
+	"orca/conf"
+	"orca/pkg/cors"
+	"orca/pkg/etag"
+	"orca/pkg/locale"
+	"slices"
 )
 
-const (
-	maxAge = 12
+var (
+	// corsAllowHeaders 所有跨域策略都允许的请求头，本项目的中间件和控制器会读取它们
+	corsAllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", locale.HeaderAcceptLanguage,
+		etag.HeaderIfMatch, etag.HeaderIfNoneMatch, HeaderRequestID, "traceparent", "tracestate", HeaderAPIKey, HeaderIdempotencyKey}
+	// corsExposeHeaders 所有跨域策略都暴露的响应头
+	corsExposeHeaders = []string{"Content-Length", etag.HeaderETag, locale.HeaderContentLanguage, HeaderRequestID, "traceparent",
+		HeaderRateLimitLimit, HeaderRateLimitRemaining, HeaderRateLimitReset, HeaderRateLimitPolicy, HeaderRetryAfter,
+		HeaderIdempotentReplayed, HeaderCache}
 )
 
-// Cors add cors headers.
-func Cors() gin.HandlerFunc {
-	return cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"PUT", "GET", "POST", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Authorization", "Content-Type", "Accept"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		AllowOriginFunc: func(origin string) bool {
-			return origin == "https://github.com"
-		},
-		MaxAge: maxAge * time.Hour,
-	})
+// LoadCorsConfig 读取 cors 下的跨域策略，并在每个策略中加入本项目使用的请求头和响应头
+func LoadCorsConfig() (*cors.Config, error) {
+	var cfg cors.Config
+	if err := conf.UnmarshalKey("cors", &cfg); err != nil {
+		return nil, err
+	}
+	for _, policy := range cfg.Policies {
+		policy.AllowHeaders = slices.Concat(corsAllowHeaders, policy.AllowHeaders)
+		policy.ExposeHeaders = slices.Concat(corsExposeHeaders, policy.ExposeHeaders)
+	}
+	return &cfg, nil
+}
+
+// Cors 按请求路径选择跨域策略，需要在所有路由之前注册为全局中间件
+func Cors(c *cors.CORS) gin.HandlerFunc {
+	return c.Handler()
 }
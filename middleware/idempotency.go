@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/idempotency"
+	"orca/pkg/response"
+	"orca/pkg/tracing"
+	"strconv"
+)
+
+const (
+	// HeaderIdempotencyKey 客户端为每个需要幂等的 POST 请求生成的唯一键，重试时使用相同的值
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed 响应是重放的第一个请求的响应时为 true
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+// Idempotency 为带有 Idempotency-Key 请求头的 POST 请求提供幂等性，没有该请求头的请求不受影响。
+// 第一个请求处理期间，相同幂等键的请求返回 409；处理完成后重放第一个请求的状态码、响应头和响应体；
+// 相同的幂等键用于方法、路径或请求体不同的请求时返回 400。
+// 幂等键按用户隔离。第一个请求返回 5xx 或发生 panic 时释放幂等键，客户端可以使用相同的幂等键重试。
+// 请求体需要读入内存计算指纹，超过 maxBodySize 字节时返回 400。
+// Redis 不可用时放行请求并记录错误日志。
+func Idempotency(store *idempotency.Store, maxBodySize int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(HeaderIdempotencyKey)
+		if key == "" || c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+		if !idempotency.ValidKey(key) {
+			response.Fail(c, errors.WithCode(code.ErrIdempotencyKeyInvalid, "Idempotency-Key 应为1到255个可见的 ASCII 字符"))
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBodySize)
+		body, err := c.GetRawData()
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.Fail(c, errors.WithCode(code.ErrRequestBodyTooLarge, "请求体不能超过 %d 字节", maxBodySize))
+			c.Abort()
+			return
+		}
+		if err != nil {
+			response.Fail(c, errors.WrapC(err, code.ErrBind, "读取请求体失败"))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := idempotency.Fingerprint(c.Request.Method, c.Request.URL.RequestURI(), body)
+
+		// 未识别用户的请求共享同一个空间
+		scope := "anonymous:"
+		if userID, ok := parseUserID(c); ok {
+			scope = "user:" + strconv.FormatUint(userID, 10) + ":"
+		}
+		key = scope + key
+
+		token, existing, err := store.Lock(c, key, fingerprint)
+		if err != nil {
+			tracing.Logger(c).Error("占用幂等键失败，放行请求", zap.Error(err))
+			c.Next()
+			return
+		}
+		if existing != nil {
+			replay(c, existing, fingerprint)
+			c.Abort()
+			return
+		}
+
+		// 客户端断开后请求的 context 会被取消，保存响应和释放幂等键使用不会被取消的 context
+		detached := context.WithoutCancel(c)
+		completed := false
+		defer func() {
+			// 处理失败或发生 panic 时释放幂等键，panic 继续由 GinRecovery 处理
+			if !completed {
+				if err := store.Release(detached, key, token); err != nil {
+					tracing.Logger(c).Error("释放幂等键失败", zap.Error(err))
+				}
+			}
+		}()
+
+		// 之前的中间件设置的响应头（例如请求ID和限流）属于当前请求，不需要重放
+		before := make(map[string]bool, len(c.Writer.Header()))
+		for name := range c.Writer.Header() {
+			before[name] = true
+		}
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+		header := http.Header{}
+		for name, values := range recorder.Header() {
+			if !before[name] {
+				header[name] = values
+			}
+		}
+		if err := store.Complete(detached, key, token, &idempotency.Record{
+			Fingerprint: fingerprint,
+			Status:      status,
+			Header:      header,
+			Body:        recorder.body.Bytes(),
+		}); err != nil {
+			tracing.Logger(c).Error("保存幂等键的响应失败", zap.Error(err))
+			return
+		}
+		completed = true
+	}
+}
+
+// replay 根据已有的记录响应使用相同幂等键的请求
+func replay(c *gin.Context, record *idempotency.Record, fingerprint string) {
+	switch {
+	case record.Fingerprint != fingerprint:
+		response.Fail(c, errors.WithCode(code.ErrIdempotencyKeyReused, "Idempotency-Key 已被用于方法、路径或请求体不同的请求"))
+	case !record.Completed:
+		c.Header(HeaderRetryAfter, "1")
+		response.Fail(c, errors.WithCode(code.ErrIdempotencyInProgress, "使用相同 Idempotency-Key 的请求正在处理，请稍后重试"))
+	default:
+		for name, values := range record.Header {
+			c.Writer.Header()[name] = values
+		}
+		c.Header(HeaderIdempotentReplayed, "true")
+		c.Writer.WriteHeader(record.Status)
+		_, _ = c.Writer.Write(record.Body)
+	}
+}
+
+// responseRecorder 在写入响应的同时记录响应体
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/response"
+	"strconv"
+)
+
+const (
+	// HeaderUserID 网关在完成身份认证后，通过该请求头透传当前用户的ID
+	HeaderUserID = "X-User-Id"
+
+	ctxUserIDKey = "orca.userId"
+)
+
+// Identity 解析当前请求的用户身份，并保存到上下文中，无法识别用户时直接返回401
+func Identity() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := parseUserID(c)
+		if !ok {
+			response.Fail(c, errors.WithCode(code.ErrUnauthorized, "无法识别当前用户"))
+			c.Abort()
+			return
+		}
+		c.Set(ctxUserIDKey, userID)
+		c.Next()
+	}
+}
+
+// OptionalIdentity 与 Identity 相同，但无法识别用户时不拒绝请求，GetUserID 返回0
+func OptionalIdentity() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if userID, ok := parseUserID(c); ok {
+			c.Set(ctxUserIDKey, userID)
+		}
+		c.Next()
+	}
+}
+
+func parseUserID(c *gin.Context) (uint64, bool) {
+	userID, err := strconv.ParseUint(c.GetHeader(HeaderUserID), 10, 64)
+	return userID, err == nil && userID != 0
+}
+
+// GetUserID 返回 Identity 中间件解析出的用户ID，未经过该中间件时返回0
+func GetUserID(c *gin.Context) uint64 {
+	return c.GetUint64(ctxUserIDKey)
+}
@@ -2,10 +2,12 @@ package middleware
 
 import (
 	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httputil"
 	"orca/conf"
+	"orca/pkg/tracing"
 	"os"
 	"runtime/debug"
 	"strings"
@@ -16,25 +18,21 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-var lg *zap.Logger
-
-// InitLogger 初始化Logger
-func InitLogger() {
-	writeSyncer := getLogWriter(
+// NewLogger 根据配置创建 zap 日志，日志写入 logger.fileName 并按大小切割。
+// 返回的 io.Closer 用于在应用退出时关闭日志文件，关闭前应先调用 Sync。
+func NewLogger() (*zap.Logger, io.Closer, error) {
+	file := getLogWriter(
 		conf.GetString("logger.fileName"),
 		conf.GetInt("logger.maxSize"),
 		conf.GetInt("logger.maxBackups"),
 		conf.GetInt("logger.maxAge"))
 	encoder := getEncoder()
 	var l = new(zapcore.Level)
-	err := l.UnmarshalText([]byte(conf.GetString("logger.level")))
-	if err != nil {
-		panic("初始化日志失败")
+	if err := l.UnmarshalText([]byte(conf.GetString("logger.level"))); err != nil {
+		return nil, nil, err
 	}
-	core := zapcore.NewCore(encoder, writeSyncer, l)
-
-	lg = zap.New(core, zap.AddCaller())
-	zap.ReplaceGlobals(lg) // 替换zap包中全局的logger实例，后续在其他包中只需使用zap.L()调用即可
+	core := zapcore.NewCore(encoder, zapcore.AddSync(file), l)
+	return zap.New(core, zap.AddCaller()), file, nil
 }
 
 func getEncoder() zapcore.Encoder {
@@ -47,19 +45,18 @@ func getEncoder() zapcore.Encoder {
 	return zapcore.NewJSONEncoder(encoderConfig)
 }
 
-func getLogWriter(filename string, maxSize, maxBackup, maxAge int) zapcore.WriteSyncer {
-	lumberJackLogger := &lumberjack.Logger{
+func getLogWriter(filename string, maxSize, maxBackup, maxAge int) *lumberjack.Logger {
+	return &lumberjack.Logger{
 		Filename:   filename,
 		MaxSize:    maxSize,
 		MaxBackups: maxBackup,
 		MaxAge:     maxAge,
 		Compress:   true,
 	}
-	return zapcore.AddSync(lumberJackLogger)
 }
 
 // GinLogger 接收gin框架默认的日志
-func GinLogger() gin.HandlerFunc {
+func GinLogger(lg *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
@@ -67,7 +64,7 @@ func GinLogger() gin.HandlerFunc {
 		c.Next()
 
 		cost := time.Since(start)
-		lg.Info(path,
+		lg.With(tracing.Fields(c.Request.Context())...).Info(path,
 			zap.Int("status", c.Writer.Status()),
 			zap.String("method", c.Request.Method),
 			zap.String("path", path),
@@ -81,7 +78,7 @@ func GinLogger() gin.HandlerFunc {
 }
 
 // GinRecovery recover掉项目可能出现的panic，并使用zap记录相关日志
-func GinRecovery(stack bool) gin.HandlerFunc {
+func GinRecovery(lg *zap.Logger, stack bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
@@ -97,6 +94,7 @@ func GinRecovery(stack bool) gin.HandlerFunc {
 				}
 
 				httpRequest, _ := httputil.DumpRequest(c.Request, false)
+				lg := lg.With(tracing.Fields(c.Request.Context())...)
 				if brokenPipe {
 					lg.Error(c.Request.URL.Path,
 						zap.Any("error", err),
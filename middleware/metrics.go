@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"orca/pkg/errors"
+	"orca/pkg/metrics"
+	"time"
+)
+
+// Metrics 记录每个请求的数量和耗时，以及通过 response.Fail 返回的业务错误码。
+// 路由使用注册时的模板，例如 /menu/:code，未匹配任何路由的请求记为 unmatched，避免标签数量无限增长。
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.ObserveRequest(route, c.Request.Method, c.Writer.Status(), time.Since(start))
+		for _, err := range c.Errors {
+			if coder := errors.ParseCoder(err.Err); coder != nil {
+				m.ObserveError(coder.Code())
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"math"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/ratelimit"
+	"orca/pkg/response"
+	"orca/pkg/tracing"
+	"strconv"
+	"time"
+)
+
+const (
+	// HeaderAPIKey 调用方的 API Key，按 API Key 限流时使用
+	HeaderAPIKey = "X-API-Key"
+
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRateLimitPolicy    = "RateLimit-Policy"
+	HeaderRetryAfter         = "Retry-After"
+)
+
+// RateLimit 按 policies 对请求限流，未匹配任何路由的请求不限流。
+// 一个请求可以匹配多个策略，按顺序消耗每个策略的配额，某个策略拒绝时不再检查之后的策略并返回 429。
+// 响应头中的 RateLimit-* 描述拒绝请求的策略，都允许时描述剩余配额最少的策略。
+// Redis 不可用时放行请求并记录错误日志，限流不影响服务的可用性。
+func RateLimit(limiter *ratelimit.Limiter, policies []*ratelimit.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			c.Next()
+			return
+		}
+
+		var tightest *ratelimit.Result
+		var tightestPolicy *ratelimit.Policy
+		for _, policy := range policies {
+			if !policy.Matches(c.Request.Method, route) {
+				continue
+			}
+			result, err := limiter.Allow(c, policy, rateLimitKey(c, policy.Key))
+			if err != nil {
+				tracing.Logger(c).Error("限流检查失败，放行请求", zap.String("policy", policy.Name), zap.Error(err))
+				continue
+			}
+			if tightest == nil || !result.Allowed || result.Remaining < tightest.Remaining {
+				tightest, tightestPolicy = result, policy
+			}
+			if !result.Allowed {
+				break
+			}
+		}
+		if tightest == nil {
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set(HeaderRateLimitLimit, strconv.Itoa(tightest.Limit))
+		header.Set(HeaderRateLimitRemaining, strconv.Itoa(tightest.Remaining))
+		header.Set(HeaderRateLimitReset, strconv.Itoa(seconds(tightest.Reset)))
+		header.Set(HeaderRateLimitPolicy, fmt.Sprintf("%d;w=%d", tightestPolicy.Limit, seconds(tightestPolicy.Period)))
+		if !tightest.Allowed {
+			retryAfter := seconds(tightest.RetryAfter)
+			header.Set(HeaderRetryAfter, strconv.Itoa(retryAfter))
+			response.Fail(c, errors.WithCode(code.ErrTooManyRequests, "请求过于频繁，请在 %d 秒后重试", retryAfter))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// rateLimitKey 返回请求在 key 维度上的限流键。
+// 限流中间件在路由分组的 Identity 之前执行，因此直接解析网关透传的用户ID，没有用户ID的请求按 IP 限流。
+// X-User-Id 只有在网关删除了客户端传入的同名请求头时才可信，否则客户端可以轮换用户ID绕过限流。
+// API Key 以摘要的形式出现在键中，避免明文保存在 Redis 里。
+func rateLimitKey(c *gin.Context, key string) string {
+	switch key {
+	case ratelimit.KeyUser:
+		if userID, ok := parseUserID(c); ok {
+			return "user:" + strconv.FormatUint(userID, 10)
+		}
+	case ratelimit.KeyAPIKey:
+		if apiKey := c.GetHeader(HeaderAPIKey); apiKey != "" {
+			sum := sha256.Sum256([]byte(apiKey))
+			return "apiKey:" + hex.EncodeToString(sum[:16])
+		}
+	case ratelimit.KeyRoute:
+		return "route:" + c.Request.Method + " " + c.FullPath()
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// seconds 将 d 向上取整为秒，用于 RateLimit-Reset 和 Retry-After
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
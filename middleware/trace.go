@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"orca/pkg/tracing"
+	"orca/pkg/utils/idutils"
+)
+
+// HeaderRequestID 请求和响应中携带请求ID的头部
+const HeaderRequestID = "X-Request-ID"
+
+// Trace 为每个请求确定请求ID并开始一个服务端 span，两者都保存在 c.Request 的 context 中。
+// 请求ID使用客户端传入的 X-Request-ID，没有或不合法时重新生成；span 的父 span 来自请求的 traceparent。
+// 响应头中返回请求ID和当前 span 的 traceparent，便于客户端关联日志。
+func Trace(provider trace.TracerProvider) gin.HandlerFunc {
+	tracer := tracing.Tracer(provider)
+	propagator := propagation.TraceContext{}
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(HeaderRequestID)
+		if !tracing.ValidRequestID(requestID) {
+			requestID = idutils.Nanoid.Must()
+		}
+
+		ctx := propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx = tracing.WithRequestID(ctx, requestID)
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx, span := tracer.Start(ctx, fmt.Sprintf("%s %s", c.Request.Method, route),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", c.Request.URL.Path),
+				attribute.String("request.id", requestID),
+			))
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Header(HeaderRequestID, requestID)
+		propagator.Inject(ctx, propagation.HeaderCarrier(c.Writer.Header()))
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last().Err)
+		}
+		if status >= 500 {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", status))
+		}
+	}
+}
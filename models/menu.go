@@ -2,7 +2,8 @@ package models
 
 import (
 	"database/sql/driver"
-	errors "orca/pkg/errors"
+	"orca/pkg/errors"
+	"orca/pkg/etag"
 	"orca/pkg/validation"
 )
 
@@ -17,7 +18,7 @@ const (
 type Menu struct {
 	Model `json:",inline"`
 
-	MenuID      uint64   `gorm:"type:bigint" json:"menuId"`
+	MenuID      uint64   `gorm:"type:bigint;primaryKey" json:"menuId"`
 	Label       string   `gorm:"type:varchar(20)" json:"label"`
 	Code        string   `gorm:"type:varchar(255)" json:"code"`
 	ParentID    *uint64  `gorm:"type:bigint" json:"parentId"`
@@ -30,26 +31,49 @@ type Menu struct {
 	Show        bool     `gorm:"type:boolean" json:"show"`
 	Status      bool     `gorm:"type:boolean" json:"status"`
 	Description string   `gorm:"type:text" json:"description"`
+	Version     uint64   `gorm:"type:bigint;not null;default:1" json:"version"`
 
 	Roles []*Role `gorm:"many2many:role_menu" json:"roles"`
-}
 
-type MenuList struct {
-	Total int64   `json:"total"`
-	Items []*Menu `json:"items"`
+	// Translations 其他语言的名称和描述，Label 和 Description 为默认语言的值。
+	// 创建和更新时为 nil 表示不修改翻译，为空对象表示删除所有翻译。
+	Translations Translations `gorm:"-" json:"translations,omitempty"`
 }
 
 func (m *Menu) TableName() string {
 	return "menu"
 }
 
+// ParentColumn 实现 softdelete.Tree，菜单只能与父级菜单一起恢复，与整个子树一起清理
+func (m *Menu) ParentColumn() string {
+	return "parent_id"
+}
+
+// ETag 根据菜单ID和版本号生成 ETag
+func (m *Menu) ETag() string {
+	return etag.Of(m.MenuID, m.Version)
+}
+
+// Validate 校验菜单字段，Route、Component 和 ParentID 的规则取决于菜单类型：
+// 菜单必须包含路由和组件，按钮必须有父级菜单且不能包含路由，目录不能包含组件。
+// 父级菜单是否存在、是否构成循环需要查询数据库，由调用方另行校验。
 func (m *Menu) Validate() error {
+	isMenu := m.Type == EnumMenuTypeMenu
+	isButton := m.Type == EnumMenuTypeButton
+	isDirectory := m.Type == EnumMenuTypeDirectory
 	return validation.ValidateStruct(
 		m,
 		validation.Field(&m.Code, validation.Required, validation.Length(1, 255)),
 		validation.Field(&m.Label, validation.Required, validation.Length(1, 20)),
 		validation.Field(&m.Type, validation.Required, validation.In(EnumMenuTypeMenu, EnumMenuTypeDirectory, EnumMenuTypeButton)),
-		validation.Field(&m.Status, validation.Required))
+		validation.Field(&m.ParentID, validation.When(isButton, validation.Required)),
+		validation.Field(&m.Route,
+			validation.When(isMenu, validation.Required),
+			validation.When(isButton, validation.Nil)),
+		validation.Field(&m.Component,
+			validation.When(isMenu, validation.Required),
+			validation.When(isDirectory || isButton, validation.Nil)),
+		validation.Field(&m.Translations))
 }
 
 func (mt *MenuType) Value() (driver.Value, error) {
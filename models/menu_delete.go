@@ -0,0 +1,36 @@
+package models
+
+// MenuDeleteMode 删除菜单时对子菜单的处理方式
+type MenuDeleteMode string
+
+const (
+	// EnumMenuDeleteModeRestrict 存在子菜单或角色绑定时拒绝删除
+	EnumMenuDeleteModeRestrict MenuDeleteMode = "restrict"
+	// EnumMenuDeleteModeCascade 同时删除所有子孙菜单，它们的角色绑定不再生效
+	EnumMenuDeleteModeCascade MenuDeleteMode = "cascade"
+	// EnumMenuDeleteModeReparent 将子菜单移动到被删除菜单的父级下，被删除菜单的角色绑定不再生效
+	EnumMenuDeleteModeReparent MenuDeleteMode = "reparent"
+)
+
+// MenuRoleBinding 菜单与角色的绑定关系
+type MenuRoleBinding struct {
+	MenuCode string `json:"menuCode"`
+	RoleCode string `json:"roleCode"`
+}
+
+// MenuDeleteBlockers 阻止菜单被删除的子菜单和角色绑定
+type MenuDeleteBlockers struct {
+	Children []string           `json:"children"`
+	Bindings []*MenuRoleBinding `json:"bindings"`
+}
+
+// MenuDeletePlan 删除菜单时将要执行的变更，预览模式下只返回该计划而不执行
+type MenuDeletePlan struct {
+	Mode       MenuDeleteMode  `json:"mode"`
+	DryRun     bool            `json:"dryRun"`
+	Deleted    []string        `json:"deleted"`
+	Reparented []*MenuPosition `json:"reparented"`
+	// Suspended 被删除的菜单上暂停生效的角色绑定。删除不会移除这些绑定，菜单从回收站恢复后重新生效，
+	// 菜单被清理时由外键级联删除。
+	Suspended []*MenuRoleBinding `json:"suspended"`
+}
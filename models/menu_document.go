@@ -0,0 +1,154 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+)
+
+// MenuDocumentVersion 当前菜单导入导出文档的版本，文档结构发生不兼容的变化时需要递增
+const MenuDocumentVersion = 1
+
+// MenuDocument 菜单导入导出文档。文档中使用 Code 标识菜单和角色，而不是数据库中的ID，
+// 因此可以在不同环境之间同步同一棵菜单树。
+type MenuDocument struct {
+	Version int                 `json:"version" yaml:"version"`
+	Menus   []*MenuDocumentItem `json:"menus" yaml:"menus"`
+}
+
+// MenuDocumentItem 文档中的菜单节点。
+// Roles 为 nil 时导入不修改菜单的角色绑定，为空列表时解除该菜单的所有角色绑定，Translations 同理。
+type MenuDocumentItem struct {
+	Code        string   `json:"code" yaml:"code"`
+	Label       string   `json:"label" yaml:"label"`
+	Type        MenuType `json:"type" yaml:"type"`
+	Route       *string  `json:"route" yaml:"route"`
+	Component   *string  `json:"component" yaml:"component"`
+	IconName    string   `json:"iconName" yaml:"iconName"`
+	Order       uint     `json:"order" yaml:"order"`
+	KeepAlive   bool     `json:"keepAlive" yaml:"keepAlive"`
+	Show        bool     `json:"show" yaml:"show"`
+	Status      bool     `json:"status" yaml:"status"`
+	Description string   `json:"description" yaml:"description"`
+	Roles       []string `json:"roles" yaml:"roles"`
+
+	Translations Translations `json:"translations,omitempty" yaml:"translations,omitempty"`
+
+	Children []*MenuDocumentItem `json:"children,omitempty" yaml:"children,omitempty"`
+
+	// ParentCode 由 Flatten 根据文档的层级结构填充
+	ParentCode *string `json:"-" yaml:"-"`
+}
+
+// FieldChange 字段变更前后的值
+type FieldChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// MenuImportChange 导入时被更新的菜单及其变更的字段
+type MenuImportChange struct {
+	Code    string                  `json:"code"`
+	Changes map[string]*FieldChange `json:"changes"`
+}
+
+// MenuImportResult 导入菜单的结果，预览模式下只计算结果而不提交
+type MenuImportResult struct {
+	DryRun    bool                `json:"dryRun"`
+	Created   []string            `json:"created"`
+	Updated   []*MenuImportChange `json:"updated"`
+	Deleted   []string            `json:"deleted"`
+	Unchanged int                 `json:"unchanged"`
+}
+
+// NewMenuDocumentItem 根据菜单生成文档节点，不包括子节点
+func NewMenuDocumentItem(menu *Menu, parentCode *string, roles []string) *MenuDocumentItem {
+	return &MenuDocumentItem{
+		Code:        menu.Code,
+		Label:       menu.Label,
+		Type:        menu.Type,
+		Route:       menu.Route,
+		Component:   menu.Component,
+		IconName:    menu.IconName,
+		Order:       menu.Order,
+		KeepAlive:   menu.KeepAlive,
+		Show:        menu.Show,
+		Status:      menu.Status,
+		Description: menu.Description,
+		Roles:       roles,
+		ParentCode:  parentCode,
+
+		Translations: menu.Translations,
+	}
+}
+
+// ApplyTo 将文档节点的字段写入菜单，不包括父级菜单和角色绑定
+func (i *MenuDocumentItem) ApplyTo(menu *Menu) {
+	menu.Code = i.Code
+	menu.Label = i.Label
+	menu.Type = i.Type
+	menu.Route = i.Route
+	menu.Component = i.Component
+	menu.IconName = i.IconName
+	menu.Order = i.Order
+	menu.KeepAlive = i.KeepAlive
+	menu.Show = i.Show
+	menu.Status = i.Status
+	menu.Description = i.Description
+	menu.Translations = i.Translations
+}
+
+// Flatten 按先序遍历展开文档，父节点总是排在子节点之前，并填充每个节点的 ParentCode
+func (d *MenuDocument) Flatten() []*MenuDocumentItem {
+	var items []*MenuDocumentItem
+	var walk func(nodes []*MenuDocumentItem, parentCode *string)
+	walk = func(nodes []*MenuDocumentItem, parentCode *string) {
+		for _, node := range nodes {
+			node.ParentCode = parentCode
+			items = append(items, node)
+			walk(node.Children, &node.Code)
+		}
+	}
+	walk(d.Menus, nil)
+	return items
+}
+
+// Diff 比较两个文档节点，返回以 json 字段名为键的变更，忽略子节点。
+// target 的 Roles 或 Translations 为 nil 时不比较角色绑定或翻译。
+func (i *MenuDocumentItem) Diff(target *MenuDocumentItem) map[string]*FieldChange {
+	changes := make(map[string]*FieldChange)
+	from, to := reflect.ValueOf(i).Elem(), reflect.ValueOf(target).Elem()
+	for k := 0; k < from.NumField(); k++ {
+		field := from.Type().Field(k)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		switch {
+		case name == "children":
+			continue
+		case name == "-":
+			name = "parentCode"
+		case name == "roles" && target.Roles == nil:
+			continue
+		case name == "translations":
+			// 没有翻译和空的翻译视为相同
+			if target.Translations == nil || (len(i.Translations) == 0 && len(target.Translations) == 0) {
+				continue
+			}
+		}
+
+		a, b := indirect(from.Field(k)), indirect(to.Field(k))
+		if !reflect.DeepEqual(a, b) {
+			changes[name] = &FieldChange{From: a, To: b}
+		}
+	}
+	return changes
+}
+
+// indirect 返回指针指向的值，空指针返回 nil
+func indirect(v reflect.Value) any {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		return v.Elem().Interface()
+	}
+	return v.Interface()
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMenuDocumentFlatten(t *testing.T) {
+	doc := &MenuDocument{Version: MenuDocumentVersion, Menus: []*MenuDocumentItem{
+		{Code: "system", Children: []*MenuDocumentItem{
+			{Code: "users", Children: []*MenuDocumentItem{{Code: "user:create"}}},
+			{Code: "roles"},
+		}},
+		{Code: "home"},
+	}}
+
+	items := doc.Flatten()
+	codes := make([]string, 0, len(items))
+	parents := make(map[string]*string, len(items))
+	for _, item := range items {
+		codes = append(codes, item.Code)
+		parents[item.Code] = item.ParentCode
+	}
+	assert.Equal(t, []string{"system", "users", "user:create", "roles", "home"}, codes)
+	assert.Nil(t, parents["system"])
+	assert.Nil(t, parents["home"])
+	assert.Equal(t, str("system"), parents["users"])
+	assert.Equal(t, str("users"), parents["user:create"])
+	assert.Equal(t, str("system"), parents["roles"])
+}
+
+func TestMenuDocumentItemDiff(t *testing.T) {
+	menu := &Menu{Code: "users", Label: "用户", Type: EnumMenuTypeMenu, Route: str("/users"), Component: str("users/index"),
+		Show: true, Status: true, Translations: Translations{"en": {Label: "Users"}}}
+	current := NewMenuDocumentItem(menu, str("system"), []string{"admin"})
+
+	tests := map[string]struct {
+		target  func(item *MenuDocumentItem)
+		changes map[string]*FieldChange
+	}{
+		"unchanged": {target: func(item *MenuDocumentItem) {}, changes: map[string]*FieldChange{}},
+		"update": {
+			target: func(item *MenuDocumentItem) {
+				item.Label = "用户管理"
+				item.Route = nil
+				item.ParentCode = nil
+			},
+			changes: map[string]*FieldChange{
+				"label":      {From: "用户", To: "用户管理"},
+				"route":      {From: "/users", To: nil},
+				"parentCode": {From: "system", To: nil},
+			},
+		},
+		"roles and translations": {
+			target: func(item *MenuDocumentItem) {
+				item.Roles = []string{}
+				item.Translations = Translations{}
+			},
+			changes: map[string]*FieldChange{
+				"roles":        {From: []string{"admin"}, To: []string{}},
+				"translations": {From: Translations{"en": {Label: "Users"}}, To: Translations{}},
+			},
+		},
+		// 文档中没有 roles 和 translations 时不修改角色绑定和翻译
+		"nil roles and translations": {
+			target: func(item *MenuDocumentItem) {
+				item.Roles = nil
+				item.Translations = nil
+			},
+			changes: map[string]*FieldChange{},
+		},
+		"children ignored": {
+			target:  func(item *MenuDocumentItem) { item.Children = []*MenuDocumentItem{{Code: "user:create"}} },
+			changes: map[string]*FieldChange{},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			target := NewMenuDocumentItem(menu, str("system"), []string{"admin"})
+			tt.target(target)
+			assert.Equal(t, tt.changes, current.Diff(target))
+		})
+	}
+
+	// 新建的菜单与空节点比较，所有非零字段都是变更
+	created := (&MenuDocumentItem{Roles: []string{}, Translations: Translations{}}).Diff(current)
+	assert.Equal(t, &FieldChange{From: "", To: "users"}, created["code"])
+	assert.Equal(t, &FieldChange{From: nil, To: "system"}, created["parentCode"])
+	assert.Equal(t, &FieldChange{From: []string{}, To: []string{"admin"}}, created["roles"])
+	assert.NotContains(t, created, "keepAlive")
+
+	// 翻译为空和没有翻译视为相同
+	empty := NewMenuDocumentItem(&Menu{Code: "users"}, nil, nil)
+	assert.Empty(t, empty.Diff(&MenuDocumentItem{Code: "users", Translations: Translations{}}))
+}
+
+func TestMenuDocumentItemApplyTo(t *testing.T) {
+	item := &MenuDocumentItem{Code: "users", Label: "用户", Type: EnumMenuTypeMenu, Route: str("/users"),
+		Component: str("users/index"), IconName: "user", Order: 2, KeepAlive: true, Show: true, Status: true,
+		Description: "用户管理", Roles: []string{"admin"}, ParentCode: str("system"),
+		Translations: Translations{"en": {Label: "Users"}}}
+	menu := &Menu{MenuID: 3, ParentID: id(1), Version: 4}
+	item.ApplyTo(menu)
+
+	// 父级菜单、ID和版本号不被修改
+	assert.Equal(t, &Menu{MenuID: 3, ParentID: id(1), Version: 4, Code: "users", Label: "用户", Type: EnumMenuTypeMenu,
+		Route: str("/users"), Component: str("users/index"), IconName: "user", Order: 2, KeepAlive: true, Show: true,
+		Status: true, Description: "用户管理", Translations: Translations{"en": {Label: "Users"}}}, menu)
+
+	// 写入后生成的节点与原节点相同
+	roundTrip := NewMenuDocumentItem(menu, item.ParentCode, item.Roles)
+	require.Empty(t, item.Diff(roundTrip))
+}
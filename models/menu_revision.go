@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// MenuRevisionAction 产生修订记录的操作
+type MenuRevisionAction string
+
+const (
+	EnumMenuRevisionActionCreate   MenuRevisionAction = "create"
+	EnumMenuRevisionActionUpdate   MenuRevisionAction = "update"
+	EnumMenuRevisionActionDelete   MenuRevisionAction = "delete"
+	EnumMenuRevisionActionRollback MenuRevisionAction = "rollback"
+	EnumMenuRevisionActionRestore  MenuRevisionAction = "restore"
+)
+
+// MenuSnapshot 修订记录中菜单在修改后的完整状态，包括父级菜单编码、角色绑定和翻译。
+// 删除操作的快照为菜单被删除时的状态。
+type MenuSnapshot struct {
+	MenuDocumentItem `json:",inline"`
+
+	ParentCode *string `json:"parentCode"`
+}
+
+// NewMenuSnapshot 根据文档节点生成快照
+func NewMenuSnapshot(item *MenuDocumentItem) *MenuSnapshot {
+	return &MenuSnapshot{MenuDocumentItem: *item, ParentCode: item.ParentCode}
+}
+
+// Item 将快照转换为文档节点，用于回滚时写回菜单
+func (s *MenuSnapshot) Item() *MenuDocumentItem {
+	item := s.MenuDocumentItem
+	item.ParentCode = s.ParentCode
+	return &item
+}
+
+// MenuRevision 菜单的一次修订，每次创建、修改、删除、回滚或从回收站恢复菜单都会产生一条记录
+type MenuRevision struct {
+	RevisionID uint64                  `gorm:"type:bigint;primaryKey;autoIncrement" json:"revisionId"`
+	MenuID     uint64                  `gorm:"type:bigint" json:"menuId"`
+	Action     MenuRevisionAction      `gorm:"type:varchar(16)" json:"action"`
+	ActorID    *uint64                 `gorm:"type:bigint" json:"actorId"`
+	Snapshot   *MenuSnapshot           `gorm:"type:json;serializer:json" json:"snapshot"`
+	Changes    map[string]*FieldChange `gorm:"type:json;serializer:json" json:"changes"`
+	// RollbackOf 回滚操作恢复的修订ID
+	RollbackOf *uint64   `gorm:"type:bigint" json:"rollbackOf,omitempty"`
+	CreatedAt  time.Time `gorm:"type:datetime" json:"createdAt"`
+}
+
+func (r *MenuRevision) TableName() string {
+	return "menu_revision"
+}
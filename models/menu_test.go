@@ -0,0 +1,65 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"orca/pkg/validation"
+)
+
+func str(v string) *string {
+	return &v
+}
+
+func TestMenuValidate(t *testing.T) {
+	tests := map[string]struct {
+		menu *Menu
+		// fields 验证失败的字段，为空表示验证通过
+		fields []string
+	}{
+		"menu":      {menu: &Menu{Type: EnumMenuTypeMenu, Route: str("/users"), Component: str("users/index")}},
+		"directory": {menu: &Menu{Type: EnumMenuTypeDirectory, Route: str("/system")}},
+		"button":    {menu: &Menu{Type: EnumMenuTypeButton, ParentID: id(1)}},
+
+		"menu without route":     {menu: &Menu{Type: EnumMenuTypeMenu, Component: str("users/index")}, fields: []string{"route"}},
+		"menu without component": {menu: &Menu{Type: EnumMenuTypeMenu, Route: str("/users")}, fields: []string{"component"}},
+		"button without parent":  {menu: &Menu{Type: EnumMenuTypeButton}, fields: []string{"parentId"}},
+		"button with route": {
+			menu:   &Menu{Type: EnumMenuTypeButton, ParentID: id(1), Route: str("/save")},
+			fields: []string{"route"},
+		},
+		"button with component": {
+			menu:   &Menu{Type: EnumMenuTypeButton, ParentID: id(1), Component: str("save")},
+			fields: []string{"component"},
+		},
+		"directory with component": {
+			menu:   &Menu{Type: EnumMenuTypeDirectory, Component: str("system/index")},
+			fields: []string{"component"},
+		},
+		"unknown type": {menu: &Menu{Type: "Page"}, fields: []string{"type"}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tt.menu.Code, tt.menu.Label = "code", "label"
+			err := tt.menu.Validate()
+			if len(tt.fields) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var errs validation.Errors
+			require.ErrorAs(t, err, &errs)
+			var fields []string
+			for field := range errs {
+				fields = append(fields, field)
+			}
+			assert.ElementsMatch(t, tt.fields, fields)
+		})
+	}
+
+	err := (&Menu{Type: EnumMenuTypeDirectory, Label: "一二三四五六七八九十一二三四五六七八九十一"}).Validate()
+	var errs validation.Errors
+	require.ErrorAs(t, err, &errs)
+	assert.Contains(t, errs, "code")
+	assert.Contains(t, errs, "label")
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"orca/pkg/locale"
+	"orca/pkg/validation"
+	"regexp"
+	"sort"
+)
+
+// localePattern BCP 47 语言标签，例如 en、en-US、zh-Hans-CN
+var localePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// MenuTranslation 菜单名称和描述的翻译，每个菜单的每种语言对应一条记录
+type MenuTranslation struct {
+	MenuID      uint64 `gorm:"type:bigint;primaryKey"`
+	Locale      string `gorm:"type:varchar(35);primaryKey"`
+	Label       string `gorm:"type:varchar(20)"`
+	Description string `gorm:"type:text"`
+}
+
+func (t *MenuTranslation) TableName() string {
+	return "menu_translation"
+}
+
+// Translation 一种语言下的菜单名称和描述，描述为空时使用菜单默认的描述
+type Translation struct {
+	Label       string `json:"label" yaml:"label"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// Translations 以语言标签为键的翻译
+type Translations map[string]*Translation
+
+// Validate 校验语言标签和每种语言的名称，返回以语言标签为键的字段错误
+func (t Translations) Validate() error {
+	errs := validation.Errors{}
+	for locale, translation := range t {
+		if !localePattern.MatchString(locale) {
+			errs[locale] = validation.NewError("validation_menu_locale_invalid", "无效的语言标签")
+			continue
+		}
+		if translation == nil {
+			errs[locale] = validation.NewError("validation_menu_translation_nil", "翻译不能为空")
+			continue
+		}
+		err := validation.ValidateStruct(translation,
+			validation.Field(&translation.Label, validation.Required, validation.Length(1, 20)))
+		if err != nil {
+			errs[locale] = err
+		}
+	}
+	return errs.Filter()
+}
+
+// Localize 使用 tag 对应的翻译替换菜单的名称和描述，没有该语言的翻译时保持不变。
+// 翻译的语言标签与 tag 的匹配规则与 locale.Match 相同，例如 en 和 en-us 的翻译都可以用于 en-US。
+func (m *Menu) Localize(tag string) {
+	tags := make([]string, 0, len(m.Translations))
+	for t := range m.Translations {
+		tags = append(tags, t)
+	}
+	// 多个翻译只有主语言匹配时选择排序后的第一个，使结果稳定
+	sort.Strings(tags)
+	matched, ok := locale.Match([]string{tag}, tags)
+	if !ok || m.Translations[matched] == nil {
+		return
+	}
+	translation := m.Translations[matched]
+	m.Label = translation.Label
+	if translation.Description != "" {
+		m.Description = translation.Description
+	}
+}
+
+// LoadMenuTranslations 查询 menus 的翻译并填充到 Translations 中
+func LoadMenuTranslations(db *gorm.DB, menus []*Menu) error {
+	if len(menus) == 0 {
+		return nil
+	}
+	byID := make(map[uint64]*Menu, len(menus))
+	ids := make([]uint64, 0, len(menus))
+	for _, menu := range menus {
+		byID[menu.MenuID] = menu
+		ids = append(ids, menu.MenuID)
+	}
+
+	var rows []*MenuTranslation
+	if err := db.Where("menu_id in ?", ids).Find(&rows).Error; err != nil {
+		return err
+	}
+	for _, row := range rows {
+		menu := byID[row.MenuID]
+		if menu.Translations == nil {
+			menu.Translations = make(Translations)
+		}
+		menu.Translations[row.Locale] = &Translation{Label: row.Label, Description: row.Description}
+	}
+	return nil
+}
+
+// SaveMenuTranslations 将菜单的翻译替换为 translations，translations 为 nil 时不做修改
+func SaveMenuTranslations(tx *gorm.DB, menuID uint64, translations Translations) error {
+	if translations == nil {
+		return nil
+	}
+	if err := tx.Where("menu_id = ?", menuID).Delete(&MenuTranslation{}).Error; err != nil {
+		return err
+	}
+	if len(translations) == 0 {
+		return nil
+	}
+
+	locales := make([]string, 0, len(translations))
+	for locale := range translations {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	rows := make([]*MenuTranslation, 0, len(locales))
+	for _, locale := range locales {
+		rows = append(rows, &MenuTranslation{
+			MenuID:      menuID,
+			Locale:      locale,
+			Label:       translations[locale].Label,
+			Description: translations[locale].Description,
+		})
+	}
+	return tx.Create(&rows).Error
+}
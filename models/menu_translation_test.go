@@ -0,0 +1,32 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMenuLocalize(t *testing.T) {
+	tests := map[string]struct {
+		translations Translations
+		tag          string
+		label        string
+		description  string
+	}{
+		"完整的标签":      {Translations{"en-US": {Label: "Users"}}, "en-US", "Users", "用户"},
+		"不区分大小写":     {Translations{"en-us": {Label: "Users"}}, "en-US", "Users", "用户"},
+		"主语言":        {Translations{"en": {Label: "Users", Description: "All users"}}, "en-US", "Users", "All users"},
+		"完整的标签优先":    {Translations{"en": {Label: "Users"}, "en-GB": {Label: "Members"}, "en-US": {Label: "People"}}, "en-US", "People", "用户"},
+		"主语言匹配时结果稳定": {Translations{"en-GB": {Label: "Members"}, "en": {Label: "Users"}}, "en-US", "Users", "用户"},
+		"没有匹配的翻译":    {Translations{"ja": {Label: "ユーザー"}}, "en-US", "用户", "用户"},
+		"翻译为空":       {Translations{"en": nil}, "en-US", "用户", "用户"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			menu := &Menu{Label: "用户", Description: "用户", Translations: tt.translations}
+			menu.Localize(tt.tag)
+			assert.Equal(t, tt.label, menu.Label)
+			assert.Equal(t, tt.description, menu.Description)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"orca/pkg/validation"
+	"sort"
+)
+
+// MenuTree 菜单树节点
+type MenuTree struct {
+	*Menu `json:",inline"`
+
+	Children []*MenuTree `json:"children"`
+}
+
+// MenuPosition 菜单在树中的目标位置，ParentCode 为空表示移动到根节点下
+type MenuPosition struct {
+	Code       string  `json:"code"`
+	ParentCode *string `json:"parentCode"`
+	Order      uint    `json:"order"`
+}
+
+// MenuMoveRequest 批量移动菜单的请求体
+type MenuMoveRequest struct {
+	Positions []*MenuPosition `json:"positions"`
+}
+
+func (mp *MenuPosition) Validate() error {
+	return validation.ValidateStruct(
+		mp,
+		validation.Field(&mp.Code, validation.Required, validation.Length(1, 255)),
+		validation.Field(&mp.ParentCode, validation.NilOrNotEmpty, validation.Length(1, 255)))
+}
+
+func (mr *MenuMoveRequest) Validate() error {
+	return validation.ValidateStruct(
+		mr,
+		validation.Field(&mr.Positions, validation.Required))
+}
+
+// SortMenus 按照 Order 和 MenuID 对同级菜单排序
+func SortMenus(menus []*Menu) {
+	sort.SliceStable(menus, func(i, j int) bool {
+		if menus[i].Order != menus[j].Order {
+			return menus[i].Order < menus[j].Order
+		}
+		return menus[i].MenuID < menus[j].MenuID
+	})
+}
+
+// BuildMenuTree 将平铺的菜单组装为树，父级菜单不在 menus 中时，该菜单会被提升为根节点
+func BuildMenuTree(menus []*Menu) []*MenuTree {
+	sorted := make([]*Menu, len(menus))
+	copy(sorted, menus)
+	SortMenus(sorted)
+
+	nodes := make(map[uint64]*MenuTree, len(sorted))
+	for _, menu := range sorted {
+		nodes[menu.MenuID] = &MenuTree{Menu: menu, Children: make([]*MenuTree, 0)}
+	}
+
+	roots := make([]*MenuTree, 0)
+	for _, menu := range sorted {
+		node := nodes[menu.MenuID]
+		if menu.ParentID != nil {
+			if parent, ok := nodes[*menu.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+// FindMenuCycle 检查 parents 描述的父子关系中是否存在循环，parents 的键为菜单ID，值为父级菜单ID。
+// 存在循环时返回环上的任意一个菜单ID。
+func FindMenuCycle(parents map[uint64]*uint64) (uint64, bool) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[uint64]int, len(parents))
+	for id := range parents {
+		var path []uint64
+		current := id
+		for {
+			if state[current] == visited {
+				break
+			}
+			if state[current] == visiting {
+				return current, true
+			}
+			state[current] = visiting
+			path = append(path, current)
+			parent, ok := parents[current]
+			if !ok || parent == nil {
+				break
+			}
+			current = *parent
+		}
+		for _, p := range path {
+			state[p] = visited
+		}
+	}
+	return 0, false
+}
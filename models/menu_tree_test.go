@@ -0,0 +1,40 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func id(v uint64) *uint64 {
+	return &v
+}
+
+func TestFindMenuCycle(t *testing.T) {
+	tests := map[string]struct {
+		parents map[uint64]*uint64
+		// cycle 环上的菜单ID，为空表示没有循环
+		cycle []uint64
+	}{
+		"empty":          {parents: map[uint64]*uint64{}},
+		"tree":           {parents: map[uint64]*uint64{1: nil, 2: id(1), 3: id(1), 4: id(3)}},
+		"missing parent": {parents: map[uint64]*uint64{2: id(1), 3: id(2)}},
+		"self":           {parents: map[uint64]*uint64{1: nil, 2: id(2)}, cycle: []uint64{2}},
+		"two nodes":      {parents: map[uint64]*uint64{1: id(2), 2: id(1)}, cycle: []uint64{1, 2}},
+		"tail into cycle": {
+			parents: map[uint64]*uint64{1: id(2), 2: id(3), 3: id(4), 4: id(2), 5: id(1)},
+			cycle:   []uint64{2, 3, 4},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			found, ok := FindMenuCycle(tt.parents)
+			if len(tt.cycle) == 0 {
+				assert.False(t, ok)
+				return
+			}
+			assert.True(t, ok)
+			assert.Contains(t, tt.cycle, found)
+		})
+	}
+}
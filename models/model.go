@@ -1,9 +1,24 @@
 package models
 
-import "time"
+import (
+	"gorm.io/gorm/schema"
+	"orca/pkg/softdelete"
+	"time"
+)
 
 type Model struct {
-	CreatedAt time.Time `gorm:"type:datetime" json:"createdAt"`
-	UpdatedAt time.Time `gorm:"type:datetime" json:"updatedAt"`
-	DeletedAt uint64    `gorm:"type:bigint" json:"deletedAt"`
+	CreatedAt time.Time            `gorm:"type:datetime" json:"createdAt"`
+	UpdatedAt time.Time            `gorm:"type:datetime" json:"updatedAt"`
+	DeletedAt softdelete.DeletedAt `gorm:"type:bigint" json:"deletedAt"`
+}
+
+// TrashList 回收站中已删除的记录
+type TrashList struct {
+	Total int64 `json:"total"`
+	Items any   `json:"items"`
+}
+
+// Trashable 返回所有嵌入了 Model 的模型，回收站和定期清理任务只处理这些模型
+func Trashable() []schema.Tabler {
+	return []schema.Tabler{&Menu{}, &Role{}, &User{}}
 }
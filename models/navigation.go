@@ -0,0 +1,85 @@
+package models
+
+import "sort"
+
+// NavigationMeta 前端路由的元信息
+type NavigationMeta struct {
+	Title     string `json:"title"`
+	Icon      string `json:"icon"`
+	KeepAlive bool   `json:"keepAlive"`
+	Hidden    bool   `json:"hidden"`
+	Order     uint   `json:"order"`
+}
+
+// NavigationRoute 前端动态路由节点，结构与 vue-router、react-router 的路由配置保持一致
+type NavigationRoute struct {
+	Name      string             `json:"name"`
+	Path      string             `json:"path"`
+	Component string             `json:"component,omitempty"`
+	Meta      NavigationMeta     `json:"meta"`
+	Children  []*NavigationRoute `json:"children,omitempty"`
+}
+
+// Navigation 当前用户的导航信息，包括路由树和按钮权限码
+type Navigation struct {
+	Routes      []*NavigationRoute `json:"routes"`
+	Permissions []string           `json:"permissions"`
+}
+
+// BuildNavigation 将用户有权访问的菜单组装为路由树，按钮类型的菜单只输出权限码。
+// 父级菜单不在 menus 中时，该菜单会被提升为根路由。
+func BuildNavigation(menus []*Menu) *Navigation {
+	nav := &Navigation{
+		Routes:      make([]*NavigationRoute, 0),
+		Permissions: make([]string, 0),
+	}
+
+	sorted := make([]*Menu, len(menus))
+	copy(sorted, menus)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Order != sorted[j].Order {
+			return sorted[i].Order < sorted[j].Order
+		}
+		return sorted[i].MenuID < sorted[j].MenuID
+	})
+
+	routes := make(map[uint64]*NavigationRoute, len(sorted))
+	for _, menu := range sorted {
+		if menu.Type == EnumMenuTypeButton {
+			nav.Permissions = append(nav.Permissions, menu.Code)
+			continue
+		}
+		route := &NavigationRoute{
+			Name: menu.Code,
+			Meta: NavigationMeta{
+				Title:     menu.Label,
+				Icon:      menu.IconName,
+				KeepAlive: menu.KeepAlive,
+				Hidden:    !menu.Show,
+				Order:     menu.Order,
+			},
+		}
+		if menu.Route != nil {
+			route.Path = *menu.Route
+		}
+		if menu.Component != nil {
+			route.Component = *menu.Component
+		}
+		routes[menu.MenuID] = route
+	}
+
+	for _, menu := range sorted {
+		route, ok := routes[menu.MenuID]
+		if !ok {
+			continue
+		}
+		if menu.ParentID != nil {
+			if parent, ok := routes[*menu.ParentID]; ok {
+				parent.Children = append(parent.Children, route)
+				continue
+			}
+		}
+		nav.Routes = append(nav.Routes, route)
+	}
+	return nav
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildNavigation(t *testing.T) {
+	menus := []*Menu{
+		{MenuID: 5, Code: "user:create", Type: EnumMenuTypeButton, ParentID: id(3)},
+		{MenuID: 3, Code: "users", Label: "用户", Type: EnumMenuTypeMenu, ParentID: id(1), Order: 1, Show: true,
+			KeepAlive: true, IconName: "user", Route: str("/system/users"), Component: str("system/users/index")},
+		{MenuID: 4, Code: "profile", Label: "个人资料", Type: EnumMenuTypeMenu, ParentID: id(1), Order: 0, Show: false,
+			Route: str("/system/profile"), Component: str("system/profile/index")},
+		{MenuID: 1, Code: "system", Label: "系统", Type: EnumMenuTypeDirectory, Order: 1, Show: true, Route: str("/system")},
+		{MenuID: 2, Code: "home", Label: "首页", Type: EnumMenuTypeMenu, Order: 0, Show: true, Route: str("/"), Component: str("home")},
+		// 父级菜单不在列表中时提升为根路由
+		{MenuID: 7, Code: "daily", Label: "日报", Type: EnumMenuTypeMenu, ParentID: id(6), Order: 2, Show: true,
+			Route: str("/reports/daily"), Component: str("reports/daily")},
+		{MenuID: 8, Code: "user:delete", Type: EnumMenuTypeButton, ParentID: id(3)},
+	}
+
+	nav := BuildNavigation(menus)
+	assert.Equal(t, []string{"user:create", "user:delete"}, nav.Permissions)
+	require.Len(t, nav.Routes, 3)
+	assert.Equal(t, "home", nav.Routes[0].Name)
+	assert.Equal(t, "daily", nav.Routes[2].Name)
+
+	system := nav.Routes[1]
+	assert.Equal(t, &NavigationRoute{Name: "system", Path: "/system", Meta: NavigationMeta{Title: "系统", Order: 1}},
+		&NavigationRoute{Name: system.Name, Path: system.Path, Component: system.Component, Meta: system.Meta})
+	require.Len(t, system.Children, 2)
+	// 隐藏的菜单仍然注册路由，只是不在菜单中显示
+	assert.Equal(t, "profile", system.Children[0].Name)
+	assert.True(t, system.Children[0].Meta.Hidden)
+	assert.Equal(t, &NavigationRoute{
+		Name:      "users",
+		Path:      "/system/users",
+		Component: "system/users/index",
+		Meta:      NavigationMeta{Title: "用户", Icon: "user", KeepAlive: true, Order: 1},
+	}, system.Children[1])
+
+	empty := BuildNavigation(nil)
+	assert.NotNil(t, empty.Routes)
+	assert.NotNil(t, empty.Permissions)
+}
@@ -0,0 +1,25 @@
+package models
+
+// Permission 路由声明的权限，同步到菜单中作为所属模块目录下的按钮，按钮的编码为权限编码
+type Permission struct {
+	Code string `json:"code"`
+	// Module 权限所属的模块，对应编码与模块相同的菜单目录
+	Module string `json:"module"`
+	// Description 第一个声明该权限的路由的描述，作为按钮的名称
+	Description string `json:"description"`
+	// Routes 声明该权限的路由，例如 POST /menu，作为按钮的描述
+	Routes []string `json:"routes"`
+}
+
+// PermissionSyncResult 同步权限到菜单的结果
+type PermissionSyncResult struct {
+	DryRun bool `json:"dryRun"`
+	// Directories 新建的模块目录的编码
+	Directories []string `json:"directories"`
+	// Created 新建的按钮的编码
+	Created []string `json:"created"`
+	// Orphans 菜单中存在但没有任何路由声明的按钮的编码，同步不会删除它们
+	Orphans []string `json:"orphans"`
+	// Conflicts 编码与模块相同的菜单是按钮、无法作为父级菜单的模块，这些模块下缺少的权限不会被创建
+	Conflicts []string `json:"conflicts"`
+}
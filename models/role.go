@@ -1,7 +1,9 @@
 package models
 
 type Role struct {
-	RoleID      uint64 `gorm:"type:bigint" json:"roleId"`
+	Model `json:",inline"`
+
+	RoleID      uint64 `gorm:"type:bigint;primaryKey" json:"roleId"`
 	Label       string `gorm:"type:varchar(20)" json:"label"`
 	Code        string `gorm:"type:varchar(255)" json:"code"`
 	Status      bool   `gorm:"type:boolean" json:"status"`
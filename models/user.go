@@ -3,7 +3,7 @@ package models
 type User struct {
 	Model `json:",inline"`
 
-	UserID   uint64 `gorm:"type:bigint" json:"userId"`
+	UserID   uint64 `gorm:"type:bigint;primaryKey" json:"userId"`
 	Username string `gorm:"type:varchar(20);not null" json:"username"`
 
 	UserProfile         *UserProfile           `gorm:"foreignKey:UserProfileID;references:UserID" json:"userProfile,omitempty"`
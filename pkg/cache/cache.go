@@ -0,0 +1,118 @@
+// Package cache 在 Redis 中缓存序列化后的响应，缓存项按资源打上标签，通过标签使缓存失效。
+//
+// 每个标签在 Redis 中有一个版本号，缓存键由请求的各个部分和所有标签的当前版本号计算得出。
+// Invalidate 递增标签的版本号后，包含该标签的旧缓存键不会再被读取，在 TTL 到期后自然删除。
+// 同一个进程中相同缓存键的并发请求通过 singleflight 合并，只有一个请求会执行处理函数。
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"golang.org/x/sync/singleflight"
+	"net/http"
+	"time"
+)
+
+// Entry 缓存的响应
+type Entry struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body"`
+}
+
+// Cache 响应缓存
+type Cache struct {
+	client redis.Cmdable
+	prefix string
+	ttl    time.Duration
+	group  singleflight.Group
+}
+
+// New 创建响应缓存，prefix 为 Redis 键的前缀，ttl 为缓存项的有效期
+func New(client redis.Cmdable, prefix string, ttl time.Duration) *Cache {
+	return &Cache{client: client, prefix: prefix, ttl: ttl}
+}
+
+// Key 根据请求的各个部分和 tags 的当前版本号生成缓存键，parts 的顺序需要固定
+func (c *Cache) Key(ctx context.Context, tags []string, parts ...string) (string, error) {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	if len(tags) > 0 {
+		versionKeys := make([]string, len(tags))
+		for i, tag := range tags {
+			versionKeys[i] = c.tagKey(tag)
+		}
+		versions, err := c.client.MGet(ctx, versionKeys...).Result()
+		if err != nil {
+			return "", fmt.Errorf("cache: 读取标签版本失败: %w", err)
+		}
+		for i, tag := range tags {
+			// 从未失效的标签没有版本号，MGet 返回 nil
+			_, _ = fmt.Fprintf(h, "%s=%v\x00", tag, versions[i])
+		}
+	}
+	return c.prefix + "entry:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Get 读取缓存的响应，缓存不存在时返回 nil
+func (c *Cache) Get(ctx context.Context, key string) (*Entry, error) {
+	data, err := c.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cache: 读取缓存失败: %w", err)
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("cache: 解析缓存失败: %w", err)
+	}
+	return &entry, nil
+}
+
+// Set 保存响应，有效期为 New 中指定的 ttl
+func (c *Cache) Set(ctx context.Context, key string, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := c.client.Set(ctx, key, data, c.ttl).Err(); err != nil {
+		return fmt.Errorf("cache: 写入缓存失败: %w", err)
+	}
+	return nil
+}
+
+// Invalidate 使包含任意一个 tags 的缓存失效，资源发生变化后需要调用
+func (c *Cache) Invalidate(ctx context.Context, tags ...string) error {
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, tag := range tags {
+			pipe.Incr(ctx, c.tagKey(tag))
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("cache: 使标签失效失败: %w", err)
+	}
+	return nil
+}
+
+// Do 合并相同缓存键的并发调用，只有第一个调用会执行 fn，其余调用等待并共享它的结果。
+// shared 为 true 表示结果被多个调用共享，包括执行 fn 的调用。
+func (c *Cache) Do(key string, fn func() (*Entry, error)) (entry *Entry, shared bool, err error) {
+	v, err, shared := c.group.Do(key, func() (any, error) {
+		return fn()
+	})
+	entry, _ = v.(*Entry)
+	return entry, shared, err
+}
+
+func (c *Cache) tagKey(tag string) string {
+	return c.prefix + "tag:" + tag
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCache(t *testing.T) (*Cache, *miniredis.Miniredis) {
+	m := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: m.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return New(client, "test:", time.Minute), m
+}
+
+func TestKey(t *testing.T) {
+	c, _ := newCache(t)
+	ctx := context.Background()
+
+	key, err := c.Key(ctx, []string{"menu"}, "/menu", "page=1", "roles:1")
+	require.NoError(t, err)
+	same, err := c.Key(ctx, []string{"menu"}, "/menu", "page=1", "roles:1")
+	require.NoError(t, err)
+	assert.Equal(t, key, same)
+
+	other, err := c.Key(ctx, []string{"menu"}, "/menu", "page=1", "roles:2")
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+	// 各部分之间有分隔符，拼接结果相同的不同部分不会产生相同的键
+	other, err = c.Key(ctx, []string{"menu"}, "/menu", "page=1r", "oles:1")
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+
+	// 其他标签失效不影响缓存键
+	require.NoError(t, c.Invalidate(ctx, "role"))
+	same, err = c.Key(ctx, []string{"menu"}, "/menu", "page=1", "roles:1")
+	require.NoError(t, err)
+	assert.Equal(t, key, same)
+
+	require.NoError(t, c.Invalidate(ctx, "menu"))
+	other, err = c.Key(ctx, []string{"menu"}, "/menu", "page=1", "roles:1")
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+}
+
+func TestGetAndSet(t *testing.T) {
+	c, m := newCache(t)
+	ctx := context.Background()
+
+	entry, err := c.Get(ctx, "test:entry:a")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	want := &Entry{Status: http.StatusOK, Header: http.Header{"Content-Type": {"application/json"}}, Body: []byte(`{"code":100001}`)}
+	require.NoError(t, c.Set(ctx, "test:entry:a", want))
+	assert.Equal(t, time.Minute, m.TTL("test:entry:a"))
+
+	entry, err = c.Get(ctx, "test:entry:a")
+	require.NoError(t, err)
+	assert.Equal(t, want, entry)
+}
+
+func TestDo(t *testing.T) {
+	c, _ := newCache(t)
+	release := make(chan struct{})
+	var calls atomic.Int32
+	var wg sync.WaitGroup
+	entries := make([]*Entry, 5)
+	for i := range entries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entries[i], _, _ = c.Do("key", func() (*Entry, error) {
+				calls.Add(1)
+				<-release
+				return &Entry{Status: http.StatusOK}, nil
+			})
+		}()
+	}
+	// 等待所有调用进入 Do 后再让第一个调用返回
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for _, entry := range entries {
+		assert.Equal(t, http.StatusOK, entry.Status)
+	}
+}
@@ -20,4 +20,34 @@ const (
 
 	// ErrBind - 400: 参数绑定错误。
 	ErrBind
+
+	// ErrUnauthorized - 401: 用户未认证。
+	ErrUnauthorized
+
+	// ErrConflict - 409: 资源存在冲突。
+	ErrConflict
+
+	// ErrPreconditionRequired - 400: 缺少 If-Match 请求头。
+	ErrPreconditionRequired
+
+	// ErrPreconditionFailed - 409: 资源已被其他请求修改。
+	ErrPreconditionFailed
+
+	// ErrServiceUnavailable - 503: 服务暂时不可用。
+	ErrServiceUnavailable
+
+	// ErrTooManyRequests - 429: 请求过于频繁，请稍后再试。
+	ErrTooManyRequests
+
+	// ErrIdempotencyKeyInvalid - 400: 幂等键 Idempotency-Key 的格式不正确。
+	ErrIdempotencyKeyInvalid
+
+	// ErrIdempotencyKeyReused - 400: 幂等键 Idempotency-Key 已被用于不同的请求。
+	ErrIdempotencyKeyReused
+
+	// ErrIdempotencyInProgress - 409: 使用相同幂等键的请求正在处理。
+	ErrIdempotencyInProgress
+
+	// ErrRequestBodyTooLarge - 400: 请求体过大。
+	ErrRequestBodyTooLarge
 )
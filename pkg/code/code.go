@@ -12,7 +12,7 @@ type Code int
 var Codes = map[Code]Coder{}
 var codeMutex = &sync.Mutex{}
 
-var AllowHttpStatus = [7]int{200, 400, 401, 403, 404, 409, 500}
+var AllowHttpStatus = [9]int{200, 400, 401, 403, 404, 409, 429, 500, 503}
 
 type Coder interface {
 	// Code 返回这个Coder的Code值
@@ -70,14 +70,16 @@ func register(code Code, httpStatus int, message string, refs ...string) {
 		}
 	}
 	if !found {
-		log.Panicf("为了方便处理，Orca系统只使用200, 400, 401, 403, 404, 409，500七种HTTP状态码\n" +
+		log.Panicf("为了方便处理，Orca系统只使用200, 400, 401, 403, 404, 409，429，500，503九种HTTP状态码\n" +
 			"200：请求成功\n" +
 			"400：请求存在语法错误，服务器无法理解\n" +
 			"401：客户端未通过身份验证\n" +
 			"403：客户端无权访问指定资源\n" +
 			"404：找不到指定资源\n" +
 			"409：请求的资源存在冲突\n" + 
-			"500：服务器内部发生错误\n")
+			"429：请求过于频繁，超过了限流策略的配额\n" +
+			"500：服务器内部发生错误\n" +
+			"503：服务暂时不可用，例如依赖的数据库无法连接或服务正在关闭\n")
 	}
 	var reference string
 	if len(refs) > 0 {
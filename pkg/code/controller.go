@@ -8,4 +8,16 @@ const (
 
 	// ErrMenuNotFound - 404: 菜单未找到。
 	ErrMenuNotFound Code = iota + 100101
+
+	// ErrMenuParentInvalid - 400: 父级菜单无效。
+	ErrMenuParentInvalid
+
+	// ErrMenuCycle - 400: 菜单层级存在循环引用。
+	ErrMenuCycle
+
+	// ErrMenuHasDependents - 409: 菜单存在子菜单或角色绑定。
+	ErrMenuHasDependents
+
+	// ErrMenuRevisionNotFound - 404: 菜单修订记录未找到。
+	ErrMenuRevisionNotFound
 )
@@ -7,6 +7,20 @@ func init() {
 	register(ErrNotFound, 404, "资源未找到")
 	register(ErrValidate, 400, "字段验证错误")
 	register(ErrBind, 400, "参数绑定错误")
+	register(ErrUnauthorized, 401, "用户未认证")
+	register(ErrConflict, 409, "资源存在冲突")
+	register(ErrPreconditionRequired, 400, "缺少 If-Match 请求头")
+	register(ErrPreconditionFailed, 409, "资源已被其他请求修改")
+	register(ErrServiceUnavailable, 503, "服务暂时不可用")
+	register(ErrTooManyRequests, 429, "请求过于频繁，请稍后再试")
+	register(ErrIdempotencyKeyInvalid, 400, "幂等键 Idempotency-Key 的格式不正确")
+	register(ErrIdempotencyKeyReused, 400, "幂等键 Idempotency-Key 已被用于不同的请求")
+	register(ErrIdempotencyInProgress, 409, "使用相同幂等键的请求正在处理")
+	register(ErrRequestBodyTooLarge, 400, "请求体过大")
 	register(ErrMenuAlreadyExist, 409, "菜单已存在")
 	register(ErrMenuNotFound, 404, "菜单未找到")
+	register(ErrMenuParentInvalid, 400, "父级菜单无效")
+	register(ErrMenuCycle, 400, "菜单层级存在循环引用")
+	register(ErrMenuHasDependents, 409, "菜单存在子菜单或角色绑定")
+	register(ErrMenuRevisionNotFound, 404, "菜单修订记录未找到")
 }
@@ -0,0 +1,207 @@
+// Package cors 按请求路径选择跨域策略，策略来自配置并且可以在运行时重新加载。
+//
+// 每个策略由 github.com/gin-contrib/cors 处理，本包负责校验配置、匹配源和按路径前缀分发请求。
+// 源可以是精确的源（例如 https://admin.example.com）、匹配任意子域名的模式（例如 https://*.example.com）
+// 或 *。* 表示允许所有源，不能与 AllowCredentials 同时使用，浏览器会拒绝这样的响应。
+package cors
+
+import (
+	"fmt"
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultPolicy 未匹配任何路由前缀的请求使用的策略名称
+const DefaultPolicy = "default"
+
+// defaultMethods 策略没有指定方法时允许的方法
+var defaultMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead, http.MethodOptions,
+}
+
+// Policy 跨域策略
+type Policy struct {
+	// AllowOrigins 允许的源，为空时不允许任何跨域请求
+	AllowOrigins []string
+	// AllowMethods 允许的方法，为空时允许常用的方法
+	AllowMethods []string
+	AllowHeaders []string
+	// ExposeHeaders 允许浏览器中的脚本读取的响应头
+	ExposeHeaders    []string
+	AllowCredentials bool
+	// MaxAge 浏览器缓存预检请求结果的时长
+	MaxAge time.Duration
+}
+
+// Route 路径前缀使用的策略，前缀 /me 匹配 /me 和 /me/navigation，不匹配 /menu
+type Route struct {
+	Prefix string
+	Policy string
+}
+
+// Config 跨域配置
+type Config struct {
+	// Policies 以名称为键的策略，名称不区分大小写，没有 default 策略时未匹配的请求不允许跨域
+	Policies map[string]*Policy
+	// Routes 按最长前缀匹配请求路径
+	Routes []Route
+}
+
+// CORS 跨域中间件，Reload 可以在处理请求的同时替换配置
+type CORS struct {
+	current atomic.Pointer[compiled]
+}
+
+// New 校验配置并创建跨域中间件
+func New(cfg *Config) (*CORS, error) {
+	c := &CORS{}
+	if err := c.Reload(cfg); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Reload 校验并替换配置，配置不正确时返回错误并继续使用原来的配置
+func (c *CORS) Reload(cfg *Config) error {
+	next, err := compile(cfg)
+	if err != nil {
+		return err
+	}
+	c.current.Store(next)
+	return nil
+}
+
+// Handler 返回按请求路径选择策略的处理函数，需要注册为全局中间件，预检请求不会匹配到任何路由
+func (c *CORS) Handler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		c.current.Load().handler(ctx.Request.URL.Path)(ctx)
+	}
+}
+
+// compiled 校验后的配置，routes 按前缀长度降序排列
+type compiled struct {
+	routes   []compiledRoute
+	fallback gin.HandlerFunc
+}
+
+type compiledRoute struct {
+	prefix  string
+	handler gin.HandlerFunc
+}
+
+func (c *compiled) handler(path string) gin.HandlerFunc {
+	for _, route := range c.routes {
+		if path == route.prefix || strings.HasPrefix(path, strings.TrimSuffix(route.prefix, "/")+"/") {
+			return route.handler
+		}
+	}
+	return c.fallback
+}
+
+func compile(cfg *Config) (*compiled, error) {
+	handlers := make(map[string]gin.HandlerFunc, len(cfg.Policies))
+	for name, policy := range cfg.Policies {
+		handler, err := policy.handler()
+		if err != nil {
+			return nil, fmt.Errorf("cors: 策略 %s 不正确: %w", name, err)
+		}
+		handlers[strings.ToLower(name)] = handler
+	}
+
+	c := &compiled{fallback: handlers[DefaultPolicy]}
+	if c.fallback == nil {
+		fallback, err := (&Policy{}).handler()
+		if err != nil {
+			return nil, err
+		}
+		c.fallback = fallback
+	}
+	for _, route := range cfg.Routes {
+		if !strings.HasPrefix(route.Prefix, "/") {
+			return nil, fmt.Errorf("cors: 路由前缀 %q 必须以 / 开头", route.Prefix)
+		}
+		handler, ok := handlers[strings.ToLower(route.Policy)]
+		if !ok {
+			return nil, fmt.Errorf("cors: 路由前缀 %s 使用的策略 %q 不存在", route.Prefix, route.Policy)
+		}
+		c.routes = append(c.routes, compiledRoute{prefix: route.Prefix, handler: handler})
+	}
+	sort.SliceStable(c.routes, func(i, j int) bool { return len(c.routes[i].prefix) > len(c.routes[j].prefix) })
+	return c, nil
+}
+
+// handler 根据策略创建 gin-contrib/cors 的处理函数
+func (p *Policy) handler() (gin.HandlerFunc, error) {
+	config := cors.Config{
+		AllowMethods:     p.AllowMethods,
+		AllowHeaders:     p.AllowHeaders,
+		ExposeHeaders:    p.ExposeHeaders,
+		AllowCredentials: p.AllowCredentials,
+		MaxAge:           p.MaxAge,
+	}
+	if len(config.AllowMethods) == 0 {
+		config.AllowMethods = defaultMethods
+	}
+
+	if slices.Contains(p.AllowOrigins, "*") {
+		if len(p.AllowOrigins) > 1 {
+			return nil, fmt.Errorf("* 不能与其他源同时使用")
+		}
+		if p.AllowCredentials {
+			return nil, fmt.Errorf("允许所有源时不能携带凭证，请列出具体的源")
+		}
+		config.AllowAllOrigins = true
+	} else {
+		matchers := make([]func(origin string) bool, 0, len(p.AllowOrigins))
+		for _, origin := range p.AllowOrigins {
+			matcher, err := originMatcher(origin)
+			if err != nil {
+				return nil, err
+			}
+			matchers = append(matchers, matcher)
+		}
+		config.AllowOriginFunc = func(origin string) bool {
+			origin = strings.ToLower(origin)
+			for _, match := range matchers {
+				if match(origin) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return cors.New(config), nil
+}
+
+// originMatcher 解析配置中的源，返回匹配小写的请求源的函数
+func originMatcher(origin string) (func(string) bool, error) {
+	origin = strings.ToLower(origin)
+	u, err := url.Parse(strings.Replace(origin, "://*.", "://wildcard.", 1))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		u.Path != "" || u.RawQuery != "" || u.User != nil || strings.Contains(u.Host, "*") {
+		return nil, fmt.Errorf("源 %q 不正确，应为 https://example.com 或 https://*.example.com", origin)
+	}
+
+	scheme, host, _ := strings.Cut(origin, "://")
+	if suffix, ok := strings.CutPrefix(host, "*"); ok {
+		// *.example.com 匹配任意层级的子域名，不匹配 example.com 本身
+		prefix := scheme + "://"
+		return func(requestOrigin string) bool {
+			requestHost, ok := strings.CutPrefix(requestOrigin, prefix)
+			return ok && len(requestHost) > len(suffix) && strings.HasSuffix(requestHost, suffix) &&
+				!strings.ContainsAny(requestHost, "/@")
+		}, nil
+	}
+	return func(requestOrigin string) bool { return requestOrigin == origin }, nil
+}
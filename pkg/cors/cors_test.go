@@ -0,0 +1,132 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func newConfig() *Config {
+	return &Config{
+		Policies: map[string]*Policy{
+			"default": {
+				AllowOrigins:     []string{"https://admin.example.com", "https://*.example.org"},
+				AllowHeaders:     []string{"Content-Type"},
+				ExposeHeaders:    []string{"X-Request-ID"},
+				AllowCredentials: true,
+				MaxAge:           12 * time.Hour,
+			},
+			"public": {AllowOrigins: []string{"*"}, AllowMethods: []string{http.MethodGet}},
+		},
+		Routes: []Route{{Prefix: "/me", Policy: "public"}},
+	}
+}
+
+// serve 以 origin 发起请求，method 为 OPTIONS 时发起预检请求
+func serve(c *CORS, method, path, origin string) *httptest.ResponseRecorder {
+	engine := gin.New()
+	engine.Use(c.Handler())
+	engine.GET("/*path", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Origin", origin)
+	if method == http.MethodOptions {
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestOrigins(t *testing.T) {
+	c, err := New(newConfig())
+	require.NoError(t, err)
+
+	w := serve(c, http.MethodGet, "/menu", "https://admin.example.com")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://admin.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "X-Request-Id", w.Header().Get("Access-Control-Expose-Headers"))
+
+	for _, origin := range []string{"https://a.example.org", "https://a.b.example.org", "HTTPS://A.EXAMPLE.ORG"} {
+		assert.Equal(t, http.StatusOK, serve(c, http.MethodGet, "/menu", origin).Code, origin)
+	}
+	for _, origin := range []string{"https://example.org", "http://a.example.org", "https://evilexample.org",
+		"https://a.example.org:8443", "https://github.com", "http://admin.example.com"} {
+		assert.Equal(t, http.StatusForbidden, serve(c, http.MethodGet, "/menu", origin).Code, origin)
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	c, err := New(newConfig())
+	require.NoError(t, err)
+
+	w := serve(c, http.MethodOptions, "/menu/tree", "https://admin.example.com")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "GET,POST,PUT,PATCH,DELETE,HEAD,OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "43200", w.Header().Get("Access-Control-Max-Age"))
+}
+
+func TestRoutes(t *testing.T) {
+	c, err := New(newConfig())
+	require.NoError(t, err)
+
+	// /me 和 /me/navigation 使用 public 策略，/menu 不匹配前缀 /me
+	for _, path := range []string{"/me", "/me/navigation"} {
+		w := serve(c, http.MethodGet, path, "https://github.com")
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"), path)
+	}
+	assert.Equal(t, http.StatusForbidden, serve(c, http.MethodGet, "/menu", "https://github.com").Code)
+}
+
+func TestWithoutDefault(t *testing.T) {
+	c, err := New(&Config{})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, serve(c, http.MethodGet, "/menu", "https://admin.example.com").Code)
+	// 没有 Origin 的请求不是跨域请求
+	assert.Equal(t, http.StatusOK, serve(c, http.MethodGet, "/menu", "").Code)
+}
+
+func TestReload(t *testing.T) {
+	c, err := New(newConfig())
+	require.NoError(t, err)
+
+	invalid := newConfig()
+	invalid.Policies["public"].AllowCredentials = true
+	assert.ErrorContains(t, c.Reload(invalid), "策略 public 不正确")
+	assert.Equal(t, http.StatusOK, serve(c, http.MethodGet, "/menu", "https://admin.example.com").Code)
+
+	next := newConfig()
+	next.Policies["default"].AllowOrigins = []string{"https://github.com"}
+	require.NoError(t, c.Reload(next))
+	assert.Equal(t, http.StatusForbidden, serve(c, http.MethodGet, "/menu", "https://admin.example.com").Code)
+	assert.Equal(t, http.StatusOK, serve(c, http.MethodGet, "/menu", "https://github.com").Code)
+}
+
+func TestInvalidConfig(t *testing.T) {
+	invalid := []*Config{
+		{Policies: map[string]*Policy{"default": {AllowOrigins: []string{"*"}, AllowCredentials: true}}},
+		{Policies: map[string]*Policy{"default": {AllowOrigins: []string{"*", "https://example.com"}}}},
+		{Policies: map[string]*Policy{"default": {AllowOrigins: []string{"example.com"}}}},
+		{Policies: map[string]*Policy{"default": {AllowOrigins: []string{"https://example.com/"}}}},
+		{Policies: map[string]*Policy{"default": {AllowOrigins: []string{"https://a.*.example.com"}}}},
+		{Policies: map[string]*Policy{"default": {AllowOrigins: []string{"ftp://example.com"}}}},
+		{Routes: []Route{{Prefix: "/me", Policy: "missing"}}},
+		{Policies: map[string]*Policy{"public": {}}, Routes: []Route{{Prefix: "me", Policy: "public"}}},
+	}
+	for _, cfg := range invalid {
+		_, err := New(cfg)
+		assert.Error(t, err)
+	}
+}
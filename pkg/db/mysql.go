@@ -5,29 +5,22 @@ import (
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"orca/conf"
-	"sync"
 )
 
-// Mysql Global database connection.
-var Mysql *gorm.DB
-var once sync.Once
+// NewMysql 根据配置创建 MySQL 连接
+func NewMysql() (*gorm.DB, error) {
+	dsn := fmt.Sprintf(`%s:%s@tcp(%s:%s)/%s?charset=utf8&parseTime=%t&loc=%s`,
+		conf.GetString("database.user"),
+		conf.GetString("database.password"),
+		conf.GetString("database.host"),
+		conf.GetString("database.port"),
+		conf.GetString("database.dbname"),
+		true,
+		"Local")
 
-func InitMysql() {
-	once.Do(func() {
-		dsn := fmt.Sprintf(`%s:%s@tcp(%s:%s)/%s?charset=utf8&parseTime=%t&loc=%s`,
-			conf.GetString("database.user"),
-			conf.GetString("database.password"),
-			conf.GetString("database.host"),
-			conf.GetString("database.port"),
-			conf.GetString("database.dbname"),
-			true,
-			"Local")
-
-		var err error
-		Mysql, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
-
-		if err != nil {
-			panic("Failed to connect mysql database")
-		}
-	})
+	mysqlDB, err := gorm.Open(mysql.Open(dsn), &gorm.Config{TranslateError: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect mysql database: %w", err)
+	}
+	return mysqlDB, nil
 }
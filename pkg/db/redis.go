@@ -7,18 +7,17 @@ import (
 	"orca/conf"
 )
 
-var Redis *redis.Client
-
-func InitRedis() {
-	var c = context.Background()
-	Redis = redis.NewClient(&redis.Options{
+// NewRedis 根据配置创建 Redis 客户端，并检查服务是否可用
+func NewRedis(ctx context.Context) (*redis.Client, error) {
+	client := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%s", conf.GetString("redis.host"), conf.GetString("redis.port")),
 		Password: conf.GetString("redis.password"),
 		DB:       conf.GetInt("redis.db"),
 	})
 
-	_, err := Redis.Ping(c).Result()
-	if err != nil {
-		panic("Failed to connect redis server")
+	if _, err := client.Ping(ctx).Result(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to connect redis server: %w", err)
 	}
+	return client, nil
 }
@@ -0,0 +1,95 @@
+// Package etag 提供基于资源版本号的 ETag 生成和条件请求处理，用于乐观并发控制。
+//
+// 读取资源时：
+//
+//	if etag.NotModified(c, menu.ETag()) {
+//	        return // 已返回 304
+//	}
+//
+// 修改资源时，客户端需要在 If-Match 中携带读取时得到的 ETag：
+//
+//	if err := etag.Precondition(c, menu.ETag()); err != nil {
+//	        response.FailWithData(c, err, menu) // 409 并返回最新的资源
+//	}
+package etag
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+)
+
+const (
+	HeaderETag        = "ETag"
+	HeaderIfMatch     = "If-Match"
+	HeaderIfNoneMatch = "If-None-Match"
+)
+
+// Of 根据资源ID和版本号生成强 ETag，资源每次修改后版本号递增，ETag 随之变化
+func Of(id uint64, version uint64) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%d:%d", id, version)))
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// parse 解析 If-Match 和 If-None-Match 中的 ETag 列表
+func parse(header string) []string {
+	var tags []string
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// Match 使用强比较判断 If-Match 头是否包含 tag，弱 ETag 永远不匹配
+func Match(header, tag string) bool {
+	for _, t := range parse(header) {
+		if t == "*" || (t == tag && !strings.HasPrefix(t, "W/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// NoneMatch 使用弱比较判断 If-None-Match 头是否包含 tag
+func NoneMatch(header, tag string) bool {
+	tag = strings.TrimPrefix(tag, "W/")
+	for _, t := range parse(header) {
+		if t == "*" || strings.TrimPrefix(t, "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// NotModified 设置响应的 ETag 头，If-None-Match 与 tag 匹配时返回 304 并返回 true
+func NotModified(c *gin.Context, tag string) bool {
+	c.Header(HeaderETag, tag)
+	if NoneMatch(c.GetHeader(HeaderIfNoneMatch), tag) {
+		c.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// Precondition 校验 If-Match 头，缺少该请求头或与 tags 中任意一个不匹配时返回错误。
+// 一次修改多个资源时，If-Match 中需要包含每个资源的 ETag。
+func Precondition(c *gin.Context, tags ...string) error {
+	header := c.GetHeader(HeaderIfMatch)
+	if header == "" {
+		return errors.WithCode(code.ErrPreconditionRequired, "修改资源时必须携带 If-Match 请求头")
+	}
+	for _, tag := range tags {
+		if !Match(header, tag) {
+			return errors.WithCode(code.ErrPreconditionFailed, "资源已被修改，当前 ETag 为 %s", tag)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package etag
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+)
+
+func TestOf(t *testing.T) {
+	assert.Equal(t, Of(1, 1), Of(1, 1))
+	assert.NotEqual(t, Of(1, 1), Of(1, 2))
+	assert.NotEqual(t, Of(1, 2), Of(2, 1))
+	assert.Regexp(t, `^"[0-9a-f]{16}"$`, Of(1, 1))
+}
+
+func TestMatch(t *testing.T) {
+	tag := Of(1, 1)
+	assert.True(t, Match(tag, tag))
+	assert.True(t, Match(`"other", `+tag, tag))
+	assert.True(t, Match("*", tag))
+	assert.False(t, Match("W/"+tag, tag))
+	assert.False(t, Match(Of(1, 2), tag))
+	assert.False(t, Match("", tag))
+}
+
+func TestNoneMatch(t *testing.T) {
+	tag := Of(1, 1)
+	assert.True(t, NoneMatch(tag, tag))
+	assert.True(t, NoneMatch("W/"+tag, tag))
+	assert.True(t, NoneMatch(`"a" , `+tag, tag))
+	assert.True(t, NoneMatch("*", tag))
+	assert.False(t, NoneMatch(Of(1, 2), tag))
+}
+
+func context(header, value string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	if header != "" {
+		c.Request.Header.Set(header, value)
+	}
+	return c, w
+}
+
+func TestNotModified(t *testing.T) {
+	tag := Of(1, 1)
+
+	c, w := context(HeaderIfNoneMatch, tag)
+	assert.True(t, NotModified(c, tag))
+	c.Writer.WriteHeaderNow()
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, tag, w.Header().Get(HeaderETag))
+
+	c, w = context("", "")
+	assert.False(t, NotModified(c, tag))
+	assert.Equal(t, tag, w.Header().Get(HeaderETag))
+}
+
+func TestPrecondition(t *testing.T) {
+	c, _ := context("", "")
+	assert.True(t, errors.IsCode(Precondition(c, Of(1, 1)), code.ErrPreconditionRequired))
+
+	c, _ = context(HeaderIfMatch, Of(1, 1))
+	assert.True(t, errors.IsCode(Precondition(c, Of(1, 2)), code.ErrPreconditionFailed))
+
+	c, _ = context(HeaderIfMatch, Of(1, 1)+", "+Of(2, 5))
+	assert.NoError(t, Precondition(c, Of(1, 1), Of(2, 5)))
+}
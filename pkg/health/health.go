@@ -0,0 +1,149 @@
+// Package health 实现存活和就绪检查。
+//
+// 各模块通过 Registry.Register 注册自己依赖的检查项，例如数据库和 Redis 的连通性。
+// 就绪检查并发执行所有检查项，每项都有独立的超时时间；服务关闭前调用 Drain，
+// 之后就绪检查始终返回未就绪，负载均衡器会在连接排空期间停止向该实例转发新的请求。
+package health
+
+import (
+	"context"
+	"orca/pkg/errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Checker 检查一项依赖是否可用，不可用时返回错误
+type Checker func(ctx context.Context) error
+
+// Status 检查结果的状态
+type Status string
+
+const (
+	// StatusUp 依赖可用
+	StatusUp Status = "up"
+	// StatusDown 依赖不可用或检查超时
+	StatusDown Status = "down"
+)
+
+// Result 单项检查的结果
+type Result struct {
+	Status Status `json:"status"`
+	// LatencyMs 检查耗时，单位为毫秒
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report 就绪检查的结果，所有检查项都可用且服务不在排空中时 Status 为 up
+type Report struct {
+	Status   Status             `json:"status"`
+	Draining bool               `json:"draining"`
+	Checks   map[string]*Result `json:"checks"`
+}
+
+// Registry 检查项的注册表，可以被多个 goroutine 同时使用
+type Registry struct {
+	mu       sync.RWMutex
+	checkers map[string]Checker
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+// New 创建注册表，timeout 为每个检查项的超时时间
+func New(timeout time.Duration) *Registry {
+	return &Registry{checkers: make(map[string]Checker), timeout: timeout}
+}
+
+// Register 注册名为 name 的检查项，同名的检查项会被替换
+func (r *Registry) Register(name string, checker Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkers[name] = checker
+}
+
+// Drain 将服务标记为排空中，之后的就绪检查都返回未就绪
+func (r *Registry) Drain() {
+	r.draining.Store(true)
+}
+
+// Draining 返回服务是否在排空中
+func (r *Registry) Draining() bool {
+	return r.draining.Load()
+}
+
+// Check 并发执行所有检查项并汇总结果
+func (r *Registry) Check(ctx context.Context) *Report {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.checkers))
+	for name := range r.checkers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	checkers := make([]Checker, len(names))
+	for i, name := range names {
+		checkers[i] = r.checkers[name]
+	}
+	r.mu.RUnlock()
+
+	results := make([]*Result, len(names))
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.run(ctx, checker)
+		}()
+	}
+	wg.Wait()
+
+	report := &Report{
+		Status:   StatusUp,
+		Draining: r.Draining(),
+		Checks:   make(map[string]*Result, len(names)),
+	}
+	if report.Draining {
+		report.Status = StatusDown
+	}
+	for i, name := range names {
+		report.Checks[name] = results[i]
+		if results[i].Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+// run 在超时时间内执行检查项，检查项没有响应 ctx 的取消时也会在超时后返回
+func (r *Registry) run(ctx context.Context, checker Checker) *Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if v := recover(); v != nil {
+				done <- errors.Errorf("检查项发生 panic：%v", v)
+			}
+		}()
+		done <- checker(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := &Result{
+		Status:    StatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,74 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"orca/pkg/errors"
+)
+
+func TestCheck(t *testing.T) {
+	r := New(time.Second)
+	r.Register("mysql", func(ctx context.Context) error { return nil })
+	r.Register("redis", func(ctx context.Context) error { return errors.New("connection refused") })
+
+	report := r.Check(context.Background())
+	assert.Equal(t, StatusDown, report.Status)
+	assert.False(t, report.Draining)
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, StatusUp, report.Checks["mysql"].Status)
+	assert.Empty(t, report.Checks["mysql"].Error)
+	assert.Equal(t, StatusDown, report.Checks["redis"].Status)
+	assert.Equal(t, "connection refused", report.Checks["redis"].Error)
+}
+
+func TestCheckTimeout(t *testing.T) {
+	r := New(20 * time.Millisecond)
+	// 不响应 ctx 取消的检查项也会在超时后返回
+	block := make(chan struct{})
+	defer close(block)
+	r.Register("slow", func(ctx context.Context) error {
+		<-block
+		return nil
+	})
+
+	start := time.Now()
+	report := r.Check(context.Background())
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+}
+
+func TestCheckPanic(t *testing.T) {
+	r := New(time.Second)
+	r.Register("broken", func(ctx context.Context) error { panic("boom") })
+
+	report := r.Check(context.Background())
+	assert.Equal(t, StatusDown, report.Checks["broken"].Status)
+	assert.Contains(t, report.Checks["broken"].Error, "boom")
+}
+
+func TestDrain(t *testing.T) {
+	r := New(time.Second)
+	r.Register("mysql", func(ctx context.Context) error { return nil })
+	assert.Equal(t, StatusUp, r.Check(context.Background()).Status)
+
+	r.Drain()
+	report := r.Check(context.Background())
+	assert.True(t, report.Draining)
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, StatusUp, report.Checks["mysql"].Status)
+}
+
+func TestRegisterReplaces(t *testing.T) {
+	r := New(time.Second)
+	r.Register("redis", func(ctx context.Context) error { return errors.New("down") })
+	r.Register("redis", func(ctx context.Context) error { return nil })
+
+	report := r.Check(context.Background())
+	assert.Equal(t, StatusUp, report.Status)
+	assert.Len(t, report.Checks, 1)
+}
@@ -0,0 +1,167 @@
+// Package idempotency 在 Redis 中保存幂等键对应的请求指纹和响应，用于重放重试的请求。
+//
+// 第一个请求通过 Lock 占用幂等键，处理完成后通过 Complete 保存响应，处理失败时通过 Release 释放幂等键。
+// 占用期间幂等键的有效期为 lockTimeout，避免进程崩溃后幂等键永远无法使用；保存响应后有效期为 retention。
+// Lock 返回随机的令牌，Complete 和 Release 只在幂等键仍由该令牌占用时生效，
+// 第一个请求超时后幂等键被其他请求占用时，不会覆盖或删除其他请求的记录。
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"net/http"
+	"time"
+)
+
+// maxKeyLength 幂等键的最大长度
+const maxKeyLength = 255
+
+// ErrNotOwner 幂等键已过期或已被其他请求占用，Complete 和 Release 没有修改它
+var ErrNotOwner = errors.New("idempotency: 幂等键已不再由当前请求占用")
+
+// Record 幂等键对应的请求和响应
+type Record struct {
+	// Fingerprint 请求的指纹，相同的幂等键只能用于指纹相同的请求
+	Fingerprint string `json:"fingerprint"`
+	// Token 占用幂等键的请求的令牌，保存响应后为空
+	Token string `json:"token,omitempty"`
+	// Completed 为 false 表示第一个请求仍在处理
+	Completed bool        `json:"completed"`
+	Status    int         `json:"status,omitempty"`
+	Header    http.Header `json:"header,omitempty"`
+	Body      []byte      `json:"body,omitempty"`
+}
+
+// lock 幂等键不存在时占用它，存在时返回已有的记录
+var lock = redis.NewScript(`
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+  return false
+end
+return redis.call('GET', KEYS[1])
+`)
+
+// complete 幂等键仍由令牌 ARGV[1] 占用时保存响应 ARGV[2]，有效期为 ARGV[3] 毫秒
+var complete = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if not current or cjson.decode(current).token ~= ARGV[1] then
+  return 0
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+return 1
+`)
+
+// release 幂等键仍由令牌 ARGV[1] 占用时删除它
+var release = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if not current or cjson.decode(current).token ~= ARGV[1] then
+  return 0
+end
+return redis.call('DEL', KEYS[1])
+`)
+
+// Store 在 Redis 中保存幂等键的记录
+type Store struct {
+	client      redis.Cmdable
+	prefix      string
+	lockTimeout time.Duration
+	retention   time.Duration
+}
+
+// NewStore 创建 Store，prefix 为 Redis 键的前缀，lockTimeout 为第一个请求处理的最长时间，retention 为响应的保留时长
+func NewStore(client redis.Cmdable, prefix string, lockTimeout, retention time.Duration) *Store {
+	return &Store{client: client, prefix: prefix, lockTimeout: lockTimeout, retention: retention}
+}
+
+// Lock 以 fingerprint 占用幂等键 key。占用成功时返回令牌，之后通过它保存响应或释放幂等键；
+// 幂等键已被占用或已保存响应时返回已有的记录。
+func (s *Store) Lock(ctx context.Context, key, fingerprint string) (string, *Record, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", nil, fmt.Errorf("idempotency: 生成令牌失败: %w", err)
+	}
+	data, err := json.Marshal(&Record{Fingerprint: fingerprint, Token: token})
+	if err != nil {
+		return "", nil, err
+	}
+	existing, err := lock.Run(ctx, s.client, []string{s.prefix + key}, data, s.lockTimeout.Milliseconds()).Text()
+	if err == redis.Nil {
+		return token, nil, nil
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("idempotency: 占用幂等键失败: %w", err)
+	}
+	var record Record
+	if err := json.Unmarshal([]byte(existing), &record); err != nil {
+		return "", nil, fmt.Errorf("idempotency: 解析幂等键的记录失败: %w", err)
+	}
+	record.Token = ""
+	return "", &record, nil
+}
+
+// Complete 保存第一个请求的响应，之后使用相同幂等键的请求重放该响应。
+// 幂等键已不再由 token 占用时不做修改并返回 ErrNotOwner。
+func (s *Store) Complete(ctx context.Context, key, token string, record *Record) error {
+	record.Completed = true
+	record.Token = ""
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	saved, err := complete.Run(ctx, s.client, []string{s.prefix + key}, token, data, s.retention.Milliseconds()).Int()
+	if err != nil {
+		return fmt.Errorf("idempotency: 保存响应失败: %w", err)
+	}
+	if saved == 0 {
+		return ErrNotOwner
+	}
+	return nil
+}
+
+// Release 释放幂等键，第一个请求处理失败时调用，之后的请求可以重新处理。
+// 幂等键已不再由 token 占用时不做修改并返回 ErrNotOwner。
+func (s *Store) Release(ctx context.Context, key, token string) error {
+	released, err := release.Run(ctx, s.client, []string{s.prefix + key}, token).Int()
+	if err != nil {
+		return fmt.Errorf("idempotency: 释放幂等键失败: %w", err)
+	}
+	if released == 0 {
+		return ErrNotOwner
+	}
+	return nil
+}
+
+// newToken 生成占用幂等键的随机令牌
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ValidKey 返回幂等键是否合法，幂等键由1到255个可见的 ASCII 字符组成，例如 UUID
+func ValidKey(key string) bool {
+	if key == "" || len(key) > maxKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// Fingerprint 根据请求方法、包含查询参数的路径和请求体计算请求的指纹
+func Fingerprint(method, uri string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + uri + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
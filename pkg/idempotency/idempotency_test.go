@@ -0,0 +1,117 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStore(t *testing.T) (*Store, *miniredis.Miniredis) {
+	m := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: m.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return NewStore(client, "test:", time.Minute, time.Hour), m
+}
+
+func TestLockAndComplete(t *testing.T) {
+	store, m := newStore(t)
+	ctx := context.Background()
+
+	token, existing, err := store.Lock(ctx, "key", "a")
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+	assert.NotEmpty(t, token)
+	assert.Equal(t, time.Minute, m.TTL("test:key"))
+
+	// 第一个请求处理期间返回未完成的记录，不包括令牌
+	other, existing, err := store.Lock(ctx, "key", "a")
+	require.NoError(t, err)
+	assert.Empty(t, other)
+	assert.Equal(t, &Record{Fingerprint: "a"}, existing)
+
+	record := &Record{Fingerprint: "a", Status: http.StatusOK, Header: http.Header{"Content-Type": {"application/json"}}, Body: []byte(`{"code":100001}`)}
+	require.NoError(t, store.Complete(ctx, "key", token, record))
+	assert.Equal(t, time.Hour, m.TTL("test:key"))
+
+	_, existing, err = store.Lock(ctx, "key", "b")
+	require.NoError(t, err)
+	assert.True(t, existing.Completed)
+	assert.Equal(t, "a", existing.Fingerprint)
+	assert.Empty(t, existing.Token)
+	assert.Equal(t, http.StatusOK, existing.Status)
+	assert.Equal(t, "application/json", existing.Header.Get("Content-Type"))
+	assert.Equal(t, `{"code":100001}`, string(existing.Body))
+
+	// 保存响应后不能再释放幂等键
+	assert.ErrorIs(t, store.Release(ctx, "key", token), ErrNotOwner)
+	assert.True(t, m.Exists("test:key"))
+}
+
+func TestRelease(t *testing.T) {
+	store, m := newStore(t)
+	ctx := context.Background()
+
+	token, _, err := store.Lock(ctx, "key", "a")
+	require.NoError(t, err)
+	require.NoError(t, store.Release(ctx, "key", token))
+
+	_, existing, err := store.Lock(ctx, "key", "b")
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+
+	// 第一个请求超时后幂等键自动释放
+	m.FastForward(time.Minute)
+	_, existing, err = store.Lock(ctx, "key", "c")
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+}
+
+func TestLockExpired(t *testing.T) {
+	store, m := newStore(t)
+	ctx := context.Background()
+
+	first, _, err := store.Lock(ctx, "key", "a")
+	require.NoError(t, err)
+	// 第一个请求超时后，第二个请求占用了幂等键
+	m.FastForward(time.Minute)
+	second, _, err := store.Lock(ctx, "key", "a")
+	require.NoError(t, err)
+	require.NotEqual(t, first, second)
+
+	// 第一个请求不能释放或覆盖第二个请求占用的幂等键
+	assert.ErrorIs(t, store.Release(ctx, "key", first), ErrNotOwner)
+	assert.ErrorIs(t, store.Complete(ctx, "key", first, &Record{Fingerprint: "a", Status: http.StatusOK}), ErrNotOwner)
+	_, existing, err := store.Lock(ctx, "key", "a")
+	require.NoError(t, err)
+	assert.False(t, existing.Completed)
+
+	require.NoError(t, store.Complete(ctx, "key", second, &Record{Fingerprint: "a", Status: http.StatusOK}))
+	// 幂等键不存在时也不能保存响应
+	m.FastForward(time.Hour)
+	assert.ErrorIs(t, store.Complete(ctx, "key", second, &Record{Fingerprint: "a"}), ErrNotOwner)
+	assert.False(t, m.Exists("test:key"))
+}
+
+func TestValidKey(t *testing.T) {
+	assert.True(t, ValidKey("9f1c7a4e-3b2d-4c5e-8f6a-1b2c3d4e5f60"))
+	assert.True(t, ValidKey("order:42/retry"))
+	assert.False(t, ValidKey(""))
+	assert.False(t, ValidKey("with space"))
+	assert.False(t, ValidKey("键"))
+	assert.False(t, ValidKey(strings.Repeat("a", maxKeyLength+1)))
+}
+
+func TestFingerprint(t *testing.T) {
+	fingerprint := Fingerprint(http.MethodPost, "/menu", []byte(`{"code":"a"}`))
+	assert.Equal(t, fingerprint, Fingerprint(http.MethodPost, "/menu", []byte(`{"code":"a"}`)))
+	assert.NotEqual(t, fingerprint, Fingerprint(http.MethodPost, "/menu", []byte(`{"code":"b"}`)))
+	assert.NotEqual(t, fingerprint, Fingerprint(http.MethodPost, "/menu/import", []byte(`{"code":"a"}`)))
+	assert.NotEqual(t, fingerprint, Fingerprint(http.MethodPost, "/menu?dryRun=true", []byte(`{"code":"a"}`)))
+}
@@ -0,0 +1,99 @@
+// Package locale 根据 Accept-Language 请求头在系统支持的语言中选择响应使用的语言。
+//
+// 支持的语言和默认语言通过配置文件设置：
+//
+//	i18n:
+//	  defaultLocale: "zh-CN"
+//	  locales: ["zh-CN", "en-US"]
+package locale
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"orca/conf"
+)
+
+const (
+	HeaderAcceptLanguage  = "Accept-Language"
+	HeaderContentLanguage = "Content-Language"
+)
+
+// Default 返回默认语言，请求的语言都不受支持时使用
+func Default() string {
+	return conf.GetString("i18n.defaultLocale", "zh-CN")
+}
+
+// Supported 返回系统支持的语言
+func Supported() []string {
+	return conf.GetStringSlice("i18n.locales", []string{"zh-CN", "en-US"})
+}
+
+// FromRequest 根据 Accept-Language 选择响应使用的语言，并设置 Content-Language 响应头
+func FromRequest(c *gin.Context) string {
+	locale, ok := Match(Preferred(c.GetHeader(HeaderAcceptLanguage)), Supported())
+	if !ok {
+		locale = Default()
+	}
+	c.Header(HeaderContentLanguage, locale)
+	return locale
+}
+
+// Preferred 解析 Accept-Language，按权重从高到低返回语言标签，忽略权重为 0 的语言和通配符
+func Preferred(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	preferred := make([]string, len(tags))
+	for i, tag := range tags {
+		preferred[i] = tag.tag
+	}
+	return preferred
+}
+
+// Match 按照 preferred 的顺序在 available 中查找语言，先比较完整的标签，再比较主语言，
+// 例如 en-GB 和 en 都可以匹配 en-US。标签比较不区分大小写。
+func Match(preferred, available []string) (string, bool) {
+	for _, tag := range preferred {
+		for _, a := range available {
+			if strings.EqualFold(tag, a) {
+				return a, true
+			}
+		}
+		for _, a := range available {
+			if strings.EqualFold(base(tag), base(a)) {
+				return a, true
+			}
+		}
+	}
+	return "", false
+}
+
+// base 返回语言标签的主语言部分
+func base(tag string) string {
+	language, _, _ := strings.Cut(tag, "-")
+	return language
+}
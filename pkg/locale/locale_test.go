@@ -0,0 +1,36 @@
+package locale
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreferred(t *testing.T) {
+	assert.Equal(t, []string{"en-US", "zh-CN", "en"},
+		Preferred("zh-CN;q=0.9, en-US, en;q=0.8, fr;q=0, *;q=0.1"))
+	assert.Equal(t, []string{"ja"}, Preferred("ja, de;q=bad"))
+	assert.Empty(t, Preferred(""))
+}
+
+func TestMatch(t *testing.T) {
+	available := []string{"zh-CN", "en-US"}
+	tests := []struct {
+		preferred []string
+		want      string
+		ok        bool
+	}{
+		{[]string{"en-US"}, "en-US", true},
+		{[]string{"zh-cn"}, "zh-CN", true},
+		{[]string{"en-GB"}, "en-US", true},
+		{[]string{"en"}, "en-US", true},
+		{[]string{"fr", "zh-TW"}, "zh-CN", true},
+		{[]string{"fr"}, "", false},
+		{nil, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Match(tt.preferred, available)
+		assert.Equal(t, tt.want, got, tt.preferred)
+		assert.Equal(t, tt.ok, ok, tt.preferred)
+	}
+}
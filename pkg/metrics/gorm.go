@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// startKey 在 GORM 语句实例中保存开始时间的键
+const startKey = "metrics:start"
+
+// gormPlugin 通过 GORM 的回调记录每条 SQL 的耗时
+type gormPlugin struct {
+	m *Metrics
+}
+
+// NewGormPlugin 创建记录 SQL 耗时的 GORM 插件，通过 db.Use 注册
+func NewGormPlugin(m *Metrics) gorm.Plugin {
+	return &gormPlugin{m: m}
+}
+
+func (p *gormPlugin) Name() string {
+	return "metrics"
+}
+
+// Initialize 在每类操作的回调链首尾分别注册开始和结束的回调
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	type register func(name string, fn func(*gorm.DB)) error
+	callback := db.Callback()
+	// 每类操作在回调链首尾的注册函数
+	operations := map[string][2]register{
+		"create": {callback.Create().Before("*").Register, callback.Create().After("*").Register},
+		"query":  {callback.Query().Before("*").Register, callback.Query().After("*").Register},
+		"update": {callback.Update().Before("*").Register, callback.Update().After("*").Register},
+		"delete": {callback.Delete().Before("*").Register, callback.Delete().After("*").Register},
+		"row":    {callback.Row().Before("*").Register, callback.Row().After("*").Register},
+		"raw":    {callback.Raw().Before("*").Register, callback.Raw().After("*").Register},
+	}
+	for operation, registers := range operations {
+		if err := registers[0]("metrics:before_"+operation, before); err != nil {
+			return err
+		}
+		if err := registers[1]("metrics:after_"+operation, p.after(operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func before(db *gorm.DB) {
+	db.InstanceSet(startKey, time.Now())
+}
+
+func (p *gormPlugin) after(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(startKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+		err := db.Error
+		if err == gorm.ErrRecordNotFound {
+			err = nil
+		}
+		p.m.queryDuration.WithLabelValues(operation, db.Statement.Table, statusOf(err)).
+			Observe(time.Since(start).Seconds())
+	}
+}
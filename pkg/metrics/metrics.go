@@ -0,0 +1,100 @@
+// Package metrics 以 Prometheus 文本格式导出运行时指标，包括 HTTP 请求、业务错误、
+// GORM 查询和 Redis 命令的计数与耗时，以及 Go 运行时和进程的指标。
+//
+// 指标注册在 Metrics 自己的 Registry 中而不是 Prometheus 的全局注册表，
+// 因此在测试中可以创建多个互不影响的实例。
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"orca/pkg/code"
+	"strconv"
+	"time"
+)
+
+const namespace = "orca"
+
+// Metrics 应用的所有指标
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests  *prometheus.CounterVec
+	httpDuration  *prometheus.HistogramVec
+	errors        *prometheus.CounterVec
+	queryDuration *prometheus.HistogramVec
+	redisDuration *prometheus.HistogramVec
+}
+
+// New 创建并注册所有指标
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP 请求数，按路由模板、方法和状态码分组",
+		}, []string{"route", "method", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP 请求的处理耗时，按路由模板、方法和状态码分组",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "errors_total",
+			Help:      "返回给客户端的业务错误数，按错误码分组",
+		}, []string{"code"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "GORM 执行 SQL 的耗时，按操作和表名分组",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "table", "status"}),
+		redisDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "redis_command_duration_seconds",
+			Help:      "Redis 命令的耗时，按命令分组，pipeline 记为一次",
+			Buckets:   []float64{.0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1, .25},
+		}, []string{"command", "status"}),
+	}
+	m.registry.MustRegister(
+		m.httpRequests, m.httpDuration, m.errors, m.queryDuration, m.redisDuration,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler 返回以 Prometheus 文本格式输出所有指标的 HTTP 处理器
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Registry 返回指标所在的注册表，模块可以向其中注册自己的指标
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// ObserveRequest 记录一次 HTTP 请求，route 为路由模板，例如 /menu/:code，而不是实际的路径
+func (m *Metrics) ObserveRequest(route, method string, status int, duration time.Duration) {
+	labels := prometheus.Labels{"route": route, "method": method, "status": strconv.Itoa(status)}
+	m.httpRequests.With(labels).Inc()
+	m.httpDuration.With(labels).Observe(duration.Seconds())
+}
+
+// ObserveError 记录一次返回给客户端的业务错误
+func (m *Metrics) ObserveError(c code.Code) {
+	m.errors.WithLabelValues(strconv.Itoa(int(c))).Inc()
+}
+
+// statusOf 将错误转换为 status 标签的值
+func statusOf(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"orca/pkg/code"
+)
+
+func TestObserveRequest(t *testing.T) {
+	m := New()
+	m.ObserveRequest("/menu/:code", http.MethodGet, http.StatusOK, 20*time.Millisecond)
+	m.ObserveRequest("/menu/:code", http.MethodGet, http.StatusOK, 30*time.Millisecond)
+	m.ObserveRequest("/menu/:code", http.MethodGet, http.StatusNotFound, time.Millisecond)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("/menu/:code", "GET", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("/menu/:code", "GET", "404")))
+	assert.Equal(t, uint64(2), sampleCount(t, m.httpDuration.WithLabelValues("/menu/:code", "GET", "200")))
+}
+
+// sampleCount 返回直方图中的样本数
+func sampleCount(t *testing.T, observer prometheus.Observer) uint64 {
+	var metric dto.Metric
+	require.NoError(t, observer.(prometheus.Metric).Write(&metric))
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestObserveError(t *testing.T) {
+	m := New()
+	m.ObserveError(code.ErrValidate)
+	m.ObserveError(code.ErrValidate)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.errors.WithLabelValues("100005")))
+}
+
+func TestGormPlugin(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+
+	m := New()
+	require.NoError(t, db.Use(NewGormPlugin(m)))
+
+	var rows []map[string]any
+	require.NoError(t, db.Table("menu").Where("code = ?", "system").Find(&rows).Error)
+	require.NoError(t, db.Table("menu").Where("code = ?", "system").Update("label", "系统").Error)
+
+	assert.Equal(t, uint64(1), sampleCount(t, m.queryDuration.WithLabelValues("query", "menu", "ok")))
+	assert.Equal(t, uint64(1), sampleCount(t, m.queryDuration.WithLabelValues("update", "menu", "ok")))
+}
+
+func TestRedisHook(t *testing.T) {
+	// 连接一个不存在的服务，命令失败时同样记录耗时
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	defer client.Close()
+
+	m := New()
+	client.AddHook(NewRedisHook(m))
+	assert.Error(t, client.Get(context.Background(), "key").Err())
+
+	assert.Equal(t, uint64(1), sampleCount(t, m.redisDuration.WithLabelValues("get", "error")))
+}
+
+func TestHandler(t *testing.T) {
+	m := New()
+	m.ObserveRequest("/healthz", http.MethodGet, http.StatusOK, time.Millisecond)
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `orca_http_requests_total{method="GET",route="/healthz",status="200"} 1`)
+	assert.Contains(t, w.Body.String(), "go_goroutines")
+}
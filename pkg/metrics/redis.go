@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"time"
+)
+
+type redisStartKey struct{}
+
+// redisHook 记录每个 Redis 命令的耗时
+type redisHook struct {
+	m *Metrics
+}
+
+// NewRedisHook 创建记录命令耗时的 Redis 钩子，通过 client.AddHook 注册
+func NewRedisHook(m *Metrics) redis.Hook {
+	return &redisHook{m: m}
+}
+
+func (h *redisHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, redisStartKey{}, time.Now()), nil
+}
+
+func (h *redisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	h.observe(ctx, cmd.Name(), cmd.Err())
+	return nil
+}
+
+func (h *redisHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, redisStartKey{}, time.Now()), nil
+}
+
+func (h *redisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if cmd.Err() != nil {
+			err = cmd.Err()
+			break
+		}
+	}
+	h.observe(ctx, "pipeline", err)
+	return nil
+}
+
+func (h *redisHook) observe(ctx context.Context, command string, err error) {
+	start, ok := ctx.Value(redisStartKey{}).(time.Time)
+	if !ok {
+		return
+	}
+	// 键不存在不是错误
+	if err == redis.Nil {
+		err = nil
+	}
+	h.m.redisDuration.WithLabelValues(command, statusOf(err)).Observe(time.Since(start).Seconds())
+}
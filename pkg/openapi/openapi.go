@@ -0,0 +1,146 @@
+// Package openapi 根据路由、模型和验证规则生成 OpenAPI 3 文档，并提供内嵌的 Swagger UI。
+//
+// 文档中的路径来自 gin 注册的路由，每个路由的请求和响应模型通过 Spec.Add 声明。
+// 通过 route.Router 注册的路由在注册时声明，由 router 包统一添加到 Spec：
+//
+//	menus.POST("", "menu:create", "创建菜单", &openapi.Operation{
+//		Request: &models.Menu{},
+//		Errors:  []code.Code{code.ErrBind, code.ErrValidate, code.ErrMenuAlreadyExist},
+//	}, menuController.Create)
+//
+// 模型的字段名来自 json 标签，必填、长度、枚举、范围和格式等约束来自模型 Validate 方法中声明的规则，
+// 错误响应中列出的错误码和消息来自 code.Codes。
+package openapi
+
+// Version 生成的文档使用的 OpenAPI 版本
+const Version = "3.0.3"
+
+// Document OpenAPI 文档，只包含本项目用到的字段
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Tags       []*Tag              `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+	ErrorCodes []*ErrorCode        `json:"x-error-codes,omitempty"`
+}
+
+// Info 文档的标题、版本和描述
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag 接口分组
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem 以小写 HTTP 方法为键的接口
+type PathItem map[string]*Operation
+
+// Components 可以通过 $ref 引用的模型
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// ErrorCode 错误码及其对应的 HTTP 状态和消息，以 x-error-codes 扩展字段输出
+type ErrorCode struct {
+	Code       int    `json:"code"`
+	HttpStatus int    `json:"httpStatus"`
+	Message    string `json:"message"`
+	Reference  string `json:"reference,omitempty"`
+}
+
+// Parameter 路径、查询或请求头参数
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+// RequestBody 请求体
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response 响应
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// Header 响应头
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+// MediaType 请求体或响应的一种内容类型
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Schema 模型或字段的结构和约束
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Default              any                `json:"default,omitempty"`
+}
+
+// String 返回字符串类型的 Schema，enum 不为空时只允许其中的值
+func String(enum ...string) *Schema {
+	s := &Schema{Type: "string"}
+	for _, value := range enum {
+		s.Enum = append(s.Enum, value)
+	}
+	return s
+}
+
+// Integer 返回整数类型的 Schema
+func Integer() *Schema {
+	return &Schema{Type: "integer"}
+}
+
+// Boolean 返回布尔类型的 Schema
+func Boolean() *Schema {
+	return &Schema{Type: "boolean"}
+}
+
+// Array 返回元素为 items 的数组类型的 Schema
+func Array(items *Schema) *Schema {
+	return &Schema{Type: "array", Items: items}
+}
+
+// Query 返回查询参数
+func Query(name string, schema *Schema, description string) *Parameter {
+	return &Parameter{Name: name, In: "query", Schema: schema, Description: description}
+}
+
+// RequestHeader 返回请求头参数
+func RequestHeader(name string, required bool, description string) *Parameter {
+	return &Parameter{Name: name, In: "header", Required: required, Schema: String(), Description: description}
+}
@@ -0,0 +1,179 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"orca/pkg/code"
+	"orca/pkg/query"
+	"orca/pkg/validation"
+)
+
+type base struct {
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type item struct {
+	base `json:",inline"`
+
+	ID       uint64            `json:"id"`
+	Name     string            `json:"name"`
+	Kind     string            `json:"kind"`
+	Parent   *string           `json:"parent"`
+	Tags     []string          `json:"tags"`
+	Extra    map[string]string `json:"extra,omitempty"`
+	Children []*item           `json:"children"`
+	Secret   string            `json:"-"`
+	internal string
+}
+
+func (i *item) Validate() error {
+	return validation.ValidateStruct(i,
+		validation.Field(&i.Name, validation.Required, validation.Length(1, 20)),
+		validation.Field(&i.Kind, validation.In("a", "b")),
+		validation.Field(&i.Parent, validation.When(i.Kind == "b", validation.Required)),
+		validation.Field(&i.Tags, validation.Length(0, 5)))
+}
+
+// wrapper 嵌入指针，Validate 来自嵌入的字段
+type wrapper struct {
+	*item `json:",inline"`
+
+	Depth int `json:"depth"`
+}
+
+func TestSchema(t *testing.T) {
+	g := newGenerator()
+	assert.Equal(t, &Schema{Ref: "#/components/schemas/item"}, g.schemaOf(&item{}))
+
+	s := g.schemas["item"]
+	require.NotNil(t, s)
+	assert.Equal(t, []string{"name"}, s.Required)
+	assert.ElementsMatch(t, []string{"createdAt", "id", "name", "kind", "parent", "tags", "extra", "children"}, keys(s.Properties))
+
+	assert.Equal(t, "date-time", s.Properties["createdAt"].Format)
+	assert.Equal(t, 1, *s.Properties["name"].MinLength)
+	assert.Equal(t, 20, *s.Properties["name"].MaxLength)
+	assert.Equal(t, []any{"a", "b"}, s.Properties["kind"].Enum)
+	assert.True(t, s.Properties["parent"].Nullable)
+	assert.NotEmpty(t, s.Properties["parent"].Description)
+	assert.Equal(t, 5, *s.Properties["tags"].MaxItems)
+	assert.Equal(t, "string", s.Properties["extra"].AdditionalProperties.Type)
+	assert.Equal(t, "#/components/schemas/item", s.Properties["children"].Items.Ref)
+}
+
+func TestSchemaPromotedValidate(t *testing.T) {
+	g := newGenerator()
+	g.schemaOf(wrapper{})
+
+	s := g.schemas["wrapper"]
+	require.NotNil(t, s)
+	assert.Contains(t, s.Properties, "depth")
+	assert.Contains(t, s.Properties, "name")
+	assert.Equal(t, []string{"name"}, s.Required)
+}
+
+func TestSchemaGeneric(t *testing.T) {
+	g := newGenerator()
+	g.schemaOf(&query.Result[*item]{})
+
+	s := g.schemas["itemResult"]
+	require.NotNil(t, s)
+	assert.Equal(t, "#/components/schemas/item", s.Properties["items"].Items.Ref)
+}
+
+func TestBuild(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := gin.New()
+	handler := func(*gin.Context) {}
+	server.POST("/item", handler)
+	server.GET("/item", handler)
+	server.GET("/item/:id", handler)
+	server.GET("/hidden", handler)
+
+	spec := New(Info{Title: "test", Version: "1.0.0"})
+	spec.Add(http.MethodPost, "/item", &Operation{
+		Summary: "创建",
+		Request: &item{},
+		Errors:  []code.Code{code.ErrValidate, code.ErrBind, code.ErrConflict},
+	})
+	spec.Add(http.MethodGet, "/item", &Operation{
+		Query: &query.Options{
+			Model:  &item{},
+			Fields: map[string]query.Field{"id": {Operators: query.Range, Sortable: true}},
+		},
+		Response: &query.Result[*item]{},
+	})
+	spec.Add(http.MethodGet, "/hidden", &Operation{Hidden: true})
+
+	doc := spec.Build(server.Routes())
+	assert.Equal(t, Version, doc.OpenAPI)
+	assert.NotContains(t, doc.Paths, "/hidden")
+
+	create := doc.Paths["/item"]["post"]
+	require.NotNil(t, create)
+	assert.Equal(t, []string{"item"}, create.Tags)
+	assert.Equal(t, "#/components/schemas/item", create.RequestBody.Content[jsonType].Schema.Ref)
+	assert.Contains(t, create.Responses["400"].Description, "100005：字段验证错误")
+	assert.Contains(t, create.Responses["400"].Description, "100006：参数绑定错误")
+	assert.Contains(t, create.Responses["409"].Description, "100008：资源存在冲突")
+	assert.Contains(t, create.Responses, "500")
+
+	list := doc.Paths["/item"]["get"]
+	var names []string
+	for _, param := range list.Parameters {
+		names = append(names, param.Name)
+	}
+	assert.Equal(t, []string{"page", "limit", "cursor", "sort", "id[gt]", "id[gte]", "id[lt]", "id[lte]"}, names)
+	assert.Equal(t, "integer", list.Parameters[4].Schema.Type)
+	assert.Equal(t, "#/components/schemas/itemResult", list.Responses["200"].Content[jsonType].Schema.Properties["data"].Ref)
+
+	get := doc.Paths["/item/{id}"]["get"]
+	require.NotNil(t, get)
+	assert.Equal(t, "path", get.Parameters[0].In)
+	assert.Equal(t, "id", get.Parameters[0].Name)
+
+	assert.Contains(t, doc.Components.Schemas, "Error")
+	assert.NotEmpty(t, doc.ErrorCodes)
+	_, err := json.Marshal(doc)
+	assert.NoError(t, err)
+}
+
+func TestConvertPath(t *testing.T) {
+	path, params := convertPath("/menu/:code/revisions/:revision/rollback")
+	assert.Equal(t, "/menu/{code}/revisions/{revision}/rollback", path)
+	assert.Equal(t, []string{"code", "revision"}, params)
+}
+
+func TestOperationID(t *testing.T) {
+	assert.Equal(t, "menu.Create", operationID("orca/controller/menu.(*menuController).Create-fm"))
+	assert.Equal(t, "router.func1", operationID("orca/router.func1"))
+}
+
+func TestUI(t *testing.T) {
+	ui := UI("/openapi.json")
+
+	w := httptest.NewRecorder()
+	ui.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger-initializer.js", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `url: "/openapi.json"`)
+
+	w = httptest.NewRecorder()
+	ui.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "swagger-ui")
+}
+
+func keys(m map[string]*Schema) []string {
+	result := make([]string, 0, len(m))
+	for key := range m {
+		result = append(result, key)
+	}
+	return result
+}
@@ -0,0 +1,56 @@
+package openapi
+
+import (
+	"fmt"
+	"orca/pkg/query"
+	"sort"
+	"strings"
+)
+
+// queryParameters 根据列表接口的查询参数配置生成分页、排序和过滤参数，参数格式见 query 包
+func queryParameters(g *generator, opts *query.Options) []*Parameter {
+	defaultLimit, maxLimit := opts.DefaultLimit, opts.MaxLimit
+	if defaultLimit == 0 {
+		defaultLimit = 10
+	}
+	if maxLimit == 0 {
+		maxLimit = 100
+	}
+	one, max := 1.0, float64(maxLimit)
+
+	names := make([]string, 0, len(opts.Fields))
+	var sortable []string
+	for name, field := range opts.Fields {
+		names = append(names, name)
+		if field.Sortable {
+			sortable = append(sortable, name)
+		}
+	}
+	sort.Strings(names)
+	sort.Strings(sortable)
+
+	params := []*Parameter{
+		Query("page", &Schema{Type: "integer", Minimum: &one, Default: 1}, "页码，不能与 cursor 同时使用"),
+		Query("limit", &Schema{Type: "integer", Minimum: &one, Maximum: &max, Default: defaultLimit}, "每页数量"),
+		Query("cursor", String(), "游标分页，第一页传空值，之后传响应中的 nextCursor"),
+		Query("sort", &Schema{Type: "string", Default: opts.Sort},
+			fmt.Sprintf("排序字段，以逗号分隔，- 前缀表示降序。可排序的字段：%s", strings.Join(sortable, "、"))),
+	}
+	for _, name := range names {
+		field := opts.Fields[name]
+		for _, operator := range field.Operators {
+			switch operator {
+			case query.Eq:
+				// 等值过滤同时支持 name 和 name[eq] 两种写法，文档中只列出 name
+				params = append(params, Query(name, g.propertyOf(opts.Model, name), "等值过滤，等同于 "+name+"[eq]"))
+			case query.In:
+				params = append(params, Query(name+"[in]", String(), "多值过滤，以逗号分隔"))
+			case query.Like:
+				params = append(params, Query(name+"[like]", String(), "模糊过滤"))
+			default:
+				params = append(params, Query(fmt.Sprintf("%s[%s]", name, operator), g.propertyOf(opts.Model, name), "范围过滤"))
+			}
+		}
+	}
+	return params
+}
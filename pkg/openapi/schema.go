@@ -0,0 +1,254 @@
+package openapi
+
+import (
+	"encoding/json"
+	"orca/pkg/validation"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	rawMessageType  = reflect.TypeOf(json.RawMessage{})
+	validatableType = reflect.TypeOf((*validation.Validatable)(nil)).Elem()
+	// typeArgument 泛型类型名称中的类型参数，例如 Result[*orca/models.Menu] 中的 *orca/models.Menu
+	typeArgument = regexp.MustCompile(`[\[,]([^\[\],]+)`)
+)
+
+// generator 根据 Go 类型生成 Schema，具名结构体放在 components 中通过 $ref 引用
+type generator struct {
+	schemas map[string]*Schema
+	// names 已经生成的结构体及其在 components 中的名称
+	names map[reflect.Type]string
+}
+
+func newGenerator() *generator {
+	return &generator{schemas: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+// schemaOf 返回 v 的类型对应的 Schema，v 为 nil 时返回 nil
+func (g *generator) schemaOf(v any) *Schema {
+	if v == nil {
+		return nil
+	}
+	return g.schema(reflect.TypeOf(v))
+}
+
+// schema 返回类型 t 对应的 Schema，指针类型的 Schema 可以为 null
+func (g *generator) schema(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		s := g.schema(t.Elem())
+		if s.Ref != "" {
+			// OpenAPI 3.0 中 $ref 的同级字段会被忽略，引用不标记为可以为 null
+			return s
+		}
+		s.Nullable = true
+		return s
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Boolean()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0.0
+		return &Schema{Type: "integer", Format: "int64", Minimum: &zero}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return String()
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return Array(g.schema(t.Elem()))
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		return g.ref(t)
+	default:
+		// interface 等类型可以是任意值
+		return &Schema{}
+	}
+}
+
+// ref 在 components 中生成结构体 t 的 Schema，返回对它的引用
+func (g *generator) ref(t reflect.Type) *Schema {
+	name, ok := g.names[t]
+	if !ok {
+		name = g.name(t)
+		g.names[t] = name
+		// 先占位再生成字段，字段引用自身（例如菜单树的子节点）时不会无限递归
+		g.schemas[name] = &Schema{}
+		*g.schemas[name] = *g.object(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// name 返回结构体在 components 中的名称，默认使用类型名，与其他包的类型重名时加上包名
+func (g *generator) name(t reflect.Type) string {
+	name := t.Name()
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		// 泛型类型，例如 Result[*orca/models.Menu] 命名为 MenuResult
+		base := name[:i]
+		name = ""
+		for _, match := range typeArgument.FindAllStringSubmatch(t.Name(), -1) {
+			argument := strings.TrimLeft(match[1], "*[]")
+			name += argument[strings.LastIndexByte(argument, '.')+1:]
+		}
+		name += base
+	}
+	if _, taken := g.schemas[name]; taken {
+		pkg := t.PkgPath()
+		pkg = pkg[strings.LastIndexByte(pkg, '/')+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	return name
+}
+
+// object 生成结构体的 Schema，字段名来自 json 标签，嵌入的结构体的字段展开到当前结构体中
+func (g *generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.fields(t, s)
+	applyConstraints(t, s)
+	return s
+}
+
+// applyConstraints 结构体实现了 validation.Validatable 时，根据 Validate 中声明的规则设置 s 中字段的约束
+func applyConstraints(t reflect.Type, s *Schema) {
+	if !reflect.PointerTo(t).Implements(validatableType) {
+		return
+	}
+	for name, constraint := range describe(t) {
+		property, ok := s.Properties[name]
+		if !ok {
+			continue
+		}
+		if constraint.Required && !slices.Contains(s.Required, name) {
+			s.Required = append(s.Required, name)
+		}
+		constrain(property, constraint)
+	}
+	sort.Strings(s.Required)
+}
+
+// describe 返回结构体 t 的 Validate 中声明的规则。Validate 可能来自嵌入的指针字段，
+// 在零值上调用时会 panic，这种情况下返回空，嵌入字段的规则在展开嵌入字段时设置。
+func describe(t reflect.Type) (constraints map[string]validation.Constraint) {
+	defer func() {
+		if recover() != nil {
+			constraints = nil
+		}
+	}()
+	return validation.Describe(reflect.New(t).Interface().(validation.Validatable))
+}
+
+// fields 将结构体 t 的字段添加到 s 的 properties 中
+func (g *generator) fields(t reflect.Type, s *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.fields(embedded, s)
+				applyConstraints(embedded, s)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		s.Properties[name] = g.schema(field.Type)
+	}
+}
+
+// constrain 根据验证规则设置字段的约束，引用其他模型的字段只能设置是否必填
+func constrain(s *Schema, constraint validation.Constraint) {
+	if s.Ref != "" {
+		return
+	}
+	if s.Type == "array" {
+		s.MinItems, s.MaxItems = constraint.MinLength, constraint.MaxLength
+	} else {
+		s.MinLength, s.MaxLength = constraint.MinLength, constraint.MaxLength
+	}
+	s.Enum = append(s.Enum, constraint.Enum...)
+	s.Minimum = number(constraint.Minimum, s.Minimum)
+	s.Maximum = number(constraint.Maximum, s.Maximum)
+	s.ExclusiveMinimum, s.ExclusiveMaximum = constraint.ExclusiveMinimum, constraint.ExclusiveMaximum
+	s.Pattern = constraint.Pattern
+	if constraint.Conditional {
+		s.Description = strings.TrimSpace(s.Description + " 是否必填或允许的取值取决于其他字段。")
+	}
+}
+
+// number 将数值类型的阈值转换为 float64，其他类型（例如时间）返回 fallback
+func number(threshold any, fallback *float64) *float64 {
+	if threshold == nil {
+		return fallback
+	}
+	v := reflect.ValueOf(threshold)
+	var f float64
+	switch {
+	case v.CanInt():
+		f = float64(v.Int())
+	case v.CanUint():
+		f = float64(v.Uint())
+	case v.CanFloat():
+		f = v.Float()
+	default:
+		return fallback
+	}
+	return &f
+}
+
+// propertyOf 返回结构体模型中以 json 字段名为 name 的字段的 Schema，找不到时返回字符串类型
+func (g *generator) propertyOf(model any, name string) *Schema {
+	t := reflect.TypeOf(model)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return String()
+	}
+	s := &Schema{Properties: map[string]*Schema{}}
+	g.fields(t, s)
+	property, ok := s.Properties[name]
+	if !ok {
+		return String()
+	}
+	copied := *property
+	copied.Nullable = false
+	return &copied
+}
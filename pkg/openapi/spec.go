@@ -0,0 +1,257 @@
+package openapi
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"orca/pkg/code"
+	"orca/pkg/query"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const jsonType = "application/json"
+
+// Operation 一个接口。通过 Spec.Add 声明时只需要填写摘要、参数和不输出到文档中的模型和错误码，
+// 请求体和响应由 Spec.Build 根据这些字段生成。
+type Operation struct {
+	Tags        []string             `json:"tags,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	OperationID string               `json:"operationId,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+
+	// Request 请求体的模型，例如 &models.Menu{}
+	Request any `json:"-"`
+	// RequestTypes 请求体的内容类型，为空时为 application/json
+	RequestTypes []string `json:"-"`
+	// Response 成功时响应中 data 的模型，为 nil 时 data 为 null
+	Response any `json:"-"`
+	// ResponseTypes 不为空时响应直接以这些内容类型返回 Response，而不是包装在 response.Success 的结构中
+	ResponseTypes []string `json:"-"`
+	// Query 列表接口的查询参数配置，分页、排序和过滤参数根据它生成
+	Query *query.Options `json:"-"`
+	// Errors 接口可能返回的错误码，按 HTTP 状态分组生成错误响应
+	Errors []code.Code `json:"-"`
+	// Hidden 为 true 时不在文档中输出该接口
+	Hidden bool `json:"-"`
+}
+
+// Spec 收集接口的声明，并根据 gin 的路由生成文档
+type Spec struct {
+	info       Info
+	tags       []*Tag
+	operations map[string]*Operation
+}
+
+// New 创建文档声明
+func New(info Info) *Spec {
+	return &Spec{info: info, operations: map[string]*Operation{}}
+}
+
+// Tag 声明接口分组的描述，分组在文档中按声明的顺序排列
+func (s *Spec) Tag(name, description string) {
+	s.tags = append(s.tags, &Tag{Name: name, Description: description})
+}
+
+// Add 声明路由的请求和响应，method 和 path 与注册 gin 路由时相同，例如 GET /menu/:code
+func (s *Spec) Add(method, path string, op *Operation) {
+	s.operations[method+" "+path] = op
+}
+
+// Build 根据 gin 的路由生成文档。没有通过 Add 声明的路由也会输出，只包含路径参数和通用的响应。
+func (s *Spec) Build(routes gin.RoutesInfo) *Document {
+	g := newGenerator()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    s.info,
+		Tags:    s.tags,
+		Paths:   map[string]PathItem{},
+	}
+
+	for _, route := range routes {
+		declared, ok := s.operations[route.Method+" "+route.Path]
+		if !ok {
+			declared = &Operation{}
+		}
+		if declared.Hidden {
+			continue
+		}
+		path, params := convertPath(route.Path)
+		op := s.build(g, declared, params, route)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = PathItem{}
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = op
+	}
+
+	g.schemas["Error"] = errorSchema()
+	doc.Components.Schemas = g.schemas
+	doc.ErrorCodes = errorCodes()
+	return doc
+}
+
+// build 根据声明生成接口，声明中的字段不会被修改
+func (s *Spec) build(g *generator, declared *Operation, pathParams []string, route gin.RouteInfo) *Operation {
+	op := *declared
+	if op.OperationID == "" {
+		op.OperationID = operationID(route.Handler)
+	}
+	if len(op.Tags) == 0 {
+		op.Tags = []string{strings.SplitN(strings.TrimPrefix(route.Path, "/"), "/", 2)[0]}
+	}
+
+	op.Parameters = nil
+	for _, name := range pathParams {
+		op.Parameters = append(op.Parameters, &Parameter{Name: name, In: "path", Required: true, Schema: String()})
+	}
+	for _, param := range declared.Parameters {
+		if param.In == "path" {
+			// 替换自动生成的路径参数
+			for i, existing := range op.Parameters {
+				if existing.In == "path" && existing.Name == param.Name {
+					op.Parameters[i] = param
+				}
+			}
+			continue
+		}
+		op.Parameters = append(op.Parameters, param)
+	}
+	if op.Query != nil {
+		op.Parameters = append(op.Parameters, queryParameters(g, op.Query)...)
+	}
+
+	if op.Request != nil {
+		op.RequestBody = &RequestBody{Required: true, Content: content(g.schemaOf(op.Request), op.RequestTypes)}
+	}
+
+	op.Responses = map[string]*Response{}
+	if len(op.ResponseTypes) > 0 {
+		op.Responses["200"] = &Response{Description: "请求成功", Content: content(g.schemaOf(op.Response), op.ResponseTypes)}
+	} else {
+		op.Responses["200"] = &Response{Description: "请求成功", Content: content(success(g.schemaOf(op.Response)), nil)}
+	}
+	for status, codes := range groupErrors(op.Errors) {
+		lines := make([]string, 0, len(codes))
+		for _, coder := range codes {
+			lines = append(lines, fmt.Sprintf("- %d：%s", coder.Code(), coder.Message()))
+		}
+		op.Responses[strconv.Itoa(status)] = &Response{
+			Description: http.StatusText(status) + "\n\n" + strings.Join(lines, "\n"),
+			Content:     content(&Schema{Ref: "#/components/schemas/Error"}, nil),
+		}
+	}
+	return &op
+}
+
+// convertPath 将 gin 的路径 /menu/:code 转换为 /menu/{code}，并返回路径参数
+func convertPath(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	var params []string
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			params = append(params, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// operationID 根据处理函数名生成接口ID，例如 orca/controller/menu.(*menuController).Create-fm 生成 menu.Create
+func operationID(handler string) string {
+	handler = strings.TrimSuffix(handler, "-fm")
+	handler = handler[strings.LastIndexByte(handler, '/')+1:]
+	parts := strings.Split(handler, ".")
+	if len(parts) < 2 {
+		return handler
+	}
+	return parts[0] + "." + parts[len(parts)-1]
+}
+
+// content 返回以 types 中每种内容类型为键的内容，types 为空时为 application/json
+func content(schema *Schema, types []string) map[string]*MediaType {
+	if len(types) == 0 {
+		types = []string{jsonType}
+	}
+	result := make(map[string]*MediaType, len(types))
+	for _, t := range types {
+		result[t] = &MediaType{Schema: schema}
+	}
+	return result
+}
+
+// success 返回 response.Success 的响应结构，data 为 nil 时 data 字段为 null
+func success(data *Schema) *Schema {
+	if data == nil {
+		data = &Schema{Nullable: true}
+	}
+	return &Schema{
+		Type:     "object",
+		Required: []string{"code", "data", "message", "status"},
+		Properties: map[string]*Schema{
+			"code":    {Type: "integer", Enum: []any{int(code.Success)}},
+			"data":    data,
+			"message": String(),
+			"status":  {Type: "integer", Enum: []any{http.StatusOK}},
+		},
+	}
+}
+
+// errorSchema 返回 response.Fail 的响应结构
+func errorSchema() *Schema {
+	codes := errorCodes()
+	enum := make([]any, 0, len(codes))
+	for _, coder := range codes {
+		enum = append(enum, coder.Code)
+	}
+	return &Schema{
+		Type:        "object",
+		Description: "请求失败时的响应，错误码的含义见 x-error-codes",
+		Required:    []string{"code", "message", "status"},
+		Properties: map[string]*Schema{
+			"code":      {Type: "integer", Enum: enum},
+			"data":      {Description: "导致失败的详细信息，例如字段验证错误或冲突的资源"},
+			"status":    Integer(),
+			"message":   String(),
+			"reference": String(),
+			"requestId": String(),
+			"traceId":   String(),
+		},
+	}
+}
+
+// groupErrors 将错误码按 HTTP 状态分组，每个接口都可能返回服务器内部错误
+func groupErrors(codes []code.Code) map[int][]code.Coder {
+	groups := map[int][]code.Coder{}
+	seen := map[code.Code]bool{}
+	for _, c := range append(codes, code.ErrInternalServer) {
+		coder, ok := code.Codes[c]
+		if !ok || seen[c] {
+			continue
+		}
+		seen[c] = true
+		groups[coder.HttpStatus()] = append(groups[coder.HttpStatus()], coder)
+	}
+	return groups
+}
+
+// errorCodes 返回所有注册的错误码，按错误码排序
+func errorCodes() []*ErrorCode {
+	codes := make([]*ErrorCode, 0, len(code.Codes))
+	for _, coder := range code.Codes {
+		if coder.Code() == code.Success {
+			continue
+		}
+		codes = append(codes, &ErrorCode{
+			Code:       int(coder.Code()),
+			HttpStatus: coder.HttpStatus(),
+			Message:    coder.Message(),
+			Reference:  coder.Reference(),
+		})
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i].Code < codes[j].Code })
+	return codes
+}
@@ -0,0 +1,60 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+	"net/http"
+	"sync"
+)
+
+// initializer 替换 Swagger UI 默认的 swagger-initializer.js，加载 url 指向的文档
+const initializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: %q,
+    dom_id: '#swagger-ui',
+    deepLinking: true,
+    presets: [
+      SwaggerUIBundle.presets.apis,
+      SwaggerUIStandalonePreset
+    ],
+    plugins: [
+      SwaggerUIBundle.plugins.DownloadUrl
+    ],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+// Handler 返回输出文档的处理函数，文档在第一次请求时根据 routes 返回的路由生成，
+// 因此可以在所有路由注册完成之前注册该处理函数
+func (s *Spec) Handler(routes func() gin.RoutesInfo) gin.HandlerFunc {
+	var once sync.Once
+	var data []byte
+	var err error
+	return func(c *gin.Context) {
+		once.Do(func() {
+			data, err = json.Marshal(s.Build(routes()))
+		})
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+	}
+}
+
+// UI 返回内嵌的 Swagger UI，url 为文档的地址。返回的 Handler 以 / 为根路径，挂载到其他路径下时需要使用 http.StripPrefix。
+func UI(url string) http.Handler {
+	files := http.FileServer(http.FS(swaggerFiles.FS))
+	script := []byte(fmt.Sprintf(initializer, url))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/swagger-initializer.js" {
+			w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+			_, _ = w.Write(script)
+			return
+		}
+		files.ServeHTTP(w, r)
+	})
+}
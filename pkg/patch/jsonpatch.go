@@ -0,0 +1,239 @@
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Operation RFC 6902 JSON Patch 中的一个操作
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JSONPatch 按顺序将 ops 应用到 doc 上，任意一个操作失败时返回错误
+func JSONPatch(doc any, ops []Operation) (any, error) {
+	for i, op := range ops {
+		var err error
+		if doc, err = applyOperation(doc, op); err != nil {
+			return nil, fmt.Errorf("第 %d 个操作（%s %s）失败：%w", i+1, op.Op, op.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+func applyOperation(doc any, op Operation) (any, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("缺少 value")
+		}
+		value, err := decode(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		switch op.Op {
+		case "add":
+			return add(doc, path, value)
+		case "replace":
+			return replace(doc, path, value)
+		default:
+			current, err := get(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, fmt.Errorf("值不相等")
+			}
+			return doc, nil
+		}
+	case "remove":
+		return remove(doc, path)
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			if len(path) > len(from) && reflect.DeepEqual(path[:len(from)], from) {
+				return nil, fmt.Errorf("不能移动到自身的子节点")
+			}
+			if doc, err = remove(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			// 复制的值需要深拷贝，避免与源节点共享 map 或切片
+			data, _ := json.Marshal(value)
+			value, _ = decode(data)
+		}
+		return add(doc, path, value)
+	default:
+		return nil, fmt.Errorf("不支持的操作：%s", op.Op)
+	}
+}
+
+// parsePointer 按照 RFC 6901 解析 JSON Pointer
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("无效的 JSON Pointer：%s", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// update 沿 path 找到目标节点的父容器，用 fn 修改父容器后返回更新后的文档
+func update(node any, path []string, fn func(container any, key string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return fn(node, path[0])
+	}
+	child, err := child(node, path[0])
+	if err != nil {
+		return nil, err
+	}
+	updated, err := update(child, path[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+	return set(node, path[0], updated)
+}
+
+func get(doc any, path []string) (any, error) {
+	for _, token := range path {
+		var err error
+		if doc, err = child(doc, token); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+func add(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return update(doc, path, func(container any, key string) (any, error) {
+		switch c := container.(type) {
+		case map[string]any:
+			c[key] = value
+			return c, nil
+		case []any:
+			if key == "-" {
+				return append(c, value), nil
+			}
+			index, err := arrayIndex(key, len(c)+1)
+			if err != nil {
+				return nil, err
+			}
+			c = append(c, nil)
+			copy(c[index+1:], c[index:])
+			c[index] = value
+			return c, nil
+		default:
+			return nil, fmt.Errorf("路径不存在")
+		}
+	})
+}
+
+func remove(doc any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("不能删除根节点")
+	}
+	return update(doc, path, func(container any, key string) (any, error) {
+		switch c := container.(type) {
+		case map[string]any:
+			if _, ok := c[key]; !ok {
+				return nil, fmt.Errorf("字段 %s 不存在", key)
+			}
+			delete(c, key)
+			return c, nil
+		case []any:
+			index, err := arrayIndex(key, len(c))
+			if err != nil {
+				return nil, err
+			}
+			return append(c[:index], c[index+1:]...), nil
+		default:
+			return nil, fmt.Errorf("路径不存在")
+		}
+	})
+}
+
+func replace(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	if _, err := get(doc, path); err != nil {
+		return nil, err
+	}
+	return update(doc, path, func(container any, key string) (any, error) {
+		return set(container, key, value)
+	})
+}
+
+func child(node any, key string) (any, error) {
+	switch n := node.(type) {
+	case map[string]any:
+		value, ok := n[key]
+		if !ok {
+			return nil, fmt.Errorf("字段 %s 不存在", key)
+		}
+		return value, nil
+	case []any:
+		index, err := arrayIndex(key, len(n))
+		if err != nil {
+			return nil, err
+		}
+		return n[index], nil
+	default:
+		return nil, fmt.Errorf("路径不存在")
+	}
+}
+
+func set(node any, key string, value any) (any, error) {
+	switch n := node.(type) {
+	case map[string]any:
+		n[key] = value
+		return n, nil
+	case []any:
+		index, err := arrayIndex(key, len(n))
+		if err != nil {
+			return nil, err
+		}
+		n[index] = value
+		return n, nil
+	default:
+		return nil, fmt.Errorf("路径不存在")
+	}
+}
+
+// arrayIndex 解析数组下标，下标必须在 [0, size) 之间
+func arrayIndex(key string, size int) (int, error) {
+	if key == "" || (len(key) > 1 && key[0] == '0') {
+		return 0, fmt.Errorf("无效的数组下标：%s", key)
+	}
+	index, err := strconv.Atoi(key)
+	if err != nil || index < 0 || index >= size {
+		return 0, fmt.Errorf("无效的数组下标：%s", key)
+	}
+	return index, nil
+}
@@ -0,0 +1,175 @@
+// Package patch 实现 RFC 7396 JSON Merge Patch 和 RFC 6902 JSON Patch，用于资源的部分更新。
+//
+// 典型用法：
+//
+//	fields, err := patch.Apply(c, &menu)          // 将请求体中的补丁应用到 menu 上
+//	columns, err := patch.Columns(&menu, fields, "menuId")
+//	db.Model(&menu).Select(columns).Updates(&menu) // 只更新发生变化的列
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	// MediaTypeMergePatch RFC 7396 JSON Merge Patch 的媒体类型
+	MediaTypeMergePatch = "application/merge-patch+json"
+	// MediaTypeJSONPatch RFC 6902 JSON Patch 的媒体类型
+	MediaTypeJSONPatch = "application/json-patch+json"
+)
+
+// Apply 读取请求体，根据 Content-Type 将 JSON Patch 或 JSON Merge Patch 应用到 target 上，
+// 返回值发生变化的顶层 JSON 字段名。Content-Type 不是 JSON Patch 时按 Merge Patch 处理。
+func Apply(c *gin.Context, target any) ([]string, error) {
+	body, err := c.GetRawData()
+	if err != nil {
+		return nil, err
+	}
+	if c.ContentType() == MediaTypeJSONPatch {
+		return ApplyJSONPatch(target, body)
+	}
+	return ApplyMergePatch(target, body)
+}
+
+// ApplyMergePatch 将 RFC 7396 JSON Merge Patch 应用到 target 上，返回值发生变化的顶层 JSON 字段名
+func ApplyMergePatch(target any, patch []byte) ([]string, error) {
+	return apply(target, func(doc any) (any, error) {
+		p, err := decode(patch)
+		if err != nil {
+			return nil, err
+		}
+		return MergePatch(doc, p), nil
+	})
+}
+
+// ApplyJSONPatch 将 RFC 6902 JSON Patch 应用到 target 上，返回值发生变化的顶层 JSON 字段名
+func ApplyJSONPatch(target any, patch []byte) ([]string, error) {
+	return apply(target, func(doc any) (any, error) {
+		var ops []Operation
+		if err := json.Unmarshal(patch, &ops); err != nil {
+			return nil, err
+		}
+		return JSONPatch(doc, ops)
+	})
+}
+
+// apply 将 target 序列化为通用的 JSON 值，交给 fn 修改后再反序列化回 target
+func apply(target any, fn func(doc any) (any, error)) ([]string, error) {
+	data, err := json.Marshal(target)
+	if err != nil {
+		return nil, err
+	}
+	before, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	original, _ := decode(data)
+
+	after, err := fn(before)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := after.(map[string]any); !ok {
+		return nil, fmt.Errorf("补丁后的文档必须是 JSON 对象")
+	}
+
+	patched, err := json.Marshal(after)
+	if err != nil {
+		return nil, err
+	}
+	value := reflect.ValueOf(target).Elem()
+	value.Set(reflect.Zero(value.Type()))
+	if err := json.Unmarshal(patched, target); err != nil {
+		return nil, err
+	}
+
+	return changedFields(original.(map[string]any), after.(map[string]any)), nil
+}
+
+// changedFields 返回两个 JSON 对象中值不同的顶层字段
+func changedFields(before, after map[string]any) []string {
+	var fields []string
+	for key, value := range after {
+		if !reflect.DeepEqual(before[key], value) {
+			fields = append(fields, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			fields = append(fields, key)
+		}
+	}
+	return fields
+}
+
+// decode 解析 JSON，数字保留为 json.Number，避免较大的ID丢失精度
+func decode(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// MergePatch 按照 RFC 7396 将 patch 合并到 target 上：对象递归合并，null 表示删除字段，其他值直接替换
+func MergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = make(map[string]any, len(p))
+	}
+	for key, value := range p {
+		if value == nil {
+			delete(t, key)
+			continue
+		}
+		t[key] = MergePatch(t[key], value)
+	}
+	return t
+}
+
+var schemas sync.Map
+
+// Columns 将 JSON 字段名转换为模型的数据库列名，readonly 中的字段和不对应数据库列的字段不允许修改。
+// 列名使用 GORM 默认的命名策略。
+func Columns(model any, fields []string, readonly ...string) ([]string, error) {
+	s, err := schema.Parse(model, &schemas, schema.NamingStrategy{})
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, 0, len(fields))
+	for _, field := range fields {
+		for _, name := range readonly {
+			if name == field {
+				return nil, fmt.Errorf("字段 %s 不允许修改", field)
+			}
+		}
+
+		column := ""
+		for _, f := range s.Fields {
+			if f.DBName != "" && strings.Split(f.Tag.Get("json"), ",")[0] == field {
+				column = f.DBName
+				break
+			}
+		}
+		if column == "" {
+			return nil, fmt.Errorf("字段 %s 不支持部分更新", field)
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
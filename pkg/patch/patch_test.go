@@ -0,0 +1,126 @@
+package patch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustDecode(t *testing.T, s string) any {
+	doc, err := decode([]byte(s))
+	require.NoError(t, err)
+	return doc
+}
+
+func jsonString(t *testing.T, doc any) string {
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+	return string(data)
+}
+
+// TestMergePatchRFC7396 使用 RFC 7396 附录 A 中的示例
+func TestMergePatchRFC7396(t *testing.T) {
+	tests := []struct{ target, patch, result string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		result := MergePatch(mustDecode(t, tt.target), mustDecode(t, tt.patch))
+		assert.JSONEq(t, tt.result, jsonString(t, result), "target=%s patch=%s", tt.target, tt.patch)
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	tests := []struct{ doc, ops, result string }{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":"qux"}]`, `{"foo":["bar","qux"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			`[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"foo":{"bar":1}}`, `[{"op":"copy","from":"/foo","path":"/baz"}]`, `{"foo":{"bar":1},"baz":{"bar":1}}`},
+		{`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{`{"a/b":1,"m~n":2}`, `[{"op":"replace","path":"/a~1b","value":3},{"op":"remove","path":"/m~0n"}]`, `{"a/b":3}`},
+	}
+	for _, tt := range tests {
+		var ops []Operation
+		require.NoError(t, json.Unmarshal([]byte(tt.ops), &ops))
+		result, err := JSONPatch(mustDecode(t, tt.doc), ops)
+		require.NoError(t, err, tt.ops)
+		assert.JSONEq(t, tt.result, jsonString(t, result), tt.ops)
+	}
+}
+
+func TestJSONPatchErrors(t *testing.T) {
+	tests := []struct{ doc, ops string }{
+		{`{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`},
+		{`{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`},
+		{`{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":1}]`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/5","value":1}]`},
+		{`{"foo":["bar"]}`, `[{"op":"remove","path":"/foo/01"}]`},
+		{`{"foo":{"bar":1}}`, `[{"op":"move","from":"/foo","path":"/foo/bar"}]`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz"}]`},
+		{`{"foo":"bar"}`, `[{"op":"unknown","path":"/foo"}]`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"foo","value":1}]`},
+	}
+	for _, tt := range tests {
+		var ops []Operation
+		require.NoError(t, json.Unmarshal([]byte(tt.ops), &ops))
+		_, err := JSONPatch(mustDecode(t, tt.doc), ops)
+		assert.Error(t, err, tt.ops)
+	}
+}
+
+type resource struct {
+	ID      uint64  `json:"id"`
+	Name    string  `json:"name"`
+	Route   *string `json:"route"`
+	Enabled bool    `json:"enabled"`
+	Order   uint    `json:"order"`
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	route := "/home"
+	r := resource{ID: 1 << 60, Name: "home", Route: &route, Enabled: true, Order: 3}
+
+	fields, err := ApplyMergePatch(&r, []byte(`{"enabled":false,"route":null,"name":"home"}`))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"enabled", "route"}, fields)
+	assert.Equal(t, resource{ID: 1 << 60, Name: "home", Enabled: false, Order: 3}, r)
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	r := resource{ID: 1, Name: "home", Enabled: true}
+
+	fields, err := ApplyJSONPatch(&r, []byte(`[{"op":"replace","path":"/order","value":5},{"op":"add","path":"/route","value":"/x"}]`))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"order", "route"}, fields)
+	assert.Equal(t, uint(5), r.Order)
+	assert.Equal(t, "/x", *r.Route)
+}
+
+func TestApplyRejectsNonObject(t *testing.T) {
+	r := resource{ID: 1}
+	_, err := ApplyMergePatch(&r, []byte(`[1]`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,138 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+)
+
+// Result 列表查询的结果，Total 为满足过滤条件的记录总数。
+// 游标分页时，还有下一页则 NextCursor 不为空。
+type Result[T any] struct {
+	Total      int64  `json:"total"`
+	Items      []T    `json:"items"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// Where 将过滤条件应用到 db 上
+func (r *Request) Where(db *gorm.DB) *gorm.DB {
+	if len(r.Filters) == 0 {
+		return db
+	}
+	exprs := make([]clause.Expression, 0, len(r.Filters))
+	for _, filter := range r.Filters {
+		exprs = append(exprs, filter.expression())
+	}
+	return db.Clauses(clause.Where{Exprs: exprs})
+}
+
+func (f Filter) expression() clause.Expression {
+	column := clause.Column{Name: f.Column}
+	switch f.Operator {
+	case In:
+		return clause.IN{Column: column, Values: f.Value.([]any)}
+	case Like:
+		return clause.Like{Column: column, Value: f.Value}
+	case Gt:
+		return clause.Gt{Column: column, Value: f.Value}
+	case Gte:
+		return clause.Gte{Column: column, Value: f.Value}
+	case Lt:
+		return clause.Lt{Column: column, Value: f.Value}
+	case Lte:
+		return clause.Lte{Column: column, Value: f.Value}
+	default:
+		return clause.Eq{Column: column, Value: f.Value}
+	}
+}
+
+// Paginate 将排序和分页应用到 db 上，游标分页时多查询一条记录用于判断是否还有下一页
+func (r *Request) Paginate(db *gorm.DB) *gorm.DB {
+	if len(r.Sorts) > 0 {
+		columns := make([]clause.OrderByColumn, 0, len(r.Sorts))
+		for _, sort := range r.Sorts {
+			columns = append(columns, clause.OrderByColumn{Column: clause.Column{Name: sort.Column}, Desc: sort.Desc})
+		}
+		db = db.Clauses(clause.OrderBy{Columns: columns})
+	}
+	if !r.Cursor {
+		return db.Offset((r.Page - 1) * r.Limit).Limit(r.Limit)
+	}
+	if len(r.After) > 0 {
+		db = db.Clauses(clause.Where{Exprs: []clause.Expression{r.after()}})
+	}
+	return db.Limit(r.Limit + 1)
+}
+
+// after 生成位于游标之后的条件：(a > ?) or (a = ? and b > ?) or ...，降序的列使用 <
+func (r *Request) after() clause.Expression {
+	ors := make([]clause.Expression, 0, len(r.Sorts))
+	for i, sort := range r.Sorts {
+		ands := make([]clause.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, clause.Eq{Column: clause.Column{Name: r.Sorts[j].Column}, Value: r.After[j]})
+		}
+		column := clause.Column{Name: sort.Column}
+		if sort.Desc {
+			ands = append(ands, clause.Lt{Column: column, Value: r.After[i]})
+		} else {
+			ands = append(ands, clause.Gt{Column: column, Value: r.After[i]})
+		}
+		ors = append(ors, clause.And(ands...))
+	}
+	return clause.Or(ors...)
+}
+
+// Find 按照 r 查询 T 的列表，T 通常为模型的指针
+func Find[T any](db *gorm.DB, r *Request) (*Result[T], error) {
+	result := &Result[T]{Items: make([]T, 0)}
+	// db 可能带有调用方的条件，总数和列表分别从新的会话开始，避免两次查询共享并累积同一个 Statement
+	if err := r.Where(db.Session(&gorm.Session{}).Model(&result.Items)).Count(&result.Total).Error; err != nil {
+		return nil, errors.WrapC(err, code.ErrInternalServer, "查询总数时发生错误")
+	}
+	if err := r.Paginate(r.Where(db.Session(&gorm.Session{}).Model(&result.Items))).Find(&result.Items).Error; err != nil {
+		return nil, errors.WrapC(err, code.ErrInternalServer, "查询列表时发生错误")
+	}
+
+	if r.Cursor && len(result.Items) > r.Limit {
+		result.Items = result.Items[:r.Limit]
+		next, err := r.next(db, result.Items[r.Limit-1])
+		if err != nil {
+			return nil, errors.WrapC(err, code.ErrInternalServer, "生成游标时发生错误")
+		}
+		result.NextCursor = next
+	}
+	return result, nil
+}
+
+// next 根据本页最后一条记录的排序字段值生成下一页的游标
+func (r *Request) next(db *gorm.DB, last any) (string, error) {
+	s, err := parseSchema(last)
+	if err != nil {
+		return "", err
+	}
+	value := reflect.Indirect(reflect.ValueOf(last))
+	cur := cursor{Sort: r.sort, Values: make([]json.RawMessage, 0, len(r.Sorts))}
+	for _, sort := range r.Sorts {
+		field := s.LookUpField(sort.Column)
+		if field == nil {
+			return "", errors.Errorf("排序字段 %s 不存在", sort.Column)
+		}
+		v, _ := field.ValueOf(db.Statement.Context, value)
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		cur.Values = append(cur.Values, data)
+	}
+	data, err := json.Marshal(cur)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
@@ -0,0 +1,338 @@
+// Package query 解析列表接口的分页、排序和过滤参数，并将其应用到 GORM 查询上。
+//
+// 排序和过滤只能使用 Options.Fields 中声明的字段，参数格式如下：
+//
+//	?page=2&limit=20                   按页码分页
+//	?cursor=&limit=20                  游标分页的第一页，之后使用响应中的 nextCursor
+//	?sort=-order,code                  按 order 降序、code 升序排序
+//	?type=Menu                         等值过滤，等同于 type[eq]=Menu
+//	?type[in]=Menu,Button              多值过滤
+//	?code[like]=system                 模糊过滤
+//	?createdAt[gte]=2024-01-01T00:00:00Z&createdAt[lt]=2024-02-01T00:00:00Z  范围过滤
+//
+// 典型用法：
+//
+//	req, err := query.Parse(c.Request.URL.Query(), options)
+//	result, err := query.Find[*models.Menu](tx, req)
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/schema"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/validation"
+)
+
+// Operator 过滤操作符
+type Operator string
+
+const (
+	Eq   Operator = "eq"
+	In   Operator = "in"
+	Like Operator = "like"
+	Gt   Operator = "gt"
+	Gte  Operator = "gte"
+	Lt   Operator = "lt"
+	Lte  Operator = "lte"
+)
+
+// Range 范围过滤使用的操作符
+var Range = []Operator{Gt, Gte, Lt, Lte}
+
+const (
+	defaultLimit = 10
+	maxLimit     = 100
+	// maxInValues in 过滤最多允许的值的数量
+	maxInValues = 100
+)
+
+// Field 允许排序或过滤的字段
+type Field struct {
+	// Column 数据库列名
+	Column string
+	// Operators 允许的过滤操作符，为空时不允许过滤
+	Operators []Operator
+	// Sortable 是否允许排序，游标分页要求排序字段不能为 NULL
+	Sortable bool
+}
+
+// Options 列表接口的查询参数配置
+type Options struct {
+	// Model 查询的模型，用于根据字段类型转换过滤值和游标值
+	Model any
+	// Fields 以查询参数名为键的字段白名单
+	Fields map[string]Field
+	// Key 唯一列，总是作为最后一个排序条件，保证排序结果稳定
+	Key string
+	// Sort 未指定 sort 参数时的默认排序，格式与 sort 参数相同
+	Sort string
+	// DefaultLimit 和 MaxLimit 为每页数量的默认值和上限，为 0 时分别使用 10 和 100
+	DefaultLimit int
+	MaxLimit     int
+}
+
+// Filter 一个过滤条件，Value 已经按照字段类型转换，in 过滤时为切片
+type Filter struct {
+	Column   string
+	Operator Operator
+	Value    any
+}
+
+// Sort 一个排序条件
+type Sort struct {
+	Column string
+	Desc   bool
+}
+
+// Request 解析后的列表查询
+type Request struct {
+	Page    int
+	Limit   int
+	Sorts   []Sort
+	Filters []Filter
+	// Cursor 为 true 时使用游标分页，After 为上一页最后一条记录的排序字段值，第一页时为空
+	Cursor bool
+	After  []any
+
+	// sort 规范化后的排序，用于校验游标是否与当前排序一致
+	sort string
+}
+
+// cursor 游标的内容，编码为 base64 后返回给客户端
+type cursor struct {
+	Sort   string            `json:"s"`
+	Values []json.RawMessage `json:"v"`
+}
+
+var schemas sync.Map
+
+func parseSchema(model any) (*schema.Schema, error) {
+	return schema.Parse(model, &schemas, schema.NamingStrategy{})
+}
+
+// Parse 解析查询参数，参数无效时返回的错误以 validation.Errors 为根因，键为参数名
+func Parse(values url.Values, opts *Options) (*Request, error) {
+	s, err := parseSchema(opts.Model)
+	if err != nil {
+		return nil, errors.WrapC(err, code.ErrInternalServer, "解析模型时发生错误")
+	}
+
+	errs := validation.Errors{}
+	req := &Request{Page: 1, Limit: opts.DefaultLimit}
+	if req.Limit == 0 {
+		req.Limit = defaultLimit
+	}
+	max := opts.MaxLimit
+	if max == 0 {
+		max = maxLimit
+	}
+
+	if values.Has("limit") {
+		limit, err := strconv.Atoi(values.Get("limit"))
+		if err != nil || limit < 1 || limit > max {
+			errs["limit"] = validation.NewError("validation_query_limit", "每页数量必须在1到"+strconv.Itoa(max)+"之间")
+		}
+		req.Limit = limit
+	}
+
+	_, req.Cursor = values["cursor"]
+	if values.Has("page") {
+		page, err := strconv.Atoi(values.Get("page"))
+		if err != nil || page < 1 {
+			errs["page"] = validation.NewError("validation_query_page", "页码必须是正整数")
+		} else if req.Cursor {
+			errs["page"] = validation.NewError("validation_query_page_cursor", "不能同时使用页码和游标")
+		}
+		req.Page = page
+	}
+
+	sort := opts.Sort
+	if values.Has("sort") {
+		sort = values.Get("sort")
+	}
+	if err := req.parseSort(sort, opts); err != nil {
+		errs["sort"] = err
+	}
+
+	if raw := values.Get("cursor"); raw != "" && errs["sort"] == nil {
+		if err := req.parseCursor(raw, s); err != nil {
+			errs["cursor"] = err
+		}
+	}
+
+	// 按参数名排序，使生成的 SQL 稳定
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		name, op, ok := parseKey(key)
+		if !ok {
+			continue
+		}
+		if err := req.parseFilter(name, op, values.Get(key), opts, s); err != nil {
+			errs[key] = err
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.WrapC(errs, code.ErrValidate, "查询参数错误")
+	}
+	return req, nil
+}
+
+// parseKey 将 name 或 name[op] 形式的参数名拆分为字段名和操作符，分页和排序参数返回 false
+func parseKey(key string) (string, Operator, bool) {
+	switch key {
+	case "page", "limit", "cursor", "sort":
+		return "", "", false
+	}
+	if i := strings.IndexByte(key, '['); i > 0 && strings.HasSuffix(key, "]") {
+		return key[:i], Operator(key[i+1 : len(key)-1]), true
+	}
+	return key, "", true
+}
+
+func (r *Request) parseSort(sort string, opts *Options) error {
+	seen := make(map[string]bool)
+	var names []string
+	for _, name := range strings.Split(sort, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		desc := strings.HasPrefix(name, "-")
+		field, ok := opts.Fields[strings.TrimPrefix(name, "-")]
+		if !ok || !field.Sortable {
+			return validation.NewError("validation_query_sort", "不支持按"+strings.TrimPrefix(name, "-")+"排序")
+		}
+		if seen[field.Column] {
+			continue
+		}
+		seen[field.Column] = true
+		r.Sorts = append(r.Sorts, Sort{Column: field.Column, Desc: desc})
+		names = append(names, name)
+	}
+	if opts.Key != "" && !seen[opts.Key] {
+		r.Sorts = append(r.Sorts, Sort{Column: opts.Key})
+		names = append(names, opts.Key)
+	}
+	r.sort = strings.Join(names, ",")
+	return nil
+}
+
+func (r *Request) parseCursor(raw string, s *schema.Schema) error {
+	invalid := validation.NewError("validation_query_cursor", "无效的游标")
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return invalid
+	}
+	var cur cursor
+	if err := json.Unmarshal(data, &cur); err != nil || cur.Sort != r.sort || len(cur.Values) != len(r.Sorts) {
+		return invalid
+	}
+	for i, sort := range r.Sorts {
+		field := s.LookUpField(sort.Column)
+		if field == nil {
+			return invalid
+		}
+		value := reflect.New(field.FieldType)
+		if err := json.Unmarshal(cur.Values[i], value.Interface()); err != nil {
+			return invalid
+		}
+		r.After = append(r.After, value.Elem().Interface())
+	}
+	return nil
+}
+
+func (r *Request) parseFilter(name string, op Operator, raw string, opts *Options, s *schema.Schema) error {
+	field, ok := opts.Fields[name]
+	if !ok {
+		// 未声明的参数可能由调用方自行处理，只有显式指定操作符时才视为错误
+		if op != "" {
+			return validation.NewError("validation_query_filter", "不支持按"+name+"过滤")
+		}
+		return nil
+	}
+	if op == "" {
+		op = Eq
+	}
+	allowed := false
+	for _, o := range field.Operators {
+		allowed = allowed || o == op
+	}
+	if !allowed {
+		return validation.NewError("validation_query_operator", "字段"+name+"不支持"+string(op)+"过滤")
+	}
+
+	f := s.LookUpField(field.Column)
+	if f == nil {
+		return validation.NewError("validation_query_filter", "不支持按"+name+"过滤")
+	}
+	filter := Filter{Column: field.Column, Operator: op}
+	switch op {
+	case In:
+		parts := strings.Split(raw, ",")
+		if len(parts) > maxInValues {
+			return validation.NewError("validation_query_in", "过滤值不能超过"+strconv.Itoa(maxInValues)+"个")
+		}
+		values := make([]any, 0, len(parts))
+		for _, part := range parts {
+			value, err := convert(f.FieldType, part)
+			if err != nil {
+				return validation.NewError("validation_query_value", "无效的过滤值："+part)
+			}
+			values = append(values, value)
+		}
+		filter.Value = values
+	case Like:
+		filter.Value = "%" + escapeLike(raw) + "%"
+	default:
+		value, err := convert(f.FieldType, raw)
+		if err != nil {
+			return validation.NewError("validation_query_value", "无效的过滤值："+raw)
+		}
+		filter.Value = value
+	}
+	r.Filters = append(r.Filters, filter)
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// convert 按照字段类型转换过滤值，时间使用 RFC 3339 格式
+func convert(t reflect.Type, raw string) (any, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return time.Parse(time.RFC3339, raw)
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return strconv.ParseBool(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(raw, 10, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(raw, 10, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(raw, t.Bits())
+	default:
+		return raw, nil
+	}
+}
+
+// escapeLike 转义 like 中的通配符，使过滤值按字面匹配
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
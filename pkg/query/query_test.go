@@ -0,0 +1,144 @@
+package query
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/validation"
+)
+
+type item struct {
+	ItemID    uint64 `gorm:"primaryKey"`
+	Code      string
+	Kind      string
+	Enabled   bool
+	Order     uint
+	CreatedAt time.Time
+}
+
+var options = &Options{
+	Model: &item{},
+	Fields: map[string]Field{
+		"code":      {Column: "code", Operators: []Operator{Eq, Like}, Sortable: true},
+		"kind":      {Column: "kind", Operators: []Operator{Eq, In}},
+		"enabled":   {Column: "enabled", Operators: []Operator{Eq}},
+		"order":     {Column: "order", Operators: Range, Sortable: true},
+		"createdAt": {Column: "created_at", Operators: Range, Sortable: true},
+	},
+	Key:      "item_id",
+	Sort:     "order",
+	MaxLimit: 50,
+}
+
+func dryRun(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	return db
+}
+
+func parse(t *testing.T, query string) *Request {
+	values, err := url.ParseQuery(query)
+	require.NoError(t, err)
+	req, err := Parse(values, options)
+	require.NoError(t, err, query)
+	return req
+}
+
+func statement(t *testing.T, req *Request) *gorm.Statement {
+	return req.Paginate(req.Where(dryRun(t).Model(&item{}))).Find(&[]item{}).Statement
+}
+
+func TestParseDefaults(t *testing.T) {
+	req := parse(t, "")
+	assert.Equal(t, 1, req.Page)
+	assert.Equal(t, 10, req.Limit)
+	assert.False(t, req.Cursor)
+	assert.Equal(t, []Sort{{Column: "order"}, {Column: "item_id"}}, req.Sorts)
+
+	stmt := statement(t, req)
+	assert.Equal(t, "SELECT * FROM `items` ORDER BY `order`,`item_id` LIMIT ?", stmt.SQL.String())
+}
+
+func TestParseFilters(t *testing.T) {
+	req := parse(t, "page=3&limit=20&sort=-createdAt,code&kind[in]=a,b&code[like]=50%25_off&enabled=true&order[gte]=2&other=x")
+	stmt := statement(t, req)
+	assert.Equal(t, "SELECT * FROM `items` WHERE `code` LIKE ? AND `enabled` = ? AND `kind` IN (?,?) AND `order` >= ? "+
+		"ORDER BY `created_at` DESC,`code`,`item_id` LIMIT ? OFFSET ?", stmt.SQL.String())
+	assert.Equal(t, []any{`%50\%\_off%`, true, "a", "b", uint64(2), 20, 40}, stmt.Vars)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"limit=0":                  "limit",
+		"limit=51":                 "limit",
+		"page=x":                   "page",
+		"page=2&cursor=":           "page",
+		"sort=kind":                "sort",
+		"sort=unknown":             "sort",
+		"kind[like]=a":             "kind[like]",
+		"unknown[eq]=a":            "unknown[eq]",
+		"enabled=yes":              "enabled",
+		"order[gt]=-1":             "order[gt]",
+		"createdAt[lt]=2024-01-01": "createdAt[lt]",
+		"cursor=bm90LWEtY3Vyc29y":  "cursor",
+		"cursor=not+base64":        "cursor",
+	}
+	for query, key := range tests {
+		values, _ := url.ParseQuery(query)
+		_, err := Parse(values, options)
+		require.Error(t, err, query)
+		assert.True(t, errors.IsCode(err, code.ErrValidate), query)
+		errs, ok := errors.Cause(err).(validation.Errors)
+		require.True(t, ok, query)
+		assert.Contains(t, errs, key, query)
+	}
+}
+
+func TestCursor(t *testing.T) {
+	req := parse(t, "cursor=&limit=2&sort=-createdAt")
+	stmt := statement(t, req)
+	assert.Equal(t, "SELECT * FROM `items` ORDER BY `created_at` DESC,`item_id` LIMIT ?", stmt.SQL.String())
+	assert.Equal(t, []any{3}, stmt.Vars)
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	next, err := req.next(dryRun(t), &item{ItemID: 1 << 60, CreatedAt: createdAt})
+	require.NoError(t, err)
+
+	req = parse(t, "cursor="+next+"&limit=2&sort=-createdAt")
+	assert.Equal(t, []any{createdAt, uint64(1 << 60)}, req.After)
+	stmt = statement(t, req)
+	assert.Equal(t, "SELECT * FROM `items` WHERE (`created_at` < ? OR (`created_at` = ? AND `item_id` > ?)) "+
+		"ORDER BY `created_at` DESC,`item_id` LIMIT ?", stmt.SQL.String())
+
+	// 游标与排序不一致时无效
+	values, _ := url.ParseQuery("cursor=" + next + "&sort=code")
+	_, err = Parse(values, options)
+	assert.True(t, errors.IsCode(err, code.ErrValidate))
+}
+
+func TestFindWithScopedDB(t *testing.T) {
+	db := dryRun(t)
+	var statements []string
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:record", func(tx *gorm.DB) {
+		statements = append(statements, tx.Statement.SQL.String())
+	}))
+
+	_, err := Find[*item](db.Where("kind = ?", "a"), parse(t, "enabled=true"))
+	require.NoError(t, err)
+	// 调用方的条件在两次查询中各出现一次
+	assert.Equal(t, []string{
+		"SELECT count(*) FROM `items` WHERE kind = ? AND `enabled` = ?",
+		"SELECT * FROM `items` WHERE kind = ? AND `enabled` = ? ORDER BY `order`,`item_id` LIMIT ?",
+	}, statements)
+}
@@ -0,0 +1,121 @@
+// Package ratelimit 基于 Redis 的限流，令牌桶和滑动窗口两种算法都以 Lua 脚本实现，
+// 读取、计算和写入在 Redis 中原子地完成，多个实例共享同一份限流状态。
+//
+// 脚本使用 Redis 的 TIME 作为当前时间，不受各实例时钟偏差的影响，需要 Redis 5 及以上版本。
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"regexp"
+	"slices"
+	"time"
+)
+
+const (
+	// AlgorithmTokenBucket 令牌桶：桶的容量为 Limit，每经过 Period 补满，允许短时间内的突发请求
+	AlgorithmTokenBucket = "tokenBucket"
+	// AlgorithmSlidingWindow 滑动窗口：任意 Period 长度的时间窗口内最多 Limit 个请求
+	AlgorithmSlidingWindow = "slidingWindow"
+)
+
+const (
+	// KeyIP 按客户端 IP 限流
+	KeyIP = "ip"
+	// KeyUser 按网关透传的用户ID限流，无法识别用户时按客户端 IP 限流。网关必须删除客户端传入的用户ID请求头。
+	KeyUser = "user"
+	// KeyAPIKey 按请求中的 API Key 限流，没有 API Key 时按客户端 IP 限流
+	KeyAPIKey = "apiKey"
+	// KeyRoute 按路由限流，所有客户端共享同一个配额
+	KeyRoute = "route"
+)
+
+// namePattern 策略名称的格式，名称是 Redis 键的一部分
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Policy 限流策略
+type Policy struct {
+	// Name 策略名称，同一个名称的策略共享限流状态
+	Name string
+	// Algorithm 限流算法，取值为 AlgorithmTokenBucket 或 AlgorithmSlidingWindow
+	Algorithm string
+	// Limit 每个 Period 内允许的请求数量，令牌桶中同时也是桶的容量
+	Limit  int
+	Period time.Duration
+	// Key 限流的维度，取值为 KeyIP、KeyUser、KeyAPIKey 或 KeyRoute
+	Key string
+	// Routes 策略生效的路由，格式为 "方法 路由模板" 或 "路由模板"，例如 "POST /menu/import"，为空时对所有路由生效
+	Routes []string
+}
+
+// Validate 检查策略的配置是否正确
+func (p *Policy) Validate() error {
+	if !namePattern.MatchString(p.Name) {
+		return fmt.Errorf("ratelimit: 策略名称 %q 只能包含字母、数字、_ 和 -", p.Name)
+	}
+	if p.Algorithm != AlgorithmTokenBucket && p.Algorithm != AlgorithmSlidingWindow {
+		return fmt.Errorf("ratelimit: 策略 %s 的算法 %q 不支持", p.Name, p.Algorithm)
+	}
+	if p.Limit < 1 {
+		return fmt.Errorf("ratelimit: 策略 %s 的 limit 必须大于0", p.Name)
+	}
+	if p.Period < time.Millisecond {
+		return fmt.Errorf("ratelimit: 策略 %s 的 period 不能小于1毫秒", p.Name)
+	}
+	if !slices.Contains([]string{KeyIP, KeyUser, KeyAPIKey, KeyRoute}, p.Key) {
+		return fmt.Errorf("ratelimit: 策略 %s 的 key %q 不支持", p.Name, p.Key)
+	}
+	return nil
+}
+
+// Matches 返回策略是否对 method 和路由模板 route 生效
+func (p *Policy) Matches(method, route string) bool {
+	if len(p.Routes) == 0 {
+		return true
+	}
+	return slices.Contains(p.Routes, route) || slices.Contains(p.Routes, method+" "+route)
+}
+
+// Result 一次限流检查的结果
+type Result struct {
+	Allowed bool
+	Limit   int
+	// Remaining 本次请求之后剩余的配额
+	Remaining int
+	// Reset 配额完全恢复需要的时间
+	Reset time.Duration
+	// RetryAfter 请求被拒绝时，距离下一个请求可以通过需要等待的时间
+	RetryAfter time.Duration
+}
+
+// Limiter 在 Redis 中执行限流脚本
+type Limiter struct {
+	client redis.Scripter
+	prefix string
+}
+
+// New 创建 Limiter，prefix 为 Redis 键的前缀，例如 orca:ratelimit:
+func New(client redis.Scripter, prefix string) *Limiter {
+	return &Limiter{client: client, prefix: prefix}
+}
+
+// Allow 消耗 key 在 policy 中的一个配额，返回请求是否被允许
+func (l *Limiter) Allow(ctx context.Context, policy *Policy, key string) (*Result, error) {
+	script := slidingWindow
+	if policy.Algorithm == AlgorithmTokenBucket {
+		script = tokenBucket
+	}
+	values, err := script.Run(ctx, l.client, []string{l.prefix + policy.Name + ":" + key},
+		policy.Limit, policy.Period.Milliseconds()).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("ratelimit: 执行策略 %s 失败: %w", policy.Name, err)
+	}
+	return &Result{
+		Allowed:    values[0] == 1,
+		Limit:      policy.Limit,
+		Remaining:  int(values[1]),
+		Reset:      time.Duration(values[2]) * time.Millisecond,
+		RetryAfter: time.Duration(values[3]) * time.Millisecond,
+	}, nil
+}
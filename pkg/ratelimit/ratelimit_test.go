@@ -0,0 +1,111 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLimiter 创建使用 miniredis 的 Limiter，返回的 miniredis 用于控制脚本读取的当前时间
+func newLimiter(t *testing.T) (*Limiter, *miniredis.Miniredis) {
+	m := miniredis.RunT(t)
+	m.SetTime(time.Unix(1700000000, 0))
+	client := redis.NewClient(&redis.Options{Addr: m.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return New(client, "test:"), m
+}
+
+func TestTokenBucket(t *testing.T) {
+	limiter, m := newLimiter(t)
+	policy := &Policy{Name: "bucket", Algorithm: AlgorithmTokenBucket, Limit: 3, Period: 3 * time.Second, Key: KeyIP}
+	ctx := context.Background()
+
+	for i := 2; i >= 0; i-- {
+		result, err := limiter.Allow(ctx, policy, "ip:127.0.0.1")
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, i, result.Remaining)
+	}
+	result, err := limiter.Allow(ctx, policy, "ip:127.0.0.1")
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 3*time.Second, result.Reset)
+
+	// 其他键不受影响
+	result, err = limiter.Allow(ctx, policy, "ip:127.0.0.2")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	// 每秒补充一个令牌
+	m.SetTime(time.Unix(1700000001, 0))
+	result, err = limiter.Allow(ctx, policy, "ip:127.0.0.1")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+}
+
+func TestSlidingWindow(t *testing.T) {
+	limiter, m := newLimiter(t)
+	policy := &Policy{Name: "window", Algorithm: AlgorithmSlidingWindow, Limit: 2, Period: 10 * time.Second, Key: KeyUser}
+	ctx := context.Background()
+
+	result, err := limiter.Allow(ctx, policy, "user:1")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+
+	m.SetTime(time.Unix(1700000004, 0))
+	result, err = limiter.Allow(ctx, policy, "user:1")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, 10*time.Second, result.Reset)
+
+	result, err = limiter.Allow(ctx, policy, "user:1")
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 6*time.Second, result.RetryAfter)
+
+	// 第一个请求移出窗口后释放一个配额
+	m.SetTime(time.Unix(1700000010, 0))
+	result, err = limiter.Allow(ctx, policy, "user:1")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+}
+
+func TestAllowError(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	defer client.Close()
+	policy := &Policy{Name: "window", Algorithm: AlgorithmSlidingWindow, Limit: 1, Period: time.Second, Key: KeyIP}
+	_, err := New(client, "test:").Allow(context.Background(), policy, "ip:127.0.0.1")
+	assert.ErrorContains(t, err, "执行策略 window 失败")
+}
+
+func TestPolicy(t *testing.T) {
+	policy := &Policy{Name: "menu-import", Algorithm: AlgorithmTokenBucket, Limit: 5, Period: time.Minute, Key: KeyAPIKey,
+		Routes: []string{"POST /menu/import", "/menu/export"}}
+	assert.NoError(t, policy.Validate())
+	assert.True(t, policy.Matches("POST", "/menu/import"))
+	assert.False(t, policy.Matches("GET", "/menu/import"))
+	assert.True(t, policy.Matches("GET", "/menu/export"))
+	assert.True(t, (&Policy{}).Matches("GET", "/menu"))
+
+	invalid := []*Policy{
+		{Name: "a:b", Algorithm: AlgorithmTokenBucket, Limit: 1, Period: time.Second, Key: KeyIP},
+		{Name: "a", Algorithm: "fixedWindow", Limit: 1, Period: time.Second, Key: KeyIP},
+		{Name: "a", Algorithm: AlgorithmTokenBucket, Limit: 0, Period: time.Second, Key: KeyIP},
+		{Name: "a", Algorithm: AlgorithmTokenBucket, Limit: 1, Period: 0, Key: KeyIP},
+		{Name: "a", Algorithm: AlgorithmTokenBucket, Limit: 1, Period: time.Second, Key: "session"},
+	}
+	for _, p := range invalid {
+		assert.Error(t, p.Validate())
+	}
+}
@@ -0,0 +1,61 @@
+package ratelimit
+
+import "github.com/go-redis/redis/v8"
+
+// 两个脚本的参数相同：KEYS[1] 为限流的键，ARGV[1] 为 Limit，ARGV[2] 为以毫秒表示的 Period。
+// 返回值依次为是否允许（1 或 0）、剩余配额、配额完全恢复的毫秒数、被拒绝时需要等待的毫秒数。
+
+// tokenBucket 令牌桶，哈希中保存剩余的令牌数和上次补充令牌的时间
+var tokenBucket = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local rate = limit / period
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or limit
+local ts = tonumber(state[2]) or now
+tokens = math.min(limit, tokens + math.max(0, now - ts) * rate)
+
+local allowed, retry = 0, 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  retry = math.ceil((1 - tokens) / rate)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], period)
+return {allowed, math.floor(tokens), math.ceil((limit - tokens) / rate), retry}
+`)
+
+// slidingWindow 滑动窗口，有序集合中保存窗口内每个请求的时间
+var slidingWindow = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+
+local allowed = 0
+if count < limit then
+  -- 同一毫秒内可能有多个请求，成员中加入微秒和当前数量保证唯一
+  redis.call('ZADD', KEYS[1], now, time[1] .. '.' .. time[2] .. '.' .. count)
+  count = count + 1
+  allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], window)
+
+-- 最早的请求移出窗口时释放一个配额，最新的请求移出窗口时配额完全恢复
+local retry = 0
+if allowed == 0 then
+  local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+  retry = tonumber(oldest[2]) + window - now
+end
+local newest = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+return {allowed, math.max(0, limit - count), tonumber(newest[2]) + window - now, retry}
+`)
@@ -0,0 +1,173 @@
+// Package repository 提供基于 GORM 的通用仓储，仓储在 context 携带的事务中执行，见 uow 包。
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"orca/pkg/query"
+	"orca/pkg/softdelete"
+	"orca/pkg/uow"
+)
+
+// ErrNotFound Get 查询不到记录时返回的错误
+var ErrNotFound = gorm.ErrRecordNotFound
+
+// Scope 查询条件，与 gorm.DB.Scopes 的参数相同
+type Scope = func(db *gorm.DB) *gorm.DB
+
+// Interface 仓储支持的操作。控制器依赖该接口而不是具体的实现，测试时可以替换为假的实现。
+// 仓储不暴露数据库连接，接口覆盖不了的查询（例如关联表）应当声明为单独的仓储接口。
+//
+// 嵌入 softdelete.DeletedAt 的模型默认只操作未删除的记录，使用 Unscoped 包括已删除的记录。
+type Interface[T any] interface {
+	// Get 查询满足条件的第一条记录，不存在时返回 ErrNotFound
+	Get(ctx context.Context, scopes ...Scope) (*T, error)
+	// List 查询满足条件的所有记录
+	List(ctx context.Context, scopes ...Scope) ([]*T, error)
+	// Page 按照列表查询参数分页查询满足条件的记录
+	Page(ctx context.Context, req *query.Request, scopes ...Scope) (*query.Result[*T], error)
+	// Exists 判断是否存在满足条件的记录
+	Exists(ctx context.Context, scopes ...Scope) (bool, error)
+
+	// Create 插入记录，插入后主键会被回填
+	Create(ctx context.Context, entities ...*T) error
+	// Update 将 entity 的 columns 列写入满足条件的记录，没有条件时以 entity 的主键为条件。
+	// columns 为空时写入除创建时间和删除时间以外的所有列，包括零值。返回受影响的记录数。
+	Update(ctx context.Context, entity *T, columns []string, scopes ...Scope) (int64, error)
+	// UpdateColumns 将 values 写入满足条件的记录，返回受影响的记录数
+	UpdateColumns(ctx context.Context, values map[string]any, scopes ...Scope) (int64, error)
+	// Delete 删除满足条件的记录，支持软删除的模型只记录删除时间。返回删除的记录数。
+	Delete(ctx context.Context, scopes ...Scope) (int64, error)
+	// Restore 恢复满足条件的已删除记录，返回恢复的记录数
+	Restore(ctx context.Context, scopes ...Scope) (int64, error)
+}
+
+// Repository 模型 T 的仓储
+type Repository[T any] struct {
+	db *gorm.DB
+}
+
+var _ Interface[struct{}] = (*Repository[struct{}])(nil)
+
+// New 创建使用数据库连接 db 的仓储
+func New[T any](db *gorm.DB) *Repository[T] {
+	return &Repository[T]{db: db}
+}
+
+// conn 返回绑定了 ctx 的连接，ctx 在事务中时返回该事务
+func (r *Repository[T]) conn(ctx context.Context) *gorm.DB {
+	return uow.Conn(ctx, r.db)
+}
+
+func (r *Repository[T]) model(ctx context.Context, scopes []Scope) *gorm.DB {
+	return r.conn(ctx).Model(new(T)).Scopes(scopes...)
+}
+
+func (r *Repository[T]) Get(ctx context.Context, scopes ...Scope) (*T, error) {
+	var entity T
+	result := r.model(ctx, scopes).Limit(1).Find(&entity)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrNotFound
+	}
+	return &entity, nil
+}
+
+func (r *Repository[T]) List(ctx context.Context, scopes ...Scope) ([]*T, error) {
+	entities := make([]*T, 0)
+	if err := r.model(ctx, scopes).Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (r *Repository[T]) Page(ctx context.Context, req *query.Request, scopes ...Scope) (*query.Result[*T], error) {
+	return query.Find[*T](r.model(ctx, scopes), req)
+}
+
+func (r *Repository[T]) Exists(ctx context.Context, scopes ...Scope) (bool, error) {
+	var found []map[string]any
+	result := r.model(ctx, scopes).Select("1").Limit(1).Find(&found)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *Repository[T]) Create(ctx context.Context, entities ...*T) error {
+	if len(entities) == 0 {
+		return nil
+	}
+	return r.conn(ctx).Create(entities).Error
+}
+
+func (r *Repository[T]) Update(ctx context.Context, entity *T, columns []string, scopes ...Scope) (int64, error) {
+	db := r.conn(ctx).Model(entity).Scopes(scopes...)
+	if len(columns) == 0 {
+		db = db.Select("*").Omit("created_at", "deleted_at")
+	} else {
+		db = db.Select(columns)
+	}
+	result := db.Updates(entity)
+	return result.RowsAffected, result.Error
+}
+
+func (r *Repository[T]) UpdateColumns(ctx context.Context, values map[string]any, scopes ...Scope) (int64, error) {
+	result := r.model(ctx, scopes).Updates(values)
+	return result.RowsAffected, result.Error
+}
+
+func (r *Repository[T]) Delete(ctx context.Context, scopes ...Scope) (int64, error) {
+	result := r.conn(ctx).Scopes(scopes...).Delete(new(T))
+	return result.RowsAffected, result.Error
+}
+
+func (r *Repository[T]) Restore(ctx context.Context, scopes ...Scope) (int64, error) {
+	db := r.conn(ctx)
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return 0, err
+	}
+	if stmt.Schema.LookUpField("deleted_at") == nil {
+		return 0, fmt.Errorf("模型 %s 不支持软删除", stmt.Schema.Name)
+	}
+	result := softdelete.Trashed(db).Model(new(T)).Scopes(scopes...).Update("deleted_at", 0)
+	return result.RowsAffected, result.Error
+}
+
+// Where 查询条件，参数与 gorm.DB.Where 相同
+func Where(query any, args ...any) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(query, args...)
+	}
+}
+
+// Unscoped 包括已被软删除的记录
+func Unscoped() Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}
+}
+
+// ForUpdate 对查询到的记录加排他锁，需要在事务中使用
+func ForUpdate() Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+}
+
+// Select 只查询 columns 列
+func Select(columns ...string) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Select(columns)
+	}
+}
+
+// Order 排序，参数与 gorm.DB.Order 相同
+func Order(value any) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Order(value)
+	}
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"orca/pkg/softdelete"
+)
+
+type item struct {
+	ItemID    uint64 `gorm:"primaryKey"`
+	Code      string
+	Version   uint
+	CreatedAt time.Time
+	DeletedAt softdelete.DeletedAt
+}
+
+type plain struct {
+	PlainID uint64 `gorm:"primaryKey"`
+	Code    string
+}
+
+func dryRun(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		NowFunc:                func() time.Time { return time.UnixMilli(1700000000000) },
+	})
+	require.NoError(t, err)
+	return db
+}
+
+// recorder 记录仓储执行的最后一条 SQL
+func recorder(t *testing.T) (*Repository[item], *string) {
+	db := dryRun(t)
+	var sql string
+	record := func(db *gorm.DB) { sql = db.Statement.SQL.String() }
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:sql", record))
+	require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:sql", record))
+	require.NoError(t, db.Callback().Delete().After("gorm:delete").Register("test:sql", record))
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:sql", record))
+	return New[item](db), &sql
+}
+
+func TestGet(t *testing.T) {
+	repo, sql := recorder(t)
+	_, err := repo.Get(context.Background(), Where("code = ?", "a"))
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, "SELECT * FROM `items` WHERE code = ? AND `items`.`deleted_at` = ? LIMIT ?", *sql)
+
+	_, _ = repo.Get(context.Background(), Unscoped(), Where("code = ?", "a"), ForUpdate())
+	assert.Equal(t, "SELECT * FROM `items` WHERE code = ? LIMIT ? FOR UPDATE", *sql)
+}
+
+func TestExists(t *testing.T) {
+	repo, sql := recorder(t)
+	ok, err := repo.Exists(context.Background(), Where("code = ?", "a"))
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "SELECT 1 FROM `items` WHERE code = ? AND `items`.`deleted_at` = ? LIMIT ?", *sql)
+}
+
+func TestUpdate(t *testing.T) {
+	repo, sql := recorder(t)
+	entity := &item{ItemID: 1, Code: "a", Version: 2}
+	_, err := repo.Update(context.Background(), entity, nil, Where("version = ?", 1))
+	require.NoError(t, err)
+	assert.Equal(t, "UPDATE `items` SET `code`=?,`version`=? "+
+		"WHERE version = ? AND `items`.`deleted_at` = ? AND `item_id` = ?", *sql)
+
+	_, err = repo.Update(context.Background(), entity, []string{"code"})
+	require.NoError(t, err)
+	assert.Equal(t, "UPDATE `items` SET `code`=? WHERE `items`.`deleted_at` = ? AND `item_id` = ?", *sql)
+}
+
+func TestDeleteAndRestore(t *testing.T) {
+	repo, sql := recorder(t)
+	_, err := repo.Delete(context.Background(), Where("item_id in ?", []uint64{1, 2}))
+	require.NoError(t, err)
+	assert.Equal(t, "UPDATE `items` SET `deleted_at`=? WHERE item_id in (?,?) AND `items`.`deleted_at` = ?", *sql)
+
+	_, err = repo.Restore(context.Background(), Where("item_id = ?", 1))
+	require.NoError(t, err)
+	assert.Equal(t, "UPDATE `items` SET `deleted_at`=? WHERE deleted_at <> ? AND item_id = ?", *sql)
+
+	_, err = New[plain](dryRun(t)).Restore(context.Background(), Where("plain_id = ?", 1))
+	assert.Error(t, err)
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"orca/pkg/code"
 	"orca/pkg/errors"
+	"orca/pkg/tracing"
 )
 
 // Success 表示本次请求成功，并返回请求信息和HTTP状态
@@ -29,13 +30,24 @@ func Successf(c *gin.Context, data any, message string, args ...any) {
 
 // Fail 表示本次请求失败，并返回请求失败的错误码和错误信息
 func Fail(c *gin.Context, err error) {
+	FailWithData(c, err, nil)
+}
+
+// FailWithData 与 Fail 相同，同时在 data 中返回导致失败的详细信息，例如字段验证错误或冲突的资源
+func FailWithData(c *gin.Context, err error, data any) {
 	fmt.Printf("%+v\n", err)
+	// 记录到 gin 的错误列表中，日志和指标中间件据此统计业务错误
+	_ = c.Error(err)
 	coder := errors.ParseCoder(err)
+	// 返回请求ID和追踪ID，客户端反馈问题时可以据此找到对应的日志和追踪
+	ctx := c.Request.Context()
 	c.JSON(coder.HttpStatus(), gin.H{
 		"code":      coder.Code(),
-		"data":      nil,
+		"data":      data,
 		"status":    coder.HttpStatus(),
 		"message":   coder.Message(),
 		"reference": coder.Reference(),
+		"requestId": tracing.RequestID(ctx),
+		"traceId":   tracing.TraceID(ctx),
 	})
 }
@@ -0,0 +1,132 @@
+// Package route 在注册 gin 路由的同时记录每个路由的权限编码和描述。
+//
+// 权限编码的格式为 模块:操作，例如 menu:create，模块为第一个冒号之前的部分。
+// 不需要权限的路由（例如健康检查）使用空的权限编码。
+// 注册路由时同时声明接口文档，为 nil 时文档中只包含路径参数和通用的响应。
+//
+//	r := route.New(server)
+//	menus := r.Group("/menu")
+//	menus.POST("", "menu:create", "创建菜单", &openapi.Operation{Request: &models.Menu{}}, menuController.Create)
+package route
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"orca/pkg/openapi"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// permissionPattern 权限编码的格式，模块和操作由小写字母、数字和 - 组成
+var permissionPattern = regexp.MustCompile(`^[a-z][a-z0-9-]*(:[a-z][a-z0-9-]*)+$`)
+
+// ctxPermissionKey 在 gin.Context 中保存当前路由权限编码的键
+const ctxPermissionKey = "orca.permission"
+
+// Route 注册的路由
+type Route struct {
+	Method string
+	// Path 完整的路由路径，例如 /menu/:code
+	Path string
+	// Permission 访问路由需要的权限编码，为空表示不需要权限
+	Permission string
+	// Description 路由的描述，同步权限时作为按钮的名称
+	Description string
+	// Operation 路由的接口文档，没有声明摘要时使用 Description
+	Operation *openapi.Operation
+}
+
+// Module 返回权限编码所属的模块，没有权限编码时返回空字符串
+func (r *Route) Module() string {
+	module, _, _ := strings.Cut(r.Permission, ":")
+	return module
+}
+
+// Router 包装 gin 的路由分组，通过它注册的路由都会被记录。同一个 New 创建的所有分组共享记录。
+type Router struct {
+	group  *gin.RouterGroup
+	routes *[]*Route
+}
+
+// New 创建以 engine 为根的 Router
+func New(engine *gin.Engine) *Router {
+	return &Router{group: &engine.RouterGroup, routes: &[]*Route{}}
+}
+
+// Group 创建路由分组，handlers 为分组的中间件
+func (r *Router) Group(relativePath string, handlers ...gin.HandlerFunc) *Router {
+	return &Router{group: r.group.Group(relativePath, handlers...), routes: r.routes}
+}
+
+// Handle 注册路由并记录它的权限编码、描述和接口文档，权限编码格式不正确或同一路由重复注册时 panic
+func (r *Router) Handle(method, relativePath, permission, description string, op *openapi.Operation,
+	handlers ...gin.HandlerFunc) {
+	if permission != "" && !permissionPattern.MatchString(permission) {
+		panic(fmt.Sprintf("route: %s %s 的权限编码 %q 格式不正确，应为 模块:操作", method, relativePath, permission))
+	}
+	if permission != "" {
+		// 保存当前路由的权限编码，供鉴权等中间件读取
+		handlers = append([]gin.HandlerFunc{func(c *gin.Context) { c.Set(ctxPermissionKey, permission) }}, handlers...)
+	}
+	r.group.Handle(method, relativePath, handlers...)
+
+	// 复制声明，多个路由可以共用同一个声明
+	operation := &openapi.Operation{}
+	if op != nil {
+		*operation = *op
+	}
+	if operation.Summary == "" {
+		operation.Summary = description
+	}
+	*r.routes = append(*r.routes, &Route{
+		Method:      method,
+		Path:        joinPath(r.group.BasePath(), relativePath),
+		Permission:  permission,
+		Description: description,
+		Operation:   operation,
+	})
+}
+
+func (r *Router) GET(relativePath, permission, description string, op *openapi.Operation, handlers ...gin.HandlerFunc) {
+	r.Handle(http.MethodGet, relativePath, permission, description, op, handlers...)
+}
+
+func (r *Router) POST(relativePath, permission, description string, op *openapi.Operation, handlers ...gin.HandlerFunc) {
+	r.Handle(http.MethodPost, relativePath, permission, description, op, handlers...)
+}
+
+func (r *Router) PUT(relativePath, permission, description string, op *openapi.Operation, handlers ...gin.HandlerFunc) {
+	r.Handle(http.MethodPut, relativePath, permission, description, op, handlers...)
+}
+
+func (r *Router) PATCH(relativePath, permission, description string, op *openapi.Operation, handlers ...gin.HandlerFunc) {
+	r.Handle(http.MethodPatch, relativePath, permission, description, op, handlers...)
+}
+
+func (r *Router) DELETE(relativePath, permission, description string, op *openapi.Operation, handlers ...gin.HandlerFunc) {
+	r.Handle(http.MethodDelete, relativePath, permission, description, op, handlers...)
+}
+
+// Routes 返回所有已注册的路由，按注册顺序排列
+func (r *Router) Routes() []*Route {
+	return append([]*Route(nil), *r.routes...)
+}
+
+// Permission 返回当前请求的路由声明的权限编码，路由不需要权限时返回空字符串
+func Permission(c *gin.Context) string {
+	return c.GetString(ctxPermissionKey)
+}
+
+// joinPath 与 gin 拼接分组路径的方式相同，保留相对路径末尾的 /
+func joinPath(base, relativePath string) string {
+	if relativePath == "" {
+		return base
+	}
+	joined := path.Join(base, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(joined, "/") {
+		return joined + "/"
+	}
+	return joined
+}
@@ -0,0 +1,59 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"orca/pkg/openapi"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestRoutes(t *testing.T) {
+	r := New(gin.New())
+	r.GET("/healthz", "", "存活检查", nil, func(c *gin.Context) {})
+	menus := r.Group("/menu")
+	menus.POST("", "menu:create", "创建菜单", &openapi.Operation{Request: &struct{}{}}, func(c *gin.Context) {})
+	menus.GET("/:code", "menu:read", "查询菜单", nil, func(c *gin.Context) {})
+	menus.Group("/tree/").PATCH("", "menu:move", "移动菜单", nil, func(c *gin.Context) {})
+
+	routes := r.Routes()
+	require.Len(t, routes, 4)
+	assert.Equal(t, &Route{Method: http.MethodGet, Path: "/healthz", Description: "存活检查",
+		Operation: &openapi.Operation{Summary: "存活检查"}}, routes[0])
+	assert.Equal(t, &Route{Method: http.MethodPost, Path: "/menu", Permission: "menu:create", Description: "创建菜单",
+		Operation: &openapi.Operation{Summary: "创建菜单", Request: &struct{}{}}}, routes[1])
+	assert.Equal(t, "/menu/:code", routes[2].Path)
+	assert.Equal(t, "/menu/tree/", routes[3].Path)
+	assert.Equal(t, "menu", routes[1].Module())
+	assert.Equal(t, "", routes[0].Module())
+}
+
+func TestPermission(t *testing.T) {
+	engine := gin.New()
+	r := New(engine)
+	var permission string
+	r.DELETE("/trash/:resource", "trash:purge", "清理回收站", nil, func(c *gin.Context) { permission = Permission(c) })
+	r.GET("/healthz", "", "存活检查", nil, func(c *gin.Context) { permission = Permission(c) })
+
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/trash/menu", nil))
+	assert.Equal(t, "trash:purge", permission)
+
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, "", permission)
+}
+
+func TestInvalidPermission(t *testing.T) {
+	r := New(gin.New())
+	for _, permission := range []string{"menu", "Menu:create", "menu:", ":create", "menu create"} {
+		assert.Panics(t, func() { r.POST("/menu", permission, "创建菜单", nil, func(c *gin.Context) {}) }, permission)
+	}
+	assert.Empty(t, r.Routes())
+	assert.NotPanics(t, func() { r.POST("/menu", "system-menu:batch-create", "创建菜单", nil, func(c *gin.Context) {}) })
+}
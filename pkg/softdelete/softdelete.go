@@ -0,0 +1,134 @@
+// Package softdelete 为 GORM 提供基于 bigint 时间戳的软删除支持。
+//
+// 数据表使用 deleted_at bigint default 0 记录删除时间：0 表示未删除，非 0 表示删除时刻的 Unix 毫秒时间戳。
+// 模型字段声明为 softdelete.DeletedAt 后：
+//
+//	db.Delete(&menu)                 // UPDATE menu SET deleted_at = <当前毫秒> WHERE ... AND deleted_at = 0
+//	db.Find(&menus)                  // SELECT * FROM menu WHERE deleted_at = 0
+//	db.Unscoped().Find(&menus)       // 包括已删除的记录
+//	db.Unscoped().Delete(&menu)      // 物理删除
+//
+// 唯一索引需要包含 deleted_at 列，以便同一编码可以在删除后重新创建。
+package softdelete
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// DeletedAt 删除时间，单位为毫秒，0 表示未删除
+type DeletedAt uint64
+
+// Deleted 报告记录是否已被软删除
+func (d DeletedAt) Deleted() bool {
+	return d != 0
+}
+
+func (DeletedAt) QueryClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{queryClause{Field: f}}
+}
+
+func (DeletedAt) UpdateClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{updateClause{Field: f}}
+}
+
+func (DeletedAt) DeleteClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{deleteClause{Field: f}}
+}
+
+// queryClause 为查询语句追加 deleted_at = 0 的条件
+type queryClause struct {
+	Field *schema.Field
+}
+
+func (qc queryClause) Name() string { return "" }
+
+func (qc queryClause) Build(clause.Builder) {}
+
+func (qc queryClause) MergeClause(*clause.Clause) {}
+
+func (qc queryClause) ModifyStatement(stmt *gorm.Statement) {
+	if _, ok := stmt.Clauses["soft_delete_enabled"]; ok || stmt.Statement.Unscoped {
+		return
+	}
+
+	// 只有一个 OR 条件时，GORM 不会为其加括号，需要先用 AND 包裹，避免与追加的条件发生优先级错误
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok && len(where.Exprs) >= 1 {
+			for _, expr := range where.Exprs {
+				if orCond, ok := expr.(clause.OrConditions); ok && len(orCond.Exprs) == 1 {
+					where.Exprs = []clause.Expression{clause.And(where.Exprs...)}
+					c.Expression = where
+					stmt.Clauses["WHERE"] = c
+					break
+				}
+			}
+		}
+	}
+
+	stmt.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: qc.Field.DBName}, Value: 0},
+	}})
+	stmt.Clauses["soft_delete_enabled"] = clause.Clause{}
+}
+
+// updateClause 使更新语句只作用于未删除的记录
+type updateClause struct {
+	Field *schema.Field
+}
+
+func (uc updateClause) Name() string { return "" }
+
+func (uc updateClause) Build(clause.Builder) {}
+
+func (uc updateClause) MergeClause(*clause.Clause) {}
+
+func (uc updateClause) ModifyStatement(stmt *gorm.Statement) {
+	if stmt.SQL.Len() == 0 && !stmt.Statement.Unscoped {
+		queryClause(uc).ModifyStatement(stmt)
+	}
+}
+
+// deleteClause 将删除语句改写为设置 deleted_at 的更新语句
+type deleteClause struct {
+	Field *schema.Field
+}
+
+func (dc deleteClause) Name() string { return "" }
+
+func (dc deleteClause) Build(clause.Builder) {}
+
+func (dc deleteClause) MergeClause(*clause.Clause) {}
+
+func (dc deleteClause) ModifyStatement(stmt *gorm.Statement) {
+	if stmt.SQL.Len() != 0 || stmt.Statement.Unscoped {
+		return
+	}
+
+	deletedAt := DeletedAt(stmt.DB.NowFunc().UnixMilli())
+	stmt.AddClause(clause.Set{{Column: clause.Column{Name: dc.Field.DBName}, Value: deletedAt}})
+	stmt.SetColumn(dc.Field.DBName, deletedAt, true)
+
+	if stmt.Schema != nil {
+		_, queryValues := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
+		column, values := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+		if len(values) > 0 {
+			stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: values}}})
+		}
+
+		if stmt.ReflectValue.CanAddr() && stmt.Dest != stmt.Model && stmt.Model != nil {
+			_, queryValues = schema.GetIdentityFieldValuesMap(stmt.Context, reflect.ValueOf(stmt.Model), stmt.Schema.PrimaryFields)
+			column, values = schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+			if len(values) > 0 {
+				stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: values}}})
+			}
+		}
+	}
+
+	queryClause(dc).ModifyStatement(stmt)
+	stmt.AddClauseIfNotExists(clause.Update{})
+	stmt.Build(stmt.DB.Callback().Update().Clauses...)
+}
@@ -0,0 +1,131 @@
+package softdelete
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+type item struct {
+	ItemID    uint64 `gorm:"primaryKey"`
+	Code      string
+	Label     string
+	DeletedAt DeletedAt
+}
+
+func dryRun(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		NowFunc:                func() time.Time { return time.UnixMilli(1700000000000) },
+	})
+	require.NoError(t, err)
+	return db
+}
+
+func TestQueryFiltersDeleted(t *testing.T) {
+	stmt := dryRun(t).Where("code = ?", "a").Find(&[]item{}).Statement
+	assert.Equal(t, "SELECT * FROM `items` WHERE code = ? AND `items`.`deleted_at` = ?", stmt.SQL.String())
+}
+
+func TestQueryWrapsOrCondition(t *testing.T) {
+	stmt := dryRun(t).Where("code = ? or label = ?", "a", "b").Find(&[]item{}).Statement
+	assert.Equal(t, "SELECT * FROM `items` WHERE (code = ? or label = ?) AND `items`.`deleted_at` = ?", stmt.SQL.String())
+}
+
+func TestUnscopedQuery(t *testing.T) {
+	stmt := dryRun(t).Unscoped().Find(&[]item{}).Statement
+	assert.Equal(t, "SELECT * FROM `items`", stmt.SQL.String())
+}
+
+func TestDeleteSetsTimestamp(t *testing.T) {
+	stmt := dryRun(t).Delete(&item{ItemID: 1}).Statement
+	assert.Equal(t, "UPDATE `items` SET `deleted_at`=? WHERE `items`.`item_id` = ? AND `items`.`deleted_at` = ?", stmt.SQL.String())
+	assert.Equal(t, []any{DeletedAt(1700000000000), uint64(1), 0}, stmt.Vars)
+}
+
+func TestUnscopedDelete(t *testing.T) {
+	stmt := dryRun(t).Unscoped().Delete(&item{ItemID: 1}).Statement
+	assert.Equal(t, "DELETE FROM `items` WHERE `items`.`item_id` = ?", stmt.SQL.String())
+}
+
+func TestUpdateSkipsDeleted(t *testing.T) {
+	stmt := dryRun(t).Model(&item{}).Where("code = ?", "a").Update("label", "b").Statement
+	assert.Equal(t, "UPDATE `items` SET `label`=? WHERE code = ? AND `items`.`deleted_at` = ?", stmt.SQL.String())
+}
+
+// recorder 记录 db 执行的所有 SQL
+func recorder(t *testing.T, db *gorm.DB) *[]string {
+	var statements []string
+	record := func(db *gorm.DB) { statements = append(statements, db.Statement.SQL.String()) }
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:sql", record))
+	require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:sql", record))
+	require.NoError(t, db.Callback().Delete().After("gorm:delete").Register("test:sql", record))
+	return &statements
+}
+
+func TestRestore(t *testing.T) {
+	db := dryRun(t)
+	statements := recorder(t, db)
+	_, err := Restore(db, &item{}, []uint64{1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"UPDATE `items` SET `deleted_at`=? WHERE deleted_at <> ? AND item_id in (?,?)"}, *statements)
+}
+
+type treeItem struct {
+	NodeID    uint64 `gorm:"primaryKey"`
+	ParentID  *uint64
+	DeletedAt DeletedAt
+}
+
+func (*treeItem) TableName() string { return "nodes" }
+
+func (*treeItem) ParentColumn() string { return "parent_id" }
+
+func TestRestoreTree(t *testing.T) {
+	db := dryRun(t)
+	statements := recorder(t, db)
+	_, err := Restore(db, &treeItem{}, []uint64{1, 2})
+	require.NoError(t, err)
+	// 先查询恢复的记录的父级，父级都不在回收站中时才恢复
+	assert.Equal(t, []string{
+		"SELECT `parent_id` FROM `nodes` WHERE deleted_at <> ? AND node_id in (?,?) AND parent_id is not null",
+		"UPDATE `nodes` SET `deleted_at`=? WHERE deleted_at <> ? AND node_id in (?,?)",
+	}, *statements)
+}
+
+func TestPurgeLevels(t *testing.T) {
+	id := func(v uint64) *uint64 { return &v }
+	// 1 ─┬─ 2 ─── 4
+	//    └─ 3
+	// 5 ─── 6
+	nodes := []node{{ID: 1}, {ID: 2, ParentID: id(1)}, {ID: 3, ParentID: id(1)}, {ID: 4, ParentID: id(2)},
+		{ID: 5}, {ID: 6, ParentID: id(5)}}
+
+	tests := []struct {
+		name       string
+		candidates []uint64
+		expected   [][]uint64
+	}{
+		{"整个子树", []uint64{1, 2, 3, 4}, [][]uint64{{3, 4}, {2}, {1}}},
+		{"叶子", []uint64{4, 6}, [][]uint64{{4, 6}}},
+		{"子孙未删除时保留祖先", []uint64{1, 2, 3, 5}, [][]uint64{{3}}},
+		{"没有可以清理的记录", []uint64{5}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, purgeLevels(nodes, tt.candidates))
+		})
+	}
+}
+
+func TestPrimaryKey(t *testing.T) {
+	pk, err := PrimaryKey(dryRun(t), &item{})
+	require.NoError(t, err)
+	assert.Equal(t, "item_id", pk)
+}
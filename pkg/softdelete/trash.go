@@ -0,0 +1,219 @@
+package softdelete
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const column = "deleted_at"
+
+// ErrParentTrashed 恢复树形模型的记录时，父级记录仍在回收站中且没有一起恢复
+var ErrParentTrashed = errors.New("softdelete: 父级记录仍在回收站中")
+
+// Tree 树形模型，例如菜单。记录只能与仍在回收站中的父级记录一起恢复，
+// 只有整个子树都在回收站中时才能被清理，清理时先删除子孙记录，因此父级外键不需要级联删除。
+type Tree interface {
+	// ParentColumn 返回保存父级记录主键的列名
+	ParentColumn() string
+}
+
+// Trashed 只查询已被软删除的记录
+func Trashed(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Where(column+" <> ?", 0)
+}
+
+// PrimaryKey 返回模型的主键列名
+func PrimaryKey(db *gorm.DB, model any) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return "", err
+	}
+	if stmt.Schema.PrioritizedPrimaryField == nil {
+		return "", fmt.Errorf("模型 %s 没有声明主键", stmt.Schema.Name)
+	}
+	return stmt.Schema.PrioritizedPrimaryField.DBName, nil
+}
+
+// Restore 恢复主键在 ids 中的已删除记录，返回恢复的记录数。
+// model 为 Tree 时，父级记录仍在回收站中且不在 ids 中时返回 ErrParentTrashed，不恢复任何记录。
+func Restore(db *gorm.DB, model any, ids []uint64) (int64, error) {
+	pk, err := PrimaryKey(db, model)
+	if err != nil {
+		return 0, err
+	}
+	if tree, ok := model.(Tree); ok {
+		var parentIDs []uint64
+		err := Trashed(db).Model(model).Where(pk+" in ?", ids).Where(tree.ParentColumn()+" is not null").
+			Pluck(tree.ParentColumn(), &parentIDs).Error
+		if err != nil {
+			return 0, err
+		}
+		var trashed int64
+		if parentIDs = excluded(parentIDs, ids); len(parentIDs) > 0 {
+			if err := Trashed(db).Model(model).Where(pk+" in ?", parentIDs).Count(&trashed).Error; err != nil {
+				return 0, err
+			}
+		}
+		if trashed > 0 {
+			return 0, ErrParentTrashed
+		}
+	}
+	result := Trashed(db).Model(model).Where(pk+" in ?", ids).Update(column, 0)
+	return result.RowsAffected, result.Error
+}
+
+// Purge 物理删除已删除记录。ids 不为空时只删除指定主键的记录，否则删除所有在 before 之前删除的记录。
+// model 为 Tree 时，存在未被一起删除的子孙记录的记录会被保留，其余记录按从叶子到根的顺序删除。
+func Purge(db *gorm.DB, model any, ids []uint64, before time.Time) (int64, error) {
+	query := Trashed(db).Where(column+" < ?", before.UnixMilli())
+	if len(ids) > 0 {
+		pk, err := PrimaryKey(db, model)
+		if err != nil {
+			return 0, err
+		}
+		query = Trashed(db).Where(pk+" in ?", ids)
+	}
+	tree, ok := model.(Tree)
+	if !ok {
+		result := query.Delete(model)
+		return result.RowsAffected, result.Error
+	}
+
+	pk, err := PrimaryKey(db, model)
+	if err != nil {
+		return 0, err
+	}
+	var candidates []uint64
+	if err := query.Model(model).Pluck(pk, &candidates).Error; err != nil {
+		return 0, err
+	}
+	if len(candidates) == 0 {
+		return 0, nil
+	}
+	var nodes []node
+	if err := db.Unscoped().Model(model).Select(pk+" as id", tree.ParentColumn()+" as parent_id").
+		Scan(&nodes).Error; err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, level := range purgeLevels(nodes, candidates) {
+			result := tx.Unscoped().Where(pk+" in ?", level).Delete(model)
+			if result.Error != nil {
+				return result.Error
+			}
+			count += result.RowsAffected
+		}
+		return nil
+	})
+	return count, err
+}
+
+// node 树形模型中的一条记录
+type node struct {
+	ID       uint64
+	ParentID *uint64
+}
+
+// purgeLevels 返回 candidates 中可以清理的记录，按子树的高度分组，叶子在第一组，
+// 每条记录的子记录都在它之前的组中。记录只有在所有子记录都可以清理时才能清理。
+func purgeLevels(nodes []node, candidates []uint64) [][]uint64 {
+	purgeable := make(map[uint64]bool, len(candidates))
+	for _, id := range candidates {
+		purgeable[id] = true
+	}
+	children := make(map[uint64][]uint64)
+	for _, n := range nodes {
+		if n.ParentID != nil {
+			children[*n.ParentID] = append(children[*n.ParentID], n.ID)
+		}
+	}
+
+	// 不能清理的记录会使其祖先也不能清理，重复排除直到没有变化
+	for changed := true; changed; {
+		changed = false
+		for id := range purgeable {
+			for _, child := range children[id] {
+				if !purgeable[child] {
+					delete(purgeable, id)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	height := make(map[uint64]int, len(purgeable))
+	var heightOf func(id uint64) int
+	heightOf = func(id uint64) int {
+		if h, ok := height[id]; ok {
+			return h
+		}
+		height[id] = 0
+		for _, child := range children[id] {
+			if h := heightOf(child) + 1; h > height[id] {
+				height[id] = h
+			}
+		}
+		return height[id]
+	}
+	var levels [][]uint64
+	for id := range purgeable {
+		h := heightOf(id)
+		for len(levels) <= h {
+			levels = append(levels, nil)
+		}
+		levels[h] = append(levels[h], id)
+	}
+	for _, level := range levels {
+		sort.Slice(level, func(i, j int) bool { return level[i] < level[j] })
+	}
+	return levels
+}
+
+// excluded 返回 values 中不在 ids 中的值
+func excluded(values, ids []uint64) []uint64 {
+	skip := make(map[uint64]bool, len(ids))
+	for _, id := range ids {
+		skip[id] = true
+	}
+	var result []uint64
+	for _, value := range values {
+		if !skip[value] {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// RunPurger 每隔 interval 物理删除 models 中删除时间超过 retention 的记录，直到 ctx 结束
+func RunPurger(ctx context.Context, db *gorm.DB, retention, interval time.Duration, models ...any) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			before := time.Now().Add(-retention)
+			for _, model := range models {
+				count, err := Purge(db.WithContext(ctx), model, nil, before)
+				if err != nil {
+					zap.L().Error("清理回收站失败", zap.String("model", fmt.Sprintf("%T", model)), zap.Error(err))
+					continue
+				}
+				if count > 0 {
+					zap.L().Info("清理回收站", zap.String("model", fmt.Sprintf("%T", model)), zap.Int64("count", count))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package tracing
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// spanKey 在 GORM 语句实例中保存 span 的键
+const spanKey = "tracing:span"
+
+// gormPlugin 为每条 SQL 创建一个 span，父 span 来自语句的 context
+type gormPlugin struct {
+	tracer trace.Tracer
+}
+
+// NewGormPlugin 创建追踪 SQL 的 GORM 插件，通过 db.Use 注册
+func NewGormPlugin(provider trace.TracerProvider) gorm.Plugin {
+	return &gormPlugin{tracer: Tracer(provider)}
+}
+
+func (p *gormPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize 在每类操作的回调链首尾分别注册开始和结束 span 的回调
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	type register func(name string, fn func(*gorm.DB)) error
+	callback := db.Callback()
+	// 每类操作在回调链首尾的注册函数
+	operations := map[string][2]register{
+		"create": {callback.Create().Before("*").Register, callback.Create().After("*").Register},
+		"query":  {callback.Query().Before("*").Register, callback.Query().After("*").Register},
+		"update": {callback.Update().Before("*").Register, callback.Update().After("*").Register},
+		"delete": {callback.Delete().Before("*").Register, callback.Delete().After("*").Register},
+		"row":    {callback.Row().Before("*").Register, callback.Row().After("*").Register},
+		"raw":    {callback.Raw().Before("*").Register, callback.Raw().After("*").Register},
+	}
+	for operation, registers := range operations {
+		if err := registers[0]("tracing:before_"+operation, p.before(operation)); err != nil {
+			return err
+		}
+		if err := registers[1]("tracing:after_"+operation, after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *gormPlugin) before(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		// 不在请求中的调用（例如回收站的定期清理）不单独创建追踪
+		if ctx == nil || !trace.SpanContextFromContext(ctx).IsValid() {
+			return
+		}
+		_, span := p.tracer.Start(ctx, "gorm."+operation, trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attribute.String("db.system", "mysql")))
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func after(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.sql.table", db.Statement.Table),
+		attribute.String("db.statement", db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if db.Error != nil && db.Error != gorm.ErrRecordNotFound {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// ExporterNone 只生成追踪ID，不导出 span
+	ExporterNone = "none"
+	// ExporterOTLP 通过 OTLP/HTTP 导出到收集器
+	ExporterOTLP = "otlp"
+	// ExporterFile 以 JSON 格式逐行写入本地文件
+	ExporterFile = "file"
+)
+
+// Config 追踪的配置
+type Config struct {
+	ServiceName string
+	// Exporter 导出方式，取值为 ExporterNone、ExporterOTLP 或 ExporterFile
+	Exporter string
+	// Endpoint OTLP 收集器的地址，例如 localhost:4318
+	Endpoint string
+	// Insecure 为 true 时使用 HTTP 而不是 HTTPS 连接收集器
+	Insecure bool
+	// File 导出到文件时的文件路径
+	File string
+	// SampleRatio 没有上游追踪时的采样比例，上游已经采样的请求总是采样
+	SampleRatio float64
+}
+
+// Provider 追踪的 TracerProvider，停止时需要调用 Shutdown 导出剩余的 span
+type Provider struct {
+	*sdktrace.TracerProvider
+	file io.Closer
+}
+
+// NewProvider 根据配置创建 TracerProvider。不导出 span 时仍然会生成追踪ID，用于关联日志。
+func NewProvider(ctx context.Context, cfg Config) (*Provider, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(cfg.ServiceName)))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Provider{}
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	}
+	switch cfg.Exporter {
+	case ExporterNone, "":
+	case ExporterOTLP:
+		exporterOptions := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			exporterOptions = append(exporterOptions, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(ctx, exporterOptions...)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	case ExporterFile:
+		if err := os.MkdirAll(filepath.Dir(cfg.File), 0o755); err != nil {
+			return nil, err
+		}
+		file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		p.file = file
+		options = append(options, sdktrace.WithBatcher(exporter))
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter: %s", cfg.Exporter)
+	}
+
+	p.TracerProvider = sdktrace.NewTracerProvider(options...)
+	return p, nil
+}
+
+// Shutdown 导出剩余的 span 并关闭导出器
+func (p *Provider) Shutdown(ctx context.Context) error {
+	err := p.TracerProvider.Shutdown(ctx)
+	if p.file != nil {
+		if closeErr := p.file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type redisSpanKey struct{}
+
+// redisHook 为每个 Redis 命令创建一个 span
+type redisHook struct {
+	tracer trace.Tracer
+}
+
+// NewRedisHook 创建追踪命令的 Redis 钩子，通过 client.AddHook 注册
+func NewRedisHook(provider trace.TracerProvider) redis.Hook {
+	return &redisHook{tracer: Tracer(provider)}
+}
+
+func (h *redisHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return h.start(ctx, "redis."+cmd.Name(), attribute.String("db.operation", cmd.Name())), nil
+}
+
+func (h *redisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	end(ctx, cmd.Err())
+	return nil
+}
+
+func (h *redisHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return h.start(ctx, "redis.pipeline", attribute.Int("db.redis.num_cmd", len(cmds))), nil
+}
+
+func (h *redisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if cmd.Err() != nil {
+			err = cmd.Err()
+			break
+		}
+	}
+	end(ctx, err)
+	return nil
+}
+
+// start 在 ctx 处于追踪中时创建子 span，返回携带子 span 的 context
+func (h *redisHook) start(ctx context.Context, name string, attrs ...attribute.KeyValue) context.Context {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	attrs = append(attrs, attribute.String("db.system", "redis"))
+	ctx, span := h.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	// 单独保存 span，避免在没有创建子 span 时结束了调用方的 span
+	return context.WithValue(ctx, redisSpanKey{}, span)
+}
+
+// end 结束 start 创建的 span，键不存在不是错误
+func end(ctx context.Context, err error) {
+	span, ok := ctx.Value(redisSpanKey{}).(trace.Span)
+	if !ok {
+		return
+	}
+	if err != nil && err != redis.Nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,83 @@
+// Package tracing 实现请求ID和基于 OpenTelemetry 的分布式追踪。
+//
+// 每个请求都有一个请求ID（X-Request-ID）和一个 W3C Trace Context（traceparent），
+// 两者保存在请求的 context 中，由 Logger 附加到日志上，由 response.Fail 返回给客户端。
+// GORM 和 Redis 的调用通过 NewGormPlugin 和 NewRedisHook 创建子 span，
+// span 由 NewProvider 根据配置导出到 OTLP 收集器或本地文件。
+package tracing
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// instrumentation 创建 tracer 时使用的名称
+const instrumentation = "orca"
+
+type requestIDKey struct{}
+
+// WithRequestID 返回携带请求ID的 context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID 返回 ctx 中的请求ID，没有时返回空字符串
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// TraceID 返回 ctx 中当前 span 的追踪ID，没有时返回空字符串
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
+
+// Fields 返回 ctx 中的请求ID、追踪ID和 span ID 对应的日志字段
+func Fields(ctx context.Context) []zap.Field {
+	var fields []zap.Field
+	if requestID := RequestID(ctx); requestID != "" {
+		fields = append(fields, zap.String("requestId", requestID))
+	}
+	spanContext := trace.SpanContextFromContext(ctx)
+	if spanContext.HasTraceID() {
+		fields = append(fields, zap.String("traceId", spanContext.TraceID().String()))
+	}
+	if spanContext.HasSpanID() {
+		fields = append(fields, zap.String("spanId", spanContext.SpanID().String()))
+	}
+	return fields
+}
+
+// Logger 返回附加了 ctx 中请求ID和追踪ID的全局日志，处理请求时应使用它代替 zap.L()
+func Logger(ctx context.Context) *zap.Logger {
+	return zap.L().With(Fields(ctx)...)
+}
+
+// maxRequestIDLength 客户端传入的请求ID的最大长度
+const maxRequestIDLength = 128
+
+// ValidRequestID 判断客户端传入的请求ID是否可以直接使用，只允许字母、数字和 -_.:，避免日志注入和过长的ID
+func ValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Tracer 返回 provider 中本项目使用的 tracer
+func Tracer(provider trace.TracerProvider) trace.Tracer {
+	return provider.Tracer(instrumentation)
+}
@@ -0,0 +1,109 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// newProvider 创建记录所有已结束 span 的 TracerProvider
+func newProvider() (*sdktrace.TracerProvider, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)), recorder
+}
+
+func TestValidRequestID(t *testing.T) {
+	assert.True(t, ValidRequestID("V1StGXR8_Z5jdHi6B-myT"))
+	assert.True(t, ValidRequestID("req:2024.01-01"))
+	assert.False(t, ValidRequestID(""))
+	assert.False(t, ValidRequestID("id with space"))
+	assert.False(t, ValidRequestID("id\nforged=1"))
+	assert.False(t, ValidRequestID(string(make([]byte, maxRequestIDLength+1))))
+}
+
+func TestFields(t *testing.T) {
+	assert.Empty(t, Fields(context.Background()))
+	assert.Empty(t, TraceID(context.Background()))
+
+	provider, _ := newProvider()
+	ctx, span := Tracer(provider).Start(WithRequestID(context.Background(), "request-1"), "test")
+	defer span.End()
+
+	assert.Equal(t, "request-1", RequestID(ctx))
+	assert.Equal(t, span.SpanContext().TraceID().String(), TraceID(ctx))
+
+	fields := Fields(ctx)
+	require.Len(t, fields, 3)
+	assert.Equal(t, "requestId", fields[0].Key)
+	assert.Equal(t, "request-1", fields[0].String)
+	assert.Equal(t, "traceId", fields[1].Key)
+	assert.Equal(t, "spanId", fields[2].Key)
+	assert.Equal(t, span.SpanContext().SpanID().String(), fields[2].String)
+}
+
+func TestGormPlugin(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+
+	provider, recorder := newProvider()
+	require.NoError(t, db.Use(NewGormPlugin(provider)))
+
+	// 不在追踪中的调用不创建 span
+	var rows []map[string]any
+	require.NoError(t, db.Table("menu").Find(&rows).Error)
+	assert.Empty(t, recorder.Ended())
+
+	ctx, parent := Tracer(provider).Start(context.Background(), "request")
+	require.NoError(t, db.WithContext(ctx).Table("menu").Where("code = ?", "system").Find(&rows).Error)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "gorm.query", spans[0].Name())
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, "SELECT * FROM `menu` WHERE code = ?", attributeOf(spans[0], "db.statement"))
+	assert.Equal(t, "menu", attributeOf(spans[0], "db.sql.table"))
+}
+
+// attributeOf 返回 span 中字符串属性的值
+func attributeOf(span sdktrace.ReadOnlySpan, key string) string {
+	for _, attr := range span.Attributes() {
+		if string(attr.Key) == key {
+			return attr.Value.AsString()
+		}
+	}
+	return ""
+}
+
+func TestRedisHook(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	defer client.Close()
+
+	provider, recorder := newProvider()
+	client.AddHook(NewRedisHook(provider))
+
+	ctx, parent := Tracer(provider).Start(context.Background(), "request")
+	assert.Error(t, client.Get(ctx, "key").Err())
+
+	// 调用方的 span 没有被钩子结束
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "redis.get", spans[0].Name())
+	assert.Equal(t, "Error", spans[0].Status().Code.String())
+	assert.True(t, parent.IsRecording())
+	parent.End()
+}
@@ -0,0 +1,66 @@
+// Package uow 提供工作单元，事务通过 context 在服务和仓储之间传递，嵌套的调用共享同一个事务。
+//
+// 工作单元绑定创建它的数据库连接，服务通过构造函数接收工作单元，而不是依赖全局的连接：
+//
+//	unit := uow.New(db)
+//	err := unit.Do(ctx, func(ctx context.Context) error { ... })
+package uow
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"orca/pkg/errors"
+)
+
+type txKey struct{}
+
+// Interface 工作单元支持的操作。服务依赖该接口而不是具体的实现，测试时可以替换为假的实现。
+type Interface interface {
+	// Do 在事务中执行 fn，fn 收到的 context 携带该事务，fn 返回错误时回滚
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// UnitOfWork 在数据库连接 db 上开启事务的工作单元
+type UnitOfWork struct {
+	db *gorm.DB
+}
+
+var _ Interface = (*UnitOfWork)(nil)
+
+// New 创建在 db 上开启事务的工作单元
+func New(db *gorm.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// WithTx 返回携带事务 tx 的 context，之后通过该 context 执行的仓储操作都在 tx 中进行
+func WithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// Tx 返回 ctx 中携带的事务
+func Tx(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return tx, ok
+}
+
+// Conn 返回 ctx 中携带的事务，ctx 不在事务中时返回绑定了 ctx 的 db
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := Tx(ctx); ok {
+		return tx
+	}
+	return db.WithContext(ctx)
+}
+
+// Do 在事务中执行 fn，fn 收到的 context 携带该事务，fn 返回错误时回滚。
+// ctx 已经在事务中时 fn 直接加入该事务，由最外层的 Do 提交或回滚，因此服务之间可以互相调用。
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := Tx(ctx); ok {
+		return fn(ctx)
+	}
+	if u.db == nil {
+		return errors.New("uow: 没有设置数据库连接")
+	}
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(WithTx(ctx, tx))
+	})
+}
@@ -0,0 +1,63 @@
+package uow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func dryRun(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	return db
+}
+
+func TestConn(t *testing.T) {
+	db := dryRun(t)
+	ctx := context.Background()
+	_, ok := Tx(ctx)
+	assert.False(t, ok)
+	assert.NotSame(t, db, Conn(ctx, db))
+
+	tx := db.Session(&gorm.Session{})
+	assert.Same(t, tx, Conn(WithTx(ctx, tx), db))
+}
+
+func TestDoJoinsOuterTransaction(t *testing.T) {
+	tx := dryRun(t).Session(&gorm.Session{})
+	ctx := WithTx(context.Background(), tx)
+
+	// 已经在事务中时直接加入外层事务，不会开启新的事务
+	depth := 0
+	unit := New(dryRun(t))
+	err := unit.Do(ctx, func(ctx context.Context) error {
+		depth++
+		return unit.Do(ctx, func(inner context.Context) error {
+			depth++
+			current, ok := Tx(inner)
+			assert.True(t, ok)
+			assert.Same(t, tx, current)
+			return nil
+		})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, depth)
+}
+
+func TestDoWithoutDB(t *testing.T) {
+	called := false
+	err := New(nil).Do(context.Background(), func(context.Context) error {
+		called = true
+		return nil
+	})
+	assert.Error(t, err)
+	assert.False(t, called)
+}
@@ -8,17 +8,20 @@ import (
 	"time"
 )
 
-var Sonyflake *sonyflake.Sonyflake
-
-func init() {
+// NewSonyflake 创建雪花算法ID生成器，机器ID从环境变量 SONYFLAKE_MACHINE_ID 中读取
+func NewSonyflake() (*sonyflake.Sonyflake, error) {
 	machineID, err := getMachineIDFromEnv()
 	if err != nil {
-		panic("生成雪花算法ID时，获取机器ID失败：" + err.Error())
+		return nil, errors.Wrap(err, "生成雪花算法ID时，获取机器ID失败")
 	}
-	Sonyflake = sonyflake.NewSonyflake(sonyflake.Settings{
+	flake := sonyflake.NewSonyflake(sonyflake.Settings{
 		StartTime: time.Date(2003, 11, 27, 0, 0, 0, 0, time.UTC),
 		MachineID: machineID,
 	})
+	if flake == nil {
+		return nil, errors.New("创建雪花算法ID生成器失败")
+	}
+	return flake, nil
 }
 
 // getMachineIDFromEnv 从环境变量中获取 MachineID
@@ -0,0 +1,29 @@
+package idutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewSonyflake 测试从环境变量读取机器ID并生成ID。
+func TestNewSonyflake(t *testing.T) {
+	t.Setenv("SONYFLAKE_MACHINE_ID", "42")
+
+	flake, err := NewSonyflake()
+	require.NoError(t, err, "NewSonyflake 应该没有错误")
+
+	id, err := flake.NextID()
+	require.NoError(t, err, "NextID 应该没有错误")
+	assert.Equal(t, uint64(42), id&0xFFFF, "ID的低16位应该是机器ID")
+}
+
+// TestNewSonyflakeInvalidMachineID 测试机器ID缺失或无效时返回错误而不是 panic。
+func TestNewSonyflakeInvalidMachineID(t *testing.T) {
+	for _, value := range []string{"", "abc", "65536"} {
+		t.Setenv("SONYFLAKE_MACHINE_ID", value)
+		_, err := NewSonyflake()
+		assert.Error(t, err, "SONYFLAKE_MACHINE_ID=%q 时应该返回错误", value)
+	}
+}
@@ -0,0 +1,113 @@
+package validation
+
+import (
+	"reflect"
+	"sync"
+)
+
+type (
+	// Constraint describes the rules declared for a struct field in a form that can be used to document it,
+	// for example in an OpenAPI schema.
+	Constraint struct {
+		// Required is true when the field has the Required or NotNil rule.
+		Required bool
+		// MinLength and MaxLength are set by the Length and RuneLength rules. A nil value means no limit.
+		MinLength, MaxLength *int
+		// Enum lists the values accepted by the In rule.
+		Enum []interface{}
+		// Minimum and Maximum are set by the Min and Max rules.
+		Minimum, Maximum interface{}
+		// ExclusiveMinimum and ExclusiveMaximum are true when the corresponding rule is exclusive.
+		ExclusiveMinimum, ExclusiveMaximum bool
+		// Pattern is the regular expression of the Match rule.
+		Pattern string
+		// Conditional is true when the field has rules wrapped in When, which depend on other fields.
+		Conditional bool
+	}
+
+	// describeKey identifies a struct being described. The type is part of the key because
+	// a struct and its first field share the same address.
+	describeKey struct {
+		ptr uintptr
+		typ reflect.Type
+	}
+)
+
+// describing holds the structs being described by Describe. ValidateStruct records the rules
+// of these structs into the associated map instead of validating them.
+var describing sync.Map
+
+// Describe returns the constraints declared by the Validate method of v, keyed by the field names
+// used in validation errors. v must be a pointer to a struct whose Validate method calls ValidateStruct,
+// otherwise an empty map is returned. Rules wrapped in When are not evaluated and only mark the field
+// as Conditional. v must not be validated by other goroutines while it is being described.
+func Describe(v Validatable) map[string]Constraint {
+	constraints := map[string]Constraint{}
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return constraints
+	}
+
+	key := describeKey{ptr: value.Pointer(), typ: value.Type()}
+	describing.Store(key, constraints)
+	defer describing.Delete(key)
+	_ = v.Validate()
+	return constraints
+}
+
+// describeStruct records the rules of fields into constraints.
+func describeStruct(value reflect.Value, fields []*FieldRules, constraints map[string]Constraint) error {
+	for i, fr := range fields {
+		fv := reflect.ValueOf(fr.fieldPtr)
+		if fv.Kind() != reflect.Ptr {
+			return NewInternalError(ErrFieldPointer(i))
+		}
+		ft := findStructField(value, fv)
+		if ft == nil {
+			return NewInternalError(ErrFieldNotFound(i))
+		}
+		name := getErrorFieldName(ft)
+		constraint := constraints[name]
+		describeRules(fr.rules, &constraint)
+		constraints[name] = constraint
+	}
+	return nil
+}
+
+// describeRules records the rules that can be described into constraint, other rules are ignored.
+func describeRules(rules []Rule, constraint *Constraint) {
+	for _, rule := range rules {
+		switch r := rule.(type) {
+		case RequiredRule:
+			if r.condition && !r.skipNil {
+				constraint.Required = true
+			}
+		case notNilRule:
+			constraint.Required = true
+		case LengthRule:
+			if r.min > 0 {
+				min := r.min
+				constraint.MinLength = &min
+			}
+			if r.max > 0 {
+				max := r.max
+				constraint.MaxLength = &max
+			}
+		case InRule:
+			constraint.Enum = append([]interface{}{}, r.elements...)
+		case ThresholdRule:
+			switch r.operator {
+			case greaterThan, greaterEqualThan:
+				constraint.Minimum = r.threshold
+				constraint.ExclusiveMinimum = r.operator == greaterThan
+			case lessThan, lessEqualThan:
+				constraint.Maximum = r.threshold
+				constraint.ExclusiveMaximum = r.operator == lessThan
+			}
+		case MatchRule:
+			constraint.Pattern = r.re.String()
+		case WhenRule:
+			constraint.Conditional = true
+		}
+	}
+}
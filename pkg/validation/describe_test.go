@@ -0,0 +1,52 @@
+package validation
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type describedStruct struct {
+	Name    string  `json:"name"`
+	Kind    string  `json:"kind"`
+	Age     int     `json:"age"`
+	Code    string  `json:"code"`
+	Parent  *string `json:"parent"`
+	Comment *string
+}
+
+func (s *describedStruct) Validate() error {
+	return ValidateStruct(s,
+		Field(&s.Name, Required, Length(2, 20)),
+		Field(&s.Kind, In("a", "b")),
+		Field(&s.Age, Min(1), Max(150).Exclusive()),
+		Field(&s.Code, NilOrNotEmpty, Match(regexp.MustCompile(`^[a-z]+$`))),
+		Field(&s.Parent, When(s.Kind == "b", Required)),
+		Field(&s.Comment, NotNil, Length(0, 100)),
+	)
+}
+
+func TestDescribe(t *testing.T) {
+	s := &describedStruct{}
+	constraints := Describe(s)
+
+	assert.Equal(t, Constraint{Required: true, MinLength: intPtr(2), MaxLength: intPtr(20)}, constraints["name"])
+	assert.Equal(t, Constraint{Enum: []interface{}{"a", "b"}}, constraints["kind"])
+	assert.Equal(t, Constraint{Minimum: 1, Maximum: 150, ExclusiveMaximum: true}, constraints["age"])
+	assert.Equal(t, Constraint{Pattern: "^[a-z]+$"}, constraints["code"])
+	assert.Equal(t, Constraint{Conditional: true}, constraints["parent"])
+	assert.Equal(t, Constraint{Required: true, MaxLength: intPtr(100)}, constraints["Comment"])
+
+	// the struct is validated as usual once it is described
+	assert.EqualError(t, s.Validate(), "Comment: is required; code: cannot be blank; name: cannot be blank.")
+}
+
+func TestDescribeNotStruct(t *testing.T) {
+	assert.Empty(t, Describe(Model2{}))
+	assert.Empty(t, Describe((*describedStruct)(nil)))
+}
+
+func intPtr(i int) *int {
+	return &i
+}
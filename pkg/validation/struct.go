@@ -77,6 +77,10 @@ func ValidateStructWithContext(ctx context.Context, structPtr interface{}, field
 		// treat a nil struct pointer as valid
 		return nil
 	}
+	if constraints, ok := describing.Load(describeKey{ptr: value.Pointer(), typ: value.Type()}); ok {
+		// the struct is being described by Describe
+		return describeStruct(value.Elem(), fields, constraints.(map[string]Constraint))
+	}
 	value = value.Elem()
 
 	errs := Errors{}
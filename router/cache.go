@@ -0,0 +1,41 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"orca/app"
+	"orca/conf"
+	"orca/middleware"
+	"orca/service"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// cacheResponse 返回缓存路由响应的中间件，tags 为响应涉及的资源。未启用响应缓存时返回的中间件直接放行。
+func cacheResponse(a *app.App, users *service.UserService, tags ...string) gin.HandlerFunc {
+	if !conf.GetBool("cache.enabled") {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return middleware.Cache(a.Cache, roleScope(users), tags...)
+}
+
+// roleScope 以当前用户的角色集合作为缓存的权限范围，拥有相同角色集合的用户共享缓存，未识别用户的请求共享同一份缓存
+func roleScope(users *service.UserService) func(c *gin.Context) (string, error) {
+	return func(c *gin.Context) (string, error) {
+		userID := middleware.GetUserID(c)
+		if userID == 0 {
+			return "anonymous", nil
+		}
+		roleIDs, err := users.RoleIDs(c, userID)
+		if err != nil {
+			return "", err
+		}
+		roleIDs = slices.Clone(roleIDs)
+		slices.Sort(roleIDs)
+		parts := make([]string, len(roleIDs))
+		for i, id := range roleIDs {
+			parts[i] = strconv.FormatUint(id, 10)
+		}
+		return "roles:" + strings.Join(parts, ","), nil
+	}
+}
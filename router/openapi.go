@@ -0,0 +1,47 @@
+package router
+
+import (
+	"orca/middleware"
+	"orca/pkg/code"
+	"orca/pkg/etag"
+	"orca/pkg/locale"
+	"orca/pkg/openapi"
+	"orca/pkg/route"
+)
+
+// 多个接口共用的参数和错误码，接口的文档在注册路由时声明
+var (
+	ifMatch        = openapi.RequestHeader(etag.HeaderIfMatch, true, "读取资源时响应中的 ETag，资源已被修改时返回 409")
+	acceptLanguage = openapi.RequestHeader(locale.HeaderAcceptLanguage, false, "菜单名称和描述使用的语言，默认为配置中的默认语言")
+	dryRun         = openapi.Query("dryRun", &openapi.Schema{Type: "boolean", Default: false}, "为 true 时只返回将要执行的变更")
+	idempotencyKey = openapi.RequestHeader(middleware.HeaderIdempotencyKey, false, "重试时使用相同的值，服务端重放第一个请求的响应，响应头 Idempotent-Replayed 为 true")
+	documentFormat = openapi.Query("format", openapi.String("json", "yaml"), "文档格式，默认根据 Content-Type 判断，都没有时为 json")
+
+	menuErrors         = []code.Code{code.ErrBind, code.ErrValidate, code.ErrMenuParentInvalid, code.ErrMenuCycle}
+	preconditionErrors = []code.Code{code.ErrPreconditionRequired, code.ErrPreconditionFailed}
+	idempotencyErrors  = []code.Code{code.ErrIdempotencyKeyInvalid, code.ErrIdempotencyKeyReused, code.ErrIdempotencyInProgress}
+)
+
+// hidden 不输出到文档中的接口
+var hidden = &openapi.Operation{Hidden: true}
+
+// newSpec 创建接口文档，文档中的接口由 document 根据注册的路由添加
+func newSpec() *openapi.Spec {
+	spec := openapi.New(openapi.Info{
+		Title:       "Orca",
+		Version:     "1.0.0",
+		Description: "失败的响应中 code 为业务错误码，所有错误码见 x-error-codes。",
+	})
+	spec.Tag("menu", "菜单的增删改查、树形结构、导入导出和修订记录")
+	spec.Tag("trash", "回收站，resource 为表名，例如 menu")
+	spec.Tag("me", "当前用户")
+	spec.Tag("health", "存活、就绪检查和指标")
+	return spec
+}
+
+// document 将 r 中注册的路由声明的接口文档添加到 spec
+func document(spec *openapi.Spec, r *route.Router) {
+	for _, rt := range r.Routes() {
+		spec.Add(rt.Method, rt.Path, rt.Operation)
+	}
+}
@@ -0,0 +1,86 @@
+package router
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"orca/app"
+	"orca/controller/menu"
+	"orca/controller/navigation"
+	"orca/models"
+	"orca/pkg/repository"
+	"orca/pkg/route"
+	"orca/pkg/uow"
+	"orca/service"
+	"sort"
+	"strings"
+)
+
+// newMenuService 创建菜单服务，路由和同步权限共用
+func newMenuService(a *app.App) *service.MenuService {
+	roleService := service.NewRoleService(repository.New[models.Role](a.Mysql))
+	return service.NewMenuService(uow.New(a.Mysql), repository.New[models.Menu](a.Mysql),
+		repository.New[models.MenuRevision](a.Mysql), service.NewMenuTranslationRepository(a.Mysql),
+		service.NewRoleMenuRepository(a.Mysql), roleService)
+}
+
+// Permissions 返回 r 中所有路由声明的权限，多个路由声明同一个权限编码时合并为一个，描述使用第一个路由的描述。
+// 结果按权限编码排序。
+func Permissions(r *route.Router) []*models.Permission {
+	byCode := make(map[string]*models.Permission)
+	for _, rt := range r.Routes() {
+		if rt.Permission == "" {
+			continue
+		}
+		permission, ok := byCode[rt.Permission]
+		if !ok {
+			permission = &models.Permission{Code: rt.Permission, Module: rt.Module(), Description: rt.Description}
+			byCode[rt.Permission] = permission
+		}
+		permission.Routes = append(permission.Routes, rt.Method+" "+rt.Path)
+	}
+
+	permissions := make([]*models.Permission, 0, len(byCode))
+	for _, permission := range byCode {
+		permissions = append(permissions, permission)
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i].Code < permissions[j].Code })
+	return permissions
+}
+
+// SyncPermissions 将 r 中声明的权限同步为菜单中的按钮，dryRun 为 true 时只返回将要创建的菜单。
+// 创建了菜单时使导航缓存和菜单的响应缓存失效。
+func SyncPermissions(ctx context.Context, a *app.App, r *route.Router, dryRun bool) (*models.PermissionSyncResult, error) {
+	result, err := newMenuService(a).SyncPermissions(ctx, Permissions(r), dryRun)
+	if err != nil || dryRun || len(result.Directories)+len(result.Created) == 0 {
+		return result, err
+	}
+	if err := navigation.NewCache(a.Redis).Invalidate(ctx); err != nil {
+		a.Logger.Warn("清除导航缓存失败", zap.Error(err))
+	}
+	if err := a.Cache.Invalidate(ctx, menu.CacheTag); err != nil {
+		a.Logger.Warn("清除菜单的响应缓存失败", zap.Error(err))
+	}
+	return result, nil
+}
+
+// permissionSyncer 在应用启动时同步权限的钩子，孤立的按钮和冲突的模块只记录警告日志
+func permissionSyncer(a *app.App, r *route.Router) app.Hook {
+	return app.Hook{
+		Name: "permissions",
+		Start: func(ctx context.Context) error {
+			result, err := SyncPermissions(ctx, a, r, false)
+			if err != nil {
+				return err
+			}
+			a.Logger.Info("同步权限完成",
+				zap.Strings("directories", result.Directories), zap.Strings("created", result.Created))
+			if len(result.Orphans) > 0 {
+				a.Logger.Warn("存在没有路由声明的按钮：" + strings.Join(result.Orphans, "、"))
+			}
+			if len(result.Conflicts) > 0 {
+				a.Logger.Warn("以下模块的编码已被按钮使用，没有创建其中的权限：" + strings.Join(result.Conflicts, "、"))
+			}
+			return nil
+		},
+	}
+}
@@ -2,17 +2,206 @@ package router
 
 import (
 	"github.com/gin-gonic/gin"
+	"net/http"
+	"orca/app"
+	"orca/conf"
+	"orca/controller/health"
 	"orca/controller/menu"
+	"orca/controller/navigation"
+	"orca/controller/trash"
 	"orca/middleware"
+	"orca/models"
+	"orca/pkg/code"
+	"orca/pkg/etag"
+	healthcheck "orca/pkg/health"
+	"orca/pkg/openapi"
+	"orca/pkg/query"
+	"orca/pkg/repository"
+	"orca/pkg/route"
+	"orca/service"
+	"slices"
 )
 
-func Add(server *gin.Engine) {
-	server.Use(middleware.Cors())
-	server.Use(middleware.GinLogger(), middleware.GinRecovery(true))
+// routes 由其他文件在 init 中注册的路由，codegen -scaffold 生成的模块通过它注册
+var routes []func(r *route.Router, a *app.App)
 
-	server.POST("/menu", menu.Controller.Create)
-	server.GET("/menu/:code", menu.Controller.Get)
-	server.GET("/menu", menu.Controller.List)
-	server.DELETE("/menu", menu.Controller.Delete)
-	server.PUT("/menu/:code", menu.Controller.Update)
+// Add 注册中间件和所有路由，控制器使用的依赖都来自 a。返回的 Router 记录了所有路由的权限编码，用于同步权限。
+func Add(server *gin.Engine, a *app.App) *route.Router {
+	// 让 c.Value 读取 c.Request 的 context，控制器把 c 传给服务时追踪和请求ID可以传递到数据库和 Redis
+	server.ContextWithFallback = true
+	// 追踪中间件最先注册，之后的中间件和日志都能读取请求ID和追踪ID
+	server.Use(middleware.Trace(a.Tracing))
+	server.Use(middleware.Cors(a.Cors))
+	// 指标中间件在 GinRecovery 之前注册，发生 panic 的请求也会以 500 被记录
+	server.Use(middleware.Metrics(a.Metrics))
+	server.Use(middleware.GinLogger(a.Logger), middleware.GinRecovery(a.Logger, true))
+	// 限流在日志之后注册，被拒绝的请求也会记录日志和指标
+	if len(a.RateLimits) > 0 {
+		server.Use(middleware.RateLimit(a.RateLimiter, a.RateLimits))
+	}
+	// 幂等性在限流之后注册，被限流拒绝的请求不会占用幂等键
+	if a.Idempotency != nil {
+		server.Use(middleware.Idempotency(a.Idempotency, int64(conf.GetInt("idempotency.maxBodySize", 1<<20))))
+	}
+
+	userService := service.NewUserService(repository.New[models.User](a.Mysql), service.NewUserRoleRepository(a.Mysql))
+	menuService := newMenuService(a)
+
+	navigationCache := navigation.NewCache(a.Redis)
+
+	menuController := menu.New(menuService, navigationCache, a.Cache)
+	navigationController := navigation.New(userService, menuService, navigationCache)
+	trashController := trash.New(a.Mysql, menuService, navigationCache, a.Cache)
+	healthController := health.New(a.Health)
+
+	r := route.New(server)
+	health := []string{"health"}
+	r.GET("/healthz", "", "存活检查", &openapi.Operation{Tags: health}, healthController.Healthz)
+	r.GET("/readyz", "", "就绪检查", &openapi.Operation{
+		Tags:     health,
+		Response: &healthcheck.Report{},
+		Errors:   []code.Code{code.ErrServiceUnavailable},
+	}, healthController.Readyz)
+	r.GET("/metrics", "", "Prometheus 指标", &openapi.Operation{
+		Tags:          health,
+		Response:      "",
+		ResponseTypes: []string{"text/plain"},
+	}, gin.WrapH(a.Metrics.Handler()))
+	// 接口文档只在非 release 模式下提供，文档根据所有注册的路由在第一次请求时生成
+	spec := newSpec()
+	if gin.Mode() != gin.ReleaseMode {
+		r.GET("/openapi.json", "", "接口文档", hidden, spec.Handler(server.Routes))
+		r.GET("/swagger/*any", "", "Swagger UI", hidden, gin.WrapH(http.StripPrefix("/swagger", openapi.UI("/openapi.json"))))
+	}
+
+	// 菜单的修改会记录操作人，请求中没有用户身份时操作人为空
+	menus := r.Group("/menu", middleware.OptionalIdentity())
+	menus.POST("", "menu:create", "创建菜单", &openapi.Operation{
+		Parameters: []*openapi.Parameter{idempotencyKey},
+		Request:    &models.Menu{},
+		Errors:     slices.Concat(menuErrors, idempotencyErrors, []code.Code{code.ErrMenuAlreadyExist}),
+	}, menuController.Create)
+	menus.GET("/:code", "menu:read", "查询菜单", &openapi.Operation{
+		Description: "响应头中返回 ETag，请求头 If-None-Match 与之相同时返回 304。",
+		Parameters:  []*openapi.Parameter{openapi.RequestHeader(etag.HeaderIfNoneMatch, false, "上次读取时的 ETag")},
+		Response:    &models.Menu{},
+		Errors:      []code.Code{code.ErrMenuNotFound},
+	}, menuController.Get)
+	// 菜单列表和菜单树读多写少，响应按用户的角色集合缓存，菜单变化时由控制器使缓存失效
+	cachedMenus := cacheResponse(a, userService, menu.CacheTag)
+	menus.GET("", "menu:read", "查询菜单", &openapi.Operation{
+		Summary:  "分页查询菜单列表",
+		Query:    menu.ListOptions,
+		Response: &query.Result[*models.Menu]{},
+		Errors:   []code.Code{code.ErrValidate},
+	}, cachedMenus, menuController.List)
+	menus.GET("/tree", "menu:read", "查询菜单", &openapi.Operation{
+		Summary:    "查询菜单树",
+		Parameters: []*openapi.Parameter{acceptLanguage},
+		Response:   []*models.MenuTree{},
+	}, cachedMenus, menuController.Tree)
+	menus.PATCH("/tree", "menu:move", "移动菜单", &openapi.Operation{
+		Summary:  "批量移动菜单",
+		Request:  &models.MenuMoveRequest{},
+		Response: []*models.MenuTree{},
+		Errors:   slices.Concat(menuErrors, []code.Code{code.ErrMenuNotFound}),
+	}, menuController.Move)
+	menus.GET("/export", "menu:export", "导出菜单", &openapi.Operation{
+		Parameters:    []*openapi.Parameter{documentFormat},
+		Response:      &models.MenuDocument{},
+		ResponseTypes: []string{"application/json", "application/yaml"},
+		Errors:        []code.Code{code.ErrValidate},
+	}, menuController.Export)
+	menus.POST("/import", "menu:import", "导入菜单", &openapi.Operation{
+		Description: "按 code 匹配已有菜单进行创建或更新。",
+		Parameters: []*openapi.Parameter{
+			documentFormat,
+			dryRun,
+			openapi.Query("deleteMissing", &openapi.Schema{Type: "boolean", Default: false}, "为 true 时删除文档中不存在的菜单"),
+		},
+		Request:      &models.MenuDocument{},
+		RequestTypes: []string{"application/json", "application/yaml"},
+		Response:     &models.MenuImportResult{},
+		Errors:       slices.Concat(menuErrors, idempotencyErrors, []code.Code{code.ErrMenuAlreadyExist}),
+	}, menuController.Import)
+	menus.DELETE("", "menu:delete", "删除菜单", &openapi.Operation{
+		Description: "If-Match 中需要包含每个被删除菜单的 ETag。存在子菜单或角色绑定时返回 409，data 中为阻止删除的依赖项。",
+		Parameters: []*openapi.Parameter{
+			openapi.Query("codes", openapi.Array(openapi.String()), "菜单编码，可以重复传入多个"),
+			openapi.Query("mode", &openapi.Schema{
+				Type:    "string",
+				Enum:    []any{models.EnumMenuDeleteModeRestrict, models.EnumMenuDeleteModeCascade, models.EnumMenuDeleteModeReparent},
+				Default: models.EnumMenuDeleteModeRestrict,
+			}, "存在子菜单时的处理方式"),
+			dryRun,
+			ifMatch,
+		},
+		Response: &models.MenuDeletePlan{},
+		Errors:   slices.Concat(preconditionErrors, []code.Code{code.ErrValidate, code.ErrMenuNotFound, code.ErrMenuParentInvalid, code.ErrMenuHasDependents}),
+	}, menuController.Delete)
+	menus.PUT("/:code", "menu:update", "更新菜单", &openapi.Operation{
+		Parameters: []*openapi.Parameter{ifMatch},
+		Request:    &models.Menu{},
+		Errors:     slices.Concat(menuErrors, preconditionErrors, []code.Code{code.ErrMenuNotFound, code.ErrMenuAlreadyExist}),
+	}, menuController.Update)
+	menus.PATCH("/:code", "menu:update", "更新菜单", &openapi.Operation{
+		Summary:      "部分更新菜单",
+		Parameters:   []*openapi.Parameter{ifMatch},
+		Request:      &models.Menu{},
+		RequestTypes: []string{"application/merge-patch+json", "application/json-patch+json"},
+		Response:     &models.Menu{},
+		Errors:       slices.Concat(menuErrors, preconditionErrors, []code.Code{code.ErrMenuNotFound, code.ErrMenuAlreadyExist}),
+	}, menuController.Patch)
+	menus.GET("/:code/revisions", "menu:read", "查询菜单", &openapi.Operation{
+		Summary:  "分页查询菜单修订记录",
+		Query:    menu.RevisionOptions,
+		Response: &query.Result[*models.MenuRevision]{},
+		Errors:   []code.Code{code.ErrValidate, code.ErrMenuNotFound},
+	}, menuController.Revisions)
+	menus.POST("/:code/revisions/:revision/rollback", "menu:rollback", "回滚菜单", &openapi.Operation{
+		Summary:    "回滚菜单到修订记录",
+		Parameters: []*openapi.Parameter{{Name: "revision", In: "path", Required: true, Schema: openapi.Integer()}, ifMatch, idempotencyKey},
+		Response:   &models.Menu{},
+		Errors: slices.Concat(preconditionErrors, idempotencyErrors, []code.Code{code.ErrValidate, code.ErrMenuNotFound,
+			code.ErrMenuRevisionNotFound, code.ErrMenuParentInvalid, code.ErrMenuAlreadyExist}),
+	}, menuController.Rollback)
+
+	r.GET("/trash/:resource", "trash:read", "查询回收站", &openapi.Operation{
+		Summary: "分页查询回收站",
+		Parameters: []*openapi.Parameter{
+			openapi.Query("page", &openapi.Schema{Type: "integer", Default: 1}, "页码"),
+			openapi.Query("limit", &openapi.Schema{Type: "integer", Default: 10}, "每页数量，1到100之间"),
+		},
+		Response: &models.TrashList{},
+		Errors:   []code.Code{code.ErrValidate, code.ErrNotFound},
+	}, trashController.List)
+	// 恢复菜单会记录修订，需要识别操作人
+	r.POST("/trash/:resource/restore", "trash:restore", "恢复回收站中的记录", &openapi.Operation{
+		Parameters: []*openapi.Parameter{openapi.Query("ids", openapi.Array(openapi.Integer()), "记录ID，可以重复传入多个"), idempotencyKey},
+		Errors:     slices.Concat(idempotencyErrors, []code.Code{code.ErrValidate, code.ErrNotFound, code.ErrConflict}),
+	}, middleware.OptionalIdentity(), trashController.Restore)
+	r.DELETE("/trash/:resource", "trash:purge", "清理回收站", &openapi.Operation{
+		Parameters: []*openapi.Parameter{openapi.Query("ids", openapi.Array(openapi.Integer()), "记录ID，未指定时清空该资源的回收站")},
+		Errors:     []code.Code{code.ErrValidate, code.ErrNotFound},
+	}, trashController.Purge)
+
+	// 导航只返回当前用户有权限的菜单，不需要额外的权限
+	me := r.Group("/me", middleware.Identity())
+	me.GET("/navigation", "", "查询当前用户的导航", &openapi.Operation{
+		Parameters: []*openapi.Parameter{
+			openapi.RequestHeader(middleware.HeaderUserID, true, "网关认证后透传的用户ID"),
+			acceptLanguage,
+		},
+		Response: &models.Navigation{},
+		Errors:   []code.Code{code.ErrUnauthorized},
+	}, navigationController.Get)
+
+	for _, add := range routes {
+		add(r, a)
+	}
+	document(spec, r)
+	if conf.GetBool("permission.syncOnStartup") {
+		a.Append(permissionSyncer(a, r))
+	}
+	return r
 }
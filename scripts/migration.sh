@@ -26,10 +26,14 @@ sql_files=(
   './scripts/sql/users.sql'
   './scripts/sql/user_auth.sql'
   './scripts/sql/menu.sql'
+  './scripts/sql/menu_translation.sql'
+  './scripts/sql/menu_revision.sql'
   './scripts/sql/roles.sql'
   './scripts/sql/role_menu.sql'
   './scripts/sql/user_role.sql'
 )
+# 建表脚本只创建不存在的表，已有的表由 migrations 中的脚本按文件名顺序升级，这些脚本可以重复执行
+sql_files+=(./scripts/sql/migrations/*.sql)
 
 echo -e "${yellow}${prefix}正在检查数据库 ${green}${db_name}${yellow} 是否存在...${reset}"
 
@@ -3,7 +3,7 @@ create table if not exists menu
     menu_id     bigint unsigned auto_increment comment '菜单唯一ID',
     created_at  datetime    not null default current_timestamp comment '创建时间',
     updated_at  datetime    not null default current_timestamp on update current_timestamp comment '最后更新时间',
-    deleted_at  bigint                default 0 comment '删除时间',
+    deleted_at  bigint      not null default 0 comment '删除时间（毫秒时间戳，0表示未删除）',
     label       varchar(20)  not null comment '菜单名称',
     code        varchar(255) not null comment '菜单编码',
     parent_id   bigint unsigned       default null comment '父级菜单ID',
@@ -20,15 +20,17 @@ create table if not exists menu
     `show`      boolean               default true comment '是否展示该菜单',
     status      boolean               default true comment '是否启用菜单',
     description text                  default null comment '菜单描述',
+    version     bigint unsigned not null default 1 comment '版本号，每次修改后递增，用于乐观锁',
 
     primary key (menu_id),
     index idx_menu_created_at (created_at),
     index idx_menu_parent_id (parent_id),
-    unique index idx_menu_label (label),
-    unique index idx_menu_code (code),
-    unique index idx_menu_label_code_deleted_at (label, code, deleted_at),
+    index idx_menu_deleted_at (deleted_at),
+    unique index idx_menu_label_deleted_at (label, deleted_at),
+    unique index idx_menu_code_deleted_at (code, deleted_at),
 
+    # 清理回收站时先删除子菜单，不允许级联删除子菜单
     constraint fk_menu_parent_id foreign key (parent_id)
-        references menu (menu_id) on delete cascade on update cascade
+        references menu (menu_id) on delete restrict on update cascade
 ) engine = InnoDB
   default charset = utf8mb4 comment ='菜单信息表';
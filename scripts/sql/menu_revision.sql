@@ -0,0 +1,22 @@
+create table if not exists menu_revision
+(
+    revision_id bigint unsigned auto_increment comment '修订唯一ID',
+    menu_id     bigint unsigned not null comment '菜单ID',
+    action      enum (
+        'create',   # 创建
+        'update',   # 修改
+        'delete',   # 删除
+        'rollback', # 回滚
+        'restore'   # 从回收站恢复
+        )                       not null comment '产生修订的操作',
+    actor_id    bigint unsigned          default null comment '操作人的用户ID',
+    snapshot    json            not null comment '修改后菜单的完整状态，包括父级菜单编码、角色绑定和翻译',
+    changes     json            not null comment '与修改前相比发生变化的字段',
+    rollback_of bigint unsigned          default null comment '回滚操作恢复的修订ID',
+    created_at  datetime        not null default current_timestamp comment '修订时间',
+
+    primary key (revision_id),
+    # 不使用外键，菜单从回收站中被清理后修订记录仍然保留
+    index idx_menu_revision_menu_id (menu_id, revision_id)
+) engine = InnoDB
+  default charset = utf8mb4 comment ='菜单修订记录表';
@@ -0,0 +1,13 @@
+create table if not exists menu_translation
+(
+    menu_id     bigint unsigned not null comment '菜单ID',
+    locale      varchar(35)     not null comment '语言标签，例如 en-US',
+    label       varchar(20)     not null comment '该语言下的菜单名称',
+    description text                     default null comment '该语言下的菜单描述',
+
+    primary key (menu_id, locale),
+
+    constraint fk_menu_translation_menu_id foreign key (menu_id)
+        references menu (menu_id) on delete cascade on update cascade
+) engine = InnoDB
+  default charset = utf8mb4 comment ='菜单名称和描述的多语言翻译表';
@@ -0,0 +1,117 @@
+# 将使用旧版建表脚本创建的数据库升级到支持软删除的表结构：
+#   1. deleted_at 改为 not null default 0（毫秒时间戳，0表示未删除），并将已有的 null 回填为 0
+#   2. 唯一索引加上 deleted_at，已删除的记录不再占用名称、编码等唯一值
+#   3. 菜单的父级外键改为 on delete restrict，清理回收站时先删除子菜单
+#   4. 菜单增加用于乐观锁的 version 列
+# 脚本根据 information_schema 判断每一步是否已经执行，可以重复执行，对使用新版建表脚本创建的数据库不做修改。
+
+drop procedure if exists orca_migrate_exec_if;
+drop procedure if exists orca_migrate_drop_index;
+drop procedure if exists orca_migrate_add_index;
+
+delimiter //
+
+# orca_migrate_exec_if 在 condition 为真时执行动态的 DDL
+create procedure orca_migrate_exec_if(condition_ boolean, statement_ text)
+begin
+    if condition_ then
+        set @orca_migrate_statement = statement_;
+        prepare stmt from @orca_migrate_statement;
+        execute stmt;
+        deallocate prepare stmt;
+    end if;
+end //
+
+# orca_migrate_drop_index 删除存在的索引
+create procedure orca_migrate_drop_index(table_ varchar(64), index_ varchar(64))
+begin
+    call orca_migrate_exec_if(exists(select 1
+                                     from information_schema.statistics
+                                     where table_schema = database()
+                                       and table_name = table_
+                                       and index_name = index_),
+                              concat('alter table `', table_, '` drop index `', index_, '`'));
+end //
+
+# orca_migrate_add_index 添加不存在的索引，columns_ 为索引包含的列，以逗号分隔
+create procedure orca_migrate_add_index(table_ varchar(64), unique_ boolean, index_ varchar(64), columns_ text)
+begin
+    call orca_migrate_exec_if(not exists(select 1
+                                         from information_schema.statistics
+                                         where table_schema = database()
+                                           and table_name = table_
+                                           and index_name = index_),
+                              concat('alter table `', table_, '` add ', if(unique_, 'unique index `', 'index `'),
+                                     index_, '` (', columns_, ')'));
+end //
+
+delimiter ;
+
+# users
+update users set deleted_at = 0 where deleted_at is null;
+alter table users
+    modify deleted_at bigint not null default 0 comment '删除时间（毫秒时间戳，0表示未删除）';
+call orca_migrate_drop_index('users', 'idx_users_phone');
+call orca_migrate_drop_index('users', 'idx_users_username');
+call orca_migrate_drop_index('users', 'idx_users_email');
+call orca_migrate_drop_index('users', 'idx_users_username_email_deleted_at');
+# 创建时间不再是唯一索引
+call orca_migrate_exec_if(exists(select 1
+                                 from information_schema.statistics
+                                 where table_schema = database()
+                                   and table_name = 'users'
+                                   and index_name = 'idx_users_created_at'
+                                   and non_unique = 0),
+                          'alter table users drop index idx_users_created_at');
+call orca_migrate_add_index('users', false, 'idx_users_created_at', 'created_at');
+call orca_migrate_add_index('users', false, 'idx_users_deleted_at', 'deleted_at');
+call orca_migrate_add_index('users', true, 'idx_users_phone_deleted_at', 'phone, deleted_at');
+call orca_migrate_add_index('users', true, 'idx_users_username_deleted_at', 'username, deleted_at');
+call orca_migrate_add_index('users', true, 'idx_users_email_deleted_at', 'email, deleted_at');
+
+# roles
+update roles set deleted_at = 0 where deleted_at is null;
+alter table roles
+    modify deleted_at bigint not null default 0 comment '删除时间（毫秒时间戳，0表示未删除）';
+call orca_migrate_drop_index('roles', 'idx_roles_label');
+call orca_migrate_drop_index('roles', 'idx_roles_code');
+call orca_migrate_drop_index('roles', 'idx_roles_label_code_deleted_at');
+call orca_migrate_add_index('roles', false, 'idx_roles_deleted_at', 'deleted_at');
+call orca_migrate_add_index('roles', true, 'idx_roles_label_deleted_at', 'label, deleted_at');
+call orca_migrate_add_index('roles', true, 'idx_roles_code_deleted_at', 'code, deleted_at');
+
+# menu
+update menu set deleted_at = 0 where deleted_at is null;
+alter table menu
+    modify deleted_at bigint not null default 0 comment '删除时间（毫秒时间戳，0表示未删除）';
+call orca_migrate_exec_if(not exists(select 1
+                                     from information_schema.columns
+                                     where table_schema = database()
+                                       and table_name = 'menu'
+                                       and column_name = 'version'),
+                          'alter table menu add version bigint unsigned not null default 1 comment ''版本号，每次修改后递增，用于乐观锁''');
+call orca_migrate_drop_index('menu', 'idx_menu_label');
+call orca_migrate_drop_index('menu', 'idx_menu_code');
+call orca_migrate_drop_index('menu', 'idx_menu_label_code_deleted_at');
+call orca_migrate_add_index('menu', false, 'idx_menu_deleted_at', 'deleted_at');
+call orca_migrate_add_index('menu', true, 'idx_menu_label_deleted_at', 'label, deleted_at');
+call orca_migrate_add_index('menu', true, 'idx_menu_code_deleted_at', 'code, deleted_at');
+# 清理回收站时先删除子菜单，不允许级联删除子菜单
+call orca_migrate_exec_if(exists(select 1
+                                 from information_schema.referential_constraints
+                                 where constraint_schema = database()
+                                   and table_name = 'menu'
+                                   and constraint_name = 'fk_menu_parent_id'
+                                   and delete_rule = 'CASCADE'),
+                          'alter table menu drop foreign key fk_menu_parent_id');
+call orca_migrate_exec_if(not exists(select 1
+                                     from information_schema.referential_constraints
+                                     where constraint_schema = database()
+                                       and table_name = 'menu'
+                                       and constraint_name = 'fk_menu_parent_id'),
+                          'alter table menu add constraint fk_menu_parent_id foreign key (parent_id)
+                               references menu (menu_id) on delete restrict on update cascade');
+
+drop procedure orca_migrate_exec_if;
+drop procedure orca_migrate_drop_index;
+drop procedure orca_migrate_add_index;
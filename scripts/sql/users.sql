@@ -3,7 +3,7 @@ create table if not exists users
     user_id       bigint unsigned auto_increment comment '用户ID',
     created_at    datetime    not null default current_timestamp comment '创建时间',
     updated_at    datetime    not null default current_timestamp on update current_timestamp comment '最后更新时间',
-    deleted_at    bigint               default 0 comment '删除时间',
+    deleted_at    bigint      not null default 0 comment '删除时间（毫秒时间戳，0表示未删除）',
     last_login_at timestamp            default null comment '最后登陆时间',
     last_login_ip varchar(128)         default null comment '最后登陆IP',
     username      varchar(20) not null comment '用户名',
@@ -36,11 +36,11 @@ create table if not exists users
     date_of_birth datetime comment '出生日期',
 
     primary key (user_id),
-    unique index idx_users_phone (phone),
-    unique index idx_users_username (username),
-    unique index idx_users_email (email),
-    unique index idx_users_created_at (created_at),
-    unique index idx_users_username_email_deleted_at (username, email, deleted_at)
+    index idx_users_created_at (created_at),
+    index idx_users_deleted_at (deleted_at),
+    unique index idx_users_phone_deleted_at (phone, deleted_at),
+    unique index idx_users_username_deleted_at (username, deleted_at),
+    unique index idx_users_email_deleted_at (email, deleted_at)
 ) engine = InnoDB
   auto_increment = 100000
   default charset = utf8mb4 comment ='用户基本信息表';
\ No newline at end of file
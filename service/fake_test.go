@@ -0,0 +1,557 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"orca/models"
+	"orca/pkg/query"
+	"orca/pkg/repository"
+	"orca/pkg/uow"
+)
+
+// scopeDB 只用于收集仓储条件中的子句，不会连接数据库
+var scopeDB, _ = gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{
+	DryRun:                 true,
+	DisableAutomaticPing:   true,
+	SkipDefaultTransaction: true,
+})
+
+var fakeSchemas sync.Map
+
+var (
+	// termPattern 条件中的一项，例如 code = ?、deleted_at <> ?、menu_id in ?
+	termPattern = regexp.MustCompile(`^(\w+)\s*(=|<>|in)\s*\(?\?\)?$`)
+	// orderPattern 排序中的一项，例如 deleted_at desc、deleted_at = 0 desc
+	orderPattern = regexp.MustCompile(`^(\w+)( = 0)?( asc| desc)?$`)
+	// splitOr 和 splitAnd 按不区分大小写的 or 和 and 拆分条件
+	splitOr  = regexp.MustCompile(`(?i)\s+or\s+`)
+	splitAnd = regexp.MustCompile(`(?i)\s+and\s+`)
+)
+
+// snapshotter 可以被 fakeUnitOfWork 回滚的假仓储
+type snapshotter interface {
+	snapshot() (restore func())
+}
+
+type fakeTxKey struct{}
+
+// fakeUnitOfWork 不使用数据库的工作单元，fn 返回错误时将 stores 恢复到调用前的状态
+type fakeUnitOfWork struct {
+	stores []snapshotter
+}
+
+var _ uow.Interface = (*fakeUnitOfWork)(nil)
+
+func (u *fakeUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(fakeTxKey{}) != nil {
+		return fn(ctx)
+	}
+	restores := make([]func(), 0, len(u.stores))
+	for _, store := range u.stores {
+		restores = append(restores, store.snapshot())
+	}
+	if err := fn(context.WithValue(ctx, fakeTxKey{}, true)); err != nil {
+		for _, restore := range restores {
+			restore()
+		}
+		return err
+	}
+	return nil
+}
+
+// fakeRepository 保存在内存中的仓储，只支持服务实际使用的条件：
+// col = ?、col <> ?、col in ? 以及它们通过 and 或 or 的组合，排序支持 col [asc|desc] 和 col = 0 [asc|desc]。
+// 唯一索引与数据库中的一样包含 deleted_at 列，冲突时返回 gorm.ErrDuplicatedKey。
+type fakeRepository[T any] struct {
+	schema *schema.Schema
+	rows   []*T
+	nextID uint64
+	unique [][]string
+}
+
+var _ repository.Interface[struct{}] = (*fakeRepository[struct{}])(nil)
+
+// newFakeRepository 创建假仓储，unique 中的每一项为一个唯一索引的列，例如 "code"
+func newFakeRepository[T any](unique ...string) *fakeRepository[T] {
+	s, err := schema.Parse(new(T), &fakeSchemas, schema.NamingStrategy{})
+	if err != nil {
+		panic(err)
+	}
+	r := &fakeRepository[T]{schema: s, nextID: 1}
+	for _, column := range unique {
+		r.unique = append(r.unique, strings.Split(column, ","))
+	}
+	return r
+}
+
+func (r *fakeRepository[T]) snapshot() func() {
+	rows := make([]*T, 0, len(r.rows))
+	for _, row := range r.rows {
+		rows = append(rows, clone(row))
+	}
+	nextID := r.nextID
+	return func() {
+		r.rows, r.nextID = rows, nextID
+	}
+}
+
+func clone[T any](row *T) *T {
+	copied := *row
+	return &copied
+}
+
+func (r *fakeRepository[T]) value(row *T, column string) any {
+	field := r.schema.LookUpField(column)
+	if field == nil {
+		panic(fmt.Sprintf("fake: 模型 %s 没有列 %s", r.schema.Name, column))
+	}
+	value, _ := field.ValueOf(context.Background(), reflect.ValueOf(row).Elem())
+	return value
+}
+
+func (r *fakeRepository[T]) set(row *T, column string, value any) {
+	field := r.schema.LookUpField(column)
+	if field == nil {
+		panic(fmt.Sprintf("fake: 模型 %s 没有列 %s", r.schema.Name, column))
+	}
+	if err := field.Set(context.Background(), reflect.ValueOf(row).Elem(), value); err != nil {
+		panic(err)
+	}
+}
+
+func (r *fakeRepository[T]) softDelete() bool {
+	return r.schema.LookUpField("deleted_at") != nil
+}
+
+func (r *fakeRepository[T]) deleted(row *T) bool {
+	return r.softDelete() && format(r.value(row, "deleted_at")) != "0"
+}
+
+// statement 将 scopes 应用到 scopeDB 上，返回收集到子句的语句
+func (r *fakeRepository[T]) statement(scopes []repository.Scope) *gorm.Statement {
+	db := scopeDB.Session(&gorm.Session{NewDB: true}).Model(new(T))
+	for _, scope := range scopes {
+		db = scope(db)
+	}
+	return db.Statement
+}
+
+// find 返回满足条件的记录，unscoped 为 false 且语句没有使用 Unscoped 时不包括已删除的记录
+func (r *fakeRepository[T]) find(scopes []repository.Scope, unscoped bool) []*T {
+	stmt := r.statement(scopes)
+	var matched []*T
+	for _, row := range r.rows {
+		if !unscoped && !stmt.Unscoped && r.deleted(row) {
+			continue
+		}
+		if r.match(stmt, row) {
+			matched = append(matched, row)
+		}
+	}
+	r.sort(stmt, matched)
+	return matched
+}
+
+func (r *fakeRepository[T]) match(stmt *gorm.Statement, row *T) bool {
+	c, ok := stmt.Clauses["WHERE"]
+	if !ok {
+		return true
+	}
+	for _, expr := range c.Expression.(clause.Where).Exprs {
+		e, ok := expr.(clause.Expr)
+		if !ok {
+			panic(fmt.Sprintf("fake: 不支持的条件 %T", expr))
+		}
+		if !r.eval(row, e.SQL, e.Vars) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *fakeRepository[T]) eval(row *T, sql string, vars []any) bool {
+	matched := false
+	for _, or := range splitOr.Split(strings.Trim(sql, "() "), -1) {
+		all := true
+		for _, term := range splitAnd.Split(or, -1) {
+			m := termPattern.FindStringSubmatch(strings.TrimSpace(term))
+			if m == nil {
+				panic(fmt.Sprintf("fake: 不支持的条件 %q", sql))
+			}
+			value := format(r.value(row, m[1]))
+			var ok bool
+			switch m[2] {
+			case "=":
+				ok = value == format(vars[0])
+			case "<>":
+				ok = value != format(vars[0])
+			case "in":
+				list := reflect.ValueOf(vars[0])
+				for i := 0; i < list.Len(); i++ {
+					ok = ok || value == format(list.Index(i).Interface())
+				}
+			}
+			vars = vars[1:]
+			all = all && ok
+		}
+		matched = matched || all
+	}
+	return matched
+}
+
+func (r *fakeRepository[T]) sort(stmt *gorm.Statement, rows []*T) {
+	pk := r.schema.PrioritizedPrimaryField.DBName
+	var orders []clause.OrderByColumn
+	if c, ok := stmt.Clauses["ORDER BY"]; ok {
+		orders = c.Expression.(clause.OrderBy).Columns
+	}
+	key := func(row *T, order clause.OrderByColumn) (string, bool) {
+		m := orderPattern.FindStringSubmatch(order.Column.Name)
+		if m == nil {
+			panic(fmt.Sprintf("fake: 不支持的排序 %q", order.Column.Name))
+		}
+		value := format(r.value(row, m[1]))
+		if m[2] != "" {
+			value = fmt.Sprint(value == "0")
+		}
+		return value, order.Desc || m[3] == " desc"
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, order := range orders {
+			a, desc := key(rows[i], order)
+			b, _ := key(rows[j], order)
+			if a != b {
+				return (compare(a, b) < 0) != desc
+			}
+		}
+		return compare(format(r.value(rows[i], pk)), format(r.value(rows[j], pk))) < 0
+	})
+}
+
+// compare 比较两个格式化后的值，数字按大小比较
+func compare(a, b string) int {
+	if len(a) != len(b) && strings.Trim(a+b, "0123456789") == "" {
+		return len(a) - len(b)
+	}
+	return strings.Compare(a, b)
+}
+
+// format 将值格式化为字符串以便比较，指针取其指向的值
+func format(value any) string {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "<nil>"
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return "<nil>"
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// conflict 报告 row 是否与其他记录违反唯一索引
+func (r *fakeRepository[T]) conflict(row *T) bool {
+	pk := r.schema.PrioritizedPrimaryField.DBName
+	for _, other := range r.rows {
+		if format(r.value(other, pk)) == format(r.value(row, pk)) {
+			continue
+		}
+		for _, columns := range r.unique {
+			same := !r.softDelete() || format(r.value(other, "deleted_at")) == format(r.value(row, "deleted_at"))
+			for _, column := range columns {
+				same = same && format(r.value(other, column)) == format(r.value(row, column))
+			}
+			if same {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// write 将 rows 的修改应用到 targets 上，违反唯一索引时不做修改并返回错误
+func (r *fakeRepository[T]) write(targets []*T, apply func(row *T)) (int64, error) {
+	updated := make([]*T, 0, len(targets))
+	for _, target := range targets {
+		row := clone(target)
+		apply(row)
+		updated = append(updated, row)
+	}
+	restore := r.snapshot()
+	for i, target := range targets {
+		*target = *updated[i]
+	}
+	for _, row := range targets {
+		if r.conflict(row) {
+			restore()
+			return 0, gorm.ErrDuplicatedKey
+		}
+	}
+	return int64(len(targets)), nil
+}
+
+func (r *fakeRepository[T]) Get(ctx context.Context, scopes ...repository.Scope) (*T, error) {
+	rows := r.find(scopes, false)
+	if len(rows) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return clone(rows[0]), nil
+}
+
+func (r *fakeRepository[T]) List(ctx context.Context, scopes ...repository.Scope) ([]*T, error) {
+	rows := r.find(scopes, false)
+	entities := make([]*T, 0, len(rows))
+	for _, row := range rows {
+		entities = append(entities, clone(row))
+	}
+	return entities, nil
+}
+
+func (r *fakeRepository[T]) Page(ctx context.Context, req *query.Request, scopes ...repository.Scope) (*query.Result[*T], error) {
+	items, _ := r.List(ctx, scopes...)
+	return &query.Result[*T]{Total: int64(len(items)), Items: items}, nil
+}
+
+func (r *fakeRepository[T]) Exists(ctx context.Context, scopes ...repository.Scope) (bool, error) {
+	return len(r.find(scopes, false)) > 0, nil
+}
+
+func (r *fakeRepository[T]) Create(ctx context.Context, entities ...*T) error {
+	restore := r.snapshot()
+	pk := r.schema.PrioritizedPrimaryField
+	for _, entity := range entities {
+		if format(r.value(entity, pk.DBName)) == "0" {
+			r.set(entity, pk.DBName, r.nextID)
+		}
+		if id, _ := r.value(entity, pk.DBName).(uint64); id >= r.nextID {
+			r.nextID = id + 1
+		}
+		for _, field := range r.schema.Fields {
+			if field.HasDefaultValue && field.DefaultValueInterface != nil && field.DBName != "" {
+				if _, zero := field.ValueOf(ctx, reflect.ValueOf(entity).Elem()); zero {
+					r.set(entity, field.DBName, field.DefaultValueInterface)
+				}
+			}
+		}
+		r.rows = append(r.rows, clone(entity))
+		if r.conflict(entity) {
+			restore()
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	return nil
+}
+
+func (r *fakeRepository[T]) Update(ctx context.Context, entity *T, columns []string, scopes ...repository.Scope) (int64, error) {
+	pk := r.schema.PrioritizedPrimaryField.DBName
+	targets := r.find(append(scopes, repository.Where(pk+" = ?", r.value(entity, pk))), false)
+	return r.write(targets, func(row *T) {
+		for _, field := range r.schema.Fields {
+			name := field.DBName
+			if name == "" || name == pk || (len(columns) == 0 && (name == "created_at" || name == "deleted_at")) ||
+				(len(columns) > 0 && !slices.Contains(columns, name)) {
+				continue
+			}
+			r.set(row, name, r.value(entity, name))
+		}
+	})
+}
+
+func (r *fakeRepository[T]) UpdateColumns(ctx context.Context, values map[string]any, scopes ...repository.Scope) (int64, error) {
+	return r.write(r.find(scopes, false), func(row *T) {
+		for column, value := range values {
+			r.set(row, column, value)
+		}
+	})
+}
+
+func (r *fakeRepository[T]) Delete(ctx context.Context, scopes ...repository.Scope) (int64, error) {
+	targets := r.find(scopes, false)
+	if !r.softDelete() {
+		r.rows = slices.DeleteFunc(r.rows, func(row *T) bool { return slices.Contains(targets, row) })
+		return int64(len(targets)), nil
+	}
+	return r.write(targets, func(row *T) {
+		r.set(row, "deleted_at", uint64(time.Now().UnixMilli()))
+	})
+}
+
+func (r *fakeRepository[T]) Restore(ctx context.Context, scopes ...repository.Scope) (int64, error) {
+	var targets []*T
+	for _, row := range r.find(scopes, true) {
+		if r.deleted(row) {
+			targets = append(targets, row)
+		}
+	}
+	return r.write(targets, func(row *T) {
+		r.set(row, "deleted_at", uint64(0))
+	})
+}
+
+// fakeTranslations 保存在内存中的菜单翻译
+type fakeTranslations struct {
+	rows map[uint64]models.Translations
+}
+
+var _ MenuTranslationRepository = (*fakeTranslations)(nil)
+
+func (f *fakeTranslations) snapshot() func() {
+	rows := make(map[uint64]models.Translations, len(f.rows))
+	for id, translations := range f.rows {
+		rows[id] = translations
+	}
+	return func() { f.rows = rows }
+}
+
+func (f *fakeTranslations) Load(ctx context.Context, menus []*models.Menu) error {
+	for _, menu := range menus {
+		// 假仓储中的菜单保留了写入时的翻译，从数据库查询的菜单没有翻译
+		menu.Translations = nil
+		if translations, ok := f.rows[menu.MenuID]; ok {
+			menu.Translations = models.Translations{}
+			for locale, translation := range translations {
+				copied := *translation
+				menu.Translations[locale] = &copied
+			}
+		}
+	}
+	return nil
+}
+
+func (f *fakeTranslations) Save(ctx context.Context, menuID uint64, translations models.Translations) error {
+	if translations == nil {
+		return nil
+	}
+	if len(translations) == 0 {
+		delete(f.rows, menuID)
+		return nil
+	}
+	f.rows[menuID] = translations
+	return nil
+}
+
+// fakeRoleMenus 保存在内存中的角色菜单绑定，角色来自 roles
+type fakeRoleMenus struct {
+	roles *fakeRepository[models.Role]
+	bound map[uint64][]uint64
+}
+
+var _ RoleMenuRepository = (*fakeRoleMenus)(nil)
+
+func (f *fakeRoleMenus) snapshot() func() {
+	bound := make(map[uint64][]uint64, len(f.bound))
+	for id, roleIDs := range f.bound {
+		bound[id] = roleIDs
+	}
+	return func() { f.bound = bound }
+}
+
+// codes 返回未删除的角色的编码
+func (f *fakeRoleMenus) codes() map[uint64]string {
+	roles, _ := f.roles.List(context.Background())
+	codes := make(map[uint64]string, len(roles))
+	for _, role := range roles {
+		codes[role.RoleID] = role.Code
+	}
+	return codes
+}
+
+func (f *fakeRoleMenus) MenuIDs(ctx context.Context, roleIDs []uint64) ([]uint64, error) {
+	codes := f.codes()
+	menuIDs := make([]uint64, 0)
+	for menuID, bound := range f.bound {
+		for _, roleID := range bound {
+			if _, ok := codes[roleID]; ok && slices.Contains(roleIDs, roleID) {
+				menuIDs = append(menuIDs, menuID)
+				break
+			}
+		}
+	}
+	sort.Slice(menuIDs, func(i, j int) bool { return menuIDs[i] < menuIDs[j] })
+	return menuIDs, nil
+}
+
+func (f *fakeRoleMenus) RoleCodes(ctx context.Context, menuIDs ...uint64) (map[uint64][]string, error) {
+	codes := f.codes()
+	roles := make(map[uint64][]string)
+	for menuID, bound := range f.bound {
+		if len(menuIDs) > 0 && !slices.Contains(menuIDs, menuID) {
+			continue
+		}
+		for _, roleID := range bound {
+			if code_, ok := codes[roleID]; ok {
+				roles[menuID] = append(roles[menuID], code_)
+			}
+		}
+		sort.Strings(roles[menuID])
+	}
+	return roles, nil
+}
+
+func (f *fakeRoleMenus) Replace(ctx context.Context, menuID uint64, roleIDs []uint64) error {
+	f.bound[menuID] = append([]uint64(nil), roleIDs...)
+	return nil
+}
+
+// fakeMenuService 使用假仓储的菜单服务
+type fakeMenuService struct {
+	*MenuService
+	menus        *fakeRepository[models.Menu]
+	revisions    *fakeRepository[models.MenuRevision]
+	roles        *fakeRepository[models.Role]
+	translations *fakeTranslations
+	bindings     *fakeRoleMenus
+}
+
+func newFakeMenuService() *fakeMenuService {
+	f := &fakeMenuService{
+		menus:        newFakeRepository[models.Menu]("code", "label"),
+		revisions:    newFakeRepository[models.MenuRevision](),
+		roles:        newFakeRepository[models.Role]("code"),
+		translations: &fakeTranslations{rows: map[uint64]models.Translations{}},
+	}
+	f.bindings = &fakeRoleMenus{roles: f.roles, bound: map[uint64][]uint64{}}
+	unit := &fakeUnitOfWork{stores: []snapshotter{f.menus, f.revisions, f.roles, f.translations, f.bindings}}
+	f.MenuService = NewMenuService(unit, f.menus, f.revisions, f.translations, f.bindings, NewRoleService(f.roles))
+	return f
+}
+
+// seed 直接写入菜单，不记录修订。父级菜单通过 ParentID 指定。
+func (f *fakeMenuService) seed(menus ...*models.Menu) {
+	if err := f.menus.Create(context.Background(), menus...); err != nil {
+		panic(err)
+	}
+}
+
+// menu 返回编码为 code_ 的菜单（包括已删除的菜单）
+func (f *fakeMenuService) menu(code_ string) *models.Menu {
+	menus := f.menus.find([]repository.Scope{repository.Where("code = ?", code_)}, true)
+	if len(menus) == 0 {
+		return nil
+	}
+	return clone(menus[len(menus)-1])
+}
+
+// actions 返回菜单的修订动作，按写入顺序排列
+func (f *fakeMenuService) actions(code_ string) []models.MenuRevisionAction {
+	menu := f.menu(code_)
+	var actions []models.MenuRevisionAction
+	for _, revision := range f.revisions.find([]repository.Scope{repository.Where("menu_id = ?", menu.MenuID)}, false) {
+		actions = append(actions, revision.Action)
+	}
+	return actions
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"orca/models"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/patch"
+	"orca/pkg/query"
+	"orca/pkg/repository"
+	"orca/pkg/uow"
+	"slices"
+)
+
+// readonlyFields 不允许通过 Patch 修改的字段
+var readonlyFields = []string{"menuId", "createdAt", "updatedAt", "deletedAt", "version"}
+
+// MenuService 菜单相关的业务逻辑，所有修改都会记录修订
+type MenuService struct {
+	unit         uow.Interface
+	menus        repository.Interface[models.Menu]
+	revisions    repository.Interface[models.MenuRevision]
+	translations MenuTranslationRepository
+	bindings     RoleMenuRepository
+	roles        *RoleService
+}
+
+// NewMenuService 创建菜单服务，修改菜单的操作在 unit 的事务中执行
+func NewMenuService(unit uow.Interface, menus repository.Interface[models.Menu],
+	revisions repository.Interface[models.MenuRevision], translations MenuTranslationRepository,
+	bindings RoleMenuRepository, roles *RoleService) *MenuService {
+	return &MenuService{unit: unit, menus: menus, revisions: revisions, translations: translations,
+		bindings: bindings, roles: roles}
+}
+
+// Get 根据编码查询未删除的菜单及其翻译
+func (s *MenuService) Get(ctx context.Context, code_ string, scopes ...repository.Scope) (*models.Menu, error) {
+	menu, err := s.menus.Get(ctx, append(scopes, repository.Where("code = ?", code_))...)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errors.WithCode(code.ErrMenuNotFound, "菜单（code：%s）不存在", code_)
+	}
+	if err != nil {
+		return nil, errors.WrapC(err, code.ErrInternalServer, "查询菜单时发生错误")
+	}
+	if err := s.translations.Load(ctx, []*models.Menu{menu}); err != nil {
+		return nil, errors.WrapC(err, code.ErrInternalServer, "查询菜单翻译时发生错误")
+	}
+	return menu, nil
+}
+
+// GetByID 根据ID查询未删除的菜单
+func (s *MenuService) GetByID(ctx context.Context, menuID uint64) (*models.Menu, error) {
+	menu, err := s.menus.Get(ctx, repository.Where("menu_id = ?", menuID))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errors.WithCode(code.ErrMenuNotFound, "菜单（id：%d）不存在", menuID)
+	}
+	if err != nil {
+		return nil, errors.WrapC(err, code.ErrInternalServer, "查询菜单时发生错误")
+	}
+	return menu, nil
+}
+
+// List 按照列表查询参数分页查询菜单
+func (s *MenuService) List(ctx context.Context, req *query.Request) (*query.Result[*models.Menu], error) {
+	result, err := s.menus.Page(ctx, req)
+	if err != nil {
+		return nil, errors.WrapC(err, code.ErrInternalServer, "查询菜单列表时发生错误")
+	}
+	return result, nil
+}
+
+// Tree 查询完整的菜单树，标签和描述使用 locale 对应的翻译
+func (s *MenuService) Tree(ctx context.Context, locale string) ([]*models.MenuTree, error) {
+	menus, err := s.menus.List(ctx)
+	if err != nil {
+		return nil, errors.WrapC(err, code.ErrInternalServer, "查询菜单树时发生错误")
+	}
+	if err := s.localize(ctx, menus, locale); err != nil {
+		return nil, err
+	}
+	return models.BuildMenuTree(menus), nil
+}
+
+// Navigation 查询角色有权访问的已启用菜单，组装为导航信息
+func (s *MenuService) Navigation(ctx context.Context, roleIDs []uint64, locale string) (*models.Navigation, error) {
+	if len(roleIDs) == 0 {
+		return models.BuildNavigation(nil), nil
+	}
+
+	bound, err := s.bindings.MenuIDs(ctx, roleIDs)
+	if err != nil {
+		return nil, errors.WrapC(err, code.ErrInternalServer, "查询用户菜单时发生错误")
+	}
+	if len(bound) == 0 {
+		return models.BuildNavigation(nil), nil
+	}
+	menus, err := s.menus.List(ctx, repository.Where("menu_id in ?", bound), repository.Where("status = ?", true))
+	if err != nil {
+		return nil, errors.WrapC(err, code.ErrInternalServer, "查询用户菜单时发生错误")
+	}
+	if err := s.localize(ctx, menus, locale); err != nil {
+		return nil, err
+	}
+	return models.BuildNavigation(menus), nil
+}
+
+func (s *MenuService) localize(ctx context.Context, menus []*models.Menu, locale string) error {
+	if err := s.translations.Load(ctx, menus); err != nil {
+		return errors.WrapC(err, code.ErrInternalServer, "查询菜单翻译时发生错误")
+	}
+	for _, menu := range menus {
+		menu.Localize(locale)
+	}
+	return nil
+}
+
+// Create 创建菜单及其翻译，创建后 menu 的ID和版本号会被回填
+func (s *MenuService) Create(ctx context.Context, menu *models.Menu) error {
+	return s.unit.Do(ctx, func(ctx context.Context) error {
+		if err := s.validate(ctx, menu, "创建菜单"); err != nil {
+			return err
+		}
+
+		exists, err := s.menus.Exists(ctx, repository.Where("code = ? or label = ?", menu.Code, menu.Label))
+		if err != nil {
+			return errors.WrapC(err, code.ErrInternalServer, "创建菜单时，查询菜单发生错误")
+		}
+		if exists {
+			return errors.WithCode(code.ErrMenuAlreadyExist, "创建菜单时，资源发生冲突")
+		}
+
+		if err := s.menus.Create(ctx, menu); err != nil {
+			return errors.WrapC(err, code.ErrInternalServer, "将菜单数据插入到数据库时发生错误")
+		}
+		if err := s.translations.Save(ctx, menu.MenuID, menu.Translations); err != nil {
+			return errors.WrapC(err, code.ErrInternalServer, "保存菜单翻译时发生错误")
+		}
+		recorder, err := s.newRevisionRecorder(ctx)
+		if err != nil {
+			return err
+		}
+		return recorder.record(ctx, models.EnumMenuRevisionActionCreate, menu.MenuID)
+	})
+}
+
+// Update 使用 menu 替换版本号为 version 的菜单，包括翻译。
+// 菜单已被其他请求修改时返回 ErrPreconditionFailed。
+func (s *MenuService) Update(ctx context.Context, menu *models.Menu, version uint64) error {
+	return s.save(ctx, menu, version, nil, true, "更新菜单")
+}
+
+// Patch 只更新 menu 中 fields 对应的列，fields 为 JSON 字段名，包括 translations
+func (s *MenuService) Patch(ctx context.Context, menu *models.Menu, version uint64, fields []string) error {
+	// translations 不对应菜单表的列，单独保存
+	translated := slices.Contains(fields, "translations")
+	fields = slices.DeleteFunc(slices.Clone(fields), func(field string) bool { return field == "translations" })
+	if translated && menu.Translations == nil {
+		menu.Translations = models.Translations{}
+	}
+
+	columns, err := patch.Columns(menu, fields, readonlyFields...)
+	if err != nil {
+		return errors.WrapC(err, code.ErrValidate, "更新菜单时，%s", err.Error())
+	}
+	return s.save(ctx, menu, version, append(columns, "version"), translated, "更新菜单")
+}
+
+// save 在版本号未变化时写入菜单，columns 为空时写入所有列。translated 为 true 时同时保存翻译。
+func (s *MenuService) save(ctx context.Context, menu *models.Menu, version uint64, columns []string,
+	translated bool, action string) error {
+	return s.unit.Do(ctx, func(ctx context.Context) error {
+		if err := s.validate(ctx, menu, action); err != nil {
+			return err
+		}
+
+		recorder, err := s.newRevisionRecorder(ctx, menu.MenuID)
+		if err != nil {
+			return err
+		}
+
+		// 只有版本号未变化时才更新，避免覆盖其他请求在校验 If-Match 之后做出的修改
+		menu.Version = version + 1
+		updated, err := s.menus.Update(ctx, menu, columns, repository.Where("version = ?", version))
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return errors.WithCode(code.ErrMenuAlreadyExist, "%s时，菜单名称或编码发生冲突", action)
+		}
+		if err != nil {
+			return errors.WrapC(err, code.ErrInternalServer, "%s失败", action)
+		}
+		if updated == 0 {
+			return errors.WithCode(code.ErrPreconditionFailed, "菜单（code：%s）已被其他请求修改", menu.Code)
+		}
+		if translated {
+			if err := s.translations.Save(ctx, menu.MenuID, menu.Translations); err != nil {
+				return errors.WrapC(err, code.ErrInternalServer, "保存菜单翻译时发生错误")
+			}
+		}
+		return recorder.record(ctx, models.EnumMenuRevisionActionUpdate)
+	})
+}
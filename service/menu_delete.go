@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"orca/models"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/repository"
+	"sort"
+)
+
+// MenuDeleteOptions 删除菜单的选项
+type MenuDeleteOptions struct {
+	// Mode 对子菜单的处理方式
+	Mode models.MenuDeleteMode
+	// DryRun 为 true 时只计算删除计划而不执行
+	DryRun bool
+	// Precondition 在要删除的菜单被锁定后调用，返回错误时放弃删除，用于校验 If-Match。预览模式下不会调用。
+	Precondition func(menus []*models.Menu) error
+}
+
+// Delete 删除编码为 codes 的菜单，返回将要执行或已经执行的删除计划。
+// restrict 模式下菜单存在子菜单或角色绑定时返回 ErrMenuHasDependents，同时返回阻止删除的依赖项。
+func (s *MenuService) Delete(ctx context.Context, codes []string, opts MenuDeleteOptions) (*models.MenuDeletePlan,
+	*models.MenuDeleteBlockers, error) {
+	var plan *models.MenuDeletePlan
+	var blockers *models.MenuDeleteBlockers
+	err := s.unit.Do(ctx, func(ctx context.Context) error {
+		menus, err := s.menus.List(ctx, repository.ForUpdate())
+		if err != nil {
+			return errors.WrapC(err, code.ErrInternalServer, "查询菜单时发生错误")
+		}
+
+		byCode := make(map[string]*models.Menu, len(menus))
+		for _, menu := range menus {
+			byCode[menu.Code] = menu
+		}
+		targets := make(map[uint64]*models.Menu, len(codes))
+		for _, code_ := range codes {
+			menu, ok := byCode[code_]
+			if !ok {
+				return errors.WithCode(code.ErrMenuNotFound, "菜单（code：%s）不存在", code_)
+			}
+			targets[menu.MenuID] = menu
+		}
+
+		// 菜单已被锁定，此时校验前置条件不会与其他修改发生竞争
+		if !opts.DryRun && opts.Precondition != nil {
+			current := make([]*models.Menu, 0, len(targets))
+			for _, menu := range targets {
+				current = append(current, menu)
+			}
+			models.SortMenus(current)
+			if err := opts.Precondition(current); err != nil {
+				return err
+			}
+		}
+
+		plan, blockers, err = s.planDelete(ctx, menus, targets, opts.Mode)
+		if err != nil {
+			return err
+		}
+		if blockers != nil {
+			return errors.WithCode(code.ErrMenuHasDependents, "菜单存在子菜单或角色绑定，无法删除")
+		}
+		plan.DryRun = opts.DryRun
+		if opts.DryRun {
+			return nil
+		}
+
+		ids := make([]uint64, 0, len(plan.Deleted)+len(plan.Reparented))
+		for _, code_ := range plan.Deleted {
+			ids = append(ids, byCode[code_].MenuID)
+		}
+		for _, pos := range plan.Reparented {
+			ids = append(ids, byCode[pos.Code].MenuID)
+		}
+		recorder, err := s.newRevisionRecorder(ctx, ids...)
+		if err != nil {
+			return err
+		}
+		if err := s.applyDelete(ctx, byCode, plan); err != nil {
+			return err
+		}
+		return recorder.record(ctx, models.EnumMenuRevisionActionUpdate)
+	})
+	if err != nil {
+		return nil, blockers, err
+	}
+	return plan, nil, nil
+}
+
+// planDelete 根据删除模式计算需要删除、移动和解绑的菜单，restrict 模式下存在依赖时返回 blockers
+func (s *MenuService) planDelete(ctx context.Context, menus []*models.Menu, targets map[uint64]*models.Menu,
+	mode models.MenuDeleteMode) (*models.MenuDeletePlan, *models.MenuDeleteBlockers, error) {
+	byID := make(map[uint64]*models.Menu, len(menus))
+	children := make(map[uint64][]*models.Menu)
+	for _, menu := range menus {
+		byID[menu.MenuID] = menu
+		if menu.ParentID != nil {
+			children[*menu.ParentID] = append(children[*menu.ParentID], menu)
+		}
+	}
+	for _, siblings := range children {
+		models.SortMenus(siblings)
+	}
+
+	plan := &models.MenuDeletePlan{
+		Mode:       mode,
+		Deleted:    make([]string, 0),
+		Reparented: make([]*models.MenuPosition, 0),
+		Suspended:  make([]*models.MenuRoleBinding, 0),
+	}
+
+	deleted := make(map[uint64]*models.Menu, len(targets))
+	for id, menu := range targets {
+		deleted[id] = menu
+	}
+	if mode == models.EnumMenuDeleteModeCascade {
+		var walk func(id uint64)
+		walk = func(id uint64) {
+			for _, child := range children[id] {
+				deleted[child.MenuID] = child
+				walk(child.MenuID)
+			}
+		}
+		for id := range targets {
+			walk(id)
+		}
+	}
+
+	// 不在删除范围内、但父级会被删除的菜单
+	var orphans []*models.Menu
+	for _, menu := range menus {
+		if _, ok := deleted[menu.MenuID]; ok || menu.ParentID == nil {
+			continue
+		}
+		if _, ok := deleted[*menu.ParentID]; ok {
+			orphans = append(orphans, menu)
+		}
+	}
+	models.SortMenus(orphans)
+
+	ids := make([]uint64, 0, len(deleted))
+	for id := range deleted {
+		ids = append(ids, id)
+	}
+	roles, err := s.bindings.RoleCodes(ctx, ids...)
+	if err != nil {
+		return nil, nil, errors.WrapC(err, code.ErrInternalServer, "查询菜单的角色绑定时发生错误")
+	}
+	var bindings []*models.MenuRoleBinding
+	for _, menu := range deleted {
+		for _, role := range roles[menu.MenuID] {
+			bindings = append(bindings, &models.MenuRoleBinding{MenuCode: menu.Code, RoleCode: role})
+		}
+	}
+	sort.Slice(bindings, func(i, j int) bool {
+		if bindings[i].MenuCode != bindings[j].MenuCode {
+			return bindings[i].MenuCode < bindings[j].MenuCode
+		}
+		return bindings[i].RoleCode < bindings[j].RoleCode
+	})
+
+	if mode == models.EnumMenuDeleteModeRestrict && (len(orphans) > 0 || len(bindings) > 0) {
+		blockers := &models.MenuDeleteBlockers{
+			Children: make([]string, 0, len(orphans)),
+			Bindings: make([]*models.MenuRoleBinding, 0, len(bindings)),
+		}
+		for _, orphan := range orphans {
+			blockers.Children = append(blockers.Children, orphan.Code)
+		}
+		blockers.Bindings = append(blockers.Bindings, bindings...)
+		return nil, blockers, nil
+	}
+
+	// reparent 模式下，子菜单移动到最近的未被删除的祖先菜单下，并排在原有同级菜单之后
+	nextOrder := make(map[uint64]uint)
+	for _, orphan := range orphans {
+		var parent *models.Menu
+		for p := byID[*orphan.ParentID]; p != nil; {
+			if _, ok := deleted[p.MenuID]; !ok {
+				parent = p
+				break
+			}
+			if p.ParentID == nil {
+				break
+			}
+			p = byID[*p.ParentID]
+		}
+
+		var group uint64
+		pos := &models.MenuPosition{Code: orphan.Code}
+		if parent != nil {
+			group = parent.MenuID
+			pos.ParentCode = &parent.Code
+		} else if orphan.Type == models.EnumMenuTypeButton {
+			return nil, nil, errors.WithCode(code.ErrMenuParentInvalid, "按钮（code：%s）没有可以移动到的父级菜单", orphan.Code)
+		}
+		if _, ok := nextOrder[group]; !ok {
+			for _, menu := range menus {
+				_, gone := deleted[menu.MenuID]
+				if !gone && sameParentGroup(menu.ParentID, group) && menu.Order >= nextOrder[group] {
+					nextOrder[group] = menu.Order + 1
+				}
+			}
+		}
+		pos.Order = nextOrder[group]
+		nextOrder[group]++
+		plan.Reparented = append(plan.Reparented, pos)
+	}
+
+	for _, menu := range sortedMenus(deleted) {
+		plan.Deleted = append(plan.Deleted, menu.Code)
+	}
+	plan.Suspended = append(plan.Suspended, bindings...)
+	return plan, nil, nil
+}
+
+// applyDelete 执行删除计划
+func (s *MenuService) applyDelete(ctx context.Context, byCode map[string]*models.Menu, plan *models.MenuDeletePlan) error {
+	for _, pos := range plan.Reparented {
+		var parentID *uint64
+		if pos.ParentCode != nil {
+			parentID = &byCode[*pos.ParentCode].MenuID
+		}
+		// 菜单已被锁定，版本号不会被其他请求修改
+		values := map[string]any{"parent_id": parentID, "order": pos.Order, "version": byCode[pos.Code].Version + 1}
+		if _, err := s.menus.UpdateColumns(ctx, values, repository.Where("menu_id = ?", byCode[pos.Code].MenuID)); err != nil {
+			return errors.WrapC(err, code.ErrInternalServer, "移动子菜单（code：%s）时发生错误", pos.Code)
+		}
+	}
+
+	ids := make([]uint64, 0, len(plan.Deleted))
+	for _, code_ := range plan.Deleted {
+		ids = append(ids, byCode[code_].MenuID)
+	}
+	// 软删除时保留角色绑定，恢复菜单后绑定随之恢复，清理菜单时由外键级联删除
+	if _, err := s.menus.Delete(ctx, repository.Where("menu_id in ?", ids)); err != nil {
+		return errors.WrapC(err, code.ErrInternalServer, "删除菜单时，发生错误")
+	}
+	return nil
+}
+
+// sortedMenus 返回按 models.SortMenus 排序的菜单
+func sortedMenus(menus map[uint64]*models.Menu) []*models.Menu {
+	sorted := make([]*models.Menu, 0, len(menus))
+	for _, menu := range menus {
+		sorted = append(sorted, menu)
+	}
+	models.SortMenus(sorted)
+	return sorted
+}
+
+func sameParentGroup(parentID *uint64, group uint64) bool {
+	if parentID == nil {
+		return group == 0
+	}
+	return *parentID == group
+}
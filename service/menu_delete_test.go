@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"orca/models"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+)
+
+// newDeleteFixture 创建 system（users（user:create）、roles）和 reports 两个目录，users 绑定了 admin 角色
+func newDeleteFixture(t *testing.T) *fakeMenuService {
+	s := newFakeMenuService()
+	ctx := context.Background()
+	admin := &models.Role{Code: "admin", Label: "admin", Status: true}
+	require.NoError(t, s.roles.Create(ctx, admin))
+	system, reports := directory("system", 0), directory("reports", 1)
+	s.seed(system, reports)
+	users, roles := menu("users", system, 0), menu("roles", system, 1)
+	s.seed(users, roles)
+	s.seed(button("user:create", users, 0))
+	require.NoError(t, s.bindings.Replace(ctx, users.MenuID, []uint64{admin.RoleID}))
+	return s
+}
+
+func TestDeleteRestrict(t *testing.T) {
+	s := newDeleteFixture(t)
+	ctx := context.Background()
+	restrict := MenuDeleteOptions{Mode: models.EnumMenuDeleteModeRestrict}
+
+	_, blockers, err := s.Delete(ctx, []string{"system"}, restrict)
+	assertCode(t, err, code.ErrMenuHasDependents)
+	assert.Equal(t, []string{"users", "roles"}, blockers.Children)
+	assert.Empty(t, blockers.Bindings)
+
+	_, blockers, err = s.Delete(ctx, []string{"users", "user:create"}, restrict)
+	assertCode(t, err, code.ErrMenuHasDependents)
+	assert.Empty(t, blockers.Children)
+	assert.Equal(t, []*models.MenuRoleBinding{{MenuCode: "users", RoleCode: "admin"}}, blockers.Bindings)
+	assert.False(t, s.menu("users").DeletedAt.Deleted())
+
+	plan, _, err := s.Delete(ctx, []string{"reports"}, restrict)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reports"}, plan.Deleted)
+	assert.True(t, s.menu("reports").DeletedAt.Deleted())
+	assert.Equal(t, []models.MenuRevisionAction{models.EnumMenuRevisionActionDelete}, s.actions("reports"))
+
+	_, _, err = s.Delete(ctx, []string{"reports"}, restrict)
+	assertCode(t, err, code.ErrMenuNotFound)
+}
+
+func TestDeleteCascade(t *testing.T) {
+	s := newDeleteFixture(t)
+	ctx := context.Background()
+
+	opts := MenuDeleteOptions{Mode: models.EnumMenuDeleteModeCascade, DryRun: true}
+	plan, _, err := s.Delete(ctx, []string{"system"}, opts)
+	require.NoError(t, err)
+	assert.True(t, plan.DryRun)
+	assert.Equal(t, []string{"system", "users", "user:create", "roles"}, plan.Deleted)
+	assert.Empty(t, plan.Reparented)
+	assert.Equal(t, []*models.MenuRoleBinding{{MenuCode: "users", RoleCode: "admin"}}, plan.Suspended)
+	// 预览不修改菜单
+	assert.False(t, s.menu("users").DeletedAt.Deleted())
+	assert.Empty(t, s.actions("users"))
+
+	opts.DryRun = false
+	opts.Precondition = func(menus []*models.Menu) error {
+		return errors.WithCode(code.ErrPreconditionFailed, "菜单已被修改")
+	}
+	_, _, err = s.Delete(ctx, []string{"system"}, opts)
+	assertCode(t, err, code.ErrPreconditionFailed)
+	assert.False(t, s.menu("system").DeletedAt.Deleted())
+
+	opts.Precondition = nil
+	_, _, err = s.Delete(ctx, []string{"system"}, opts)
+	require.NoError(t, err)
+	for _, code_ := range []string{"system", "users", "roles", "user:create"} {
+		assert.True(t, s.menu(code_).DeletedAt.Deleted(), code_)
+		assert.Equal(t, []models.MenuRevisionAction{models.EnumMenuRevisionActionDelete}, s.actions(code_), code_)
+	}
+	assert.False(t, s.menu("reports").DeletedAt.Deleted())
+	// 角色绑定被保留，菜单恢复后随之恢复
+	assert.Len(t, s.bindings.bound[s.menu("users").MenuID], 1)
+}
+
+func TestDeleteReparent(t *testing.T) {
+	s := newDeleteFixture(t)
+	ctx := context.Background()
+
+	plan, _, err := s.Delete(ctx, []string{"system"}, MenuDeleteOptions{Mode: models.EnumMenuDeleteModeReparent})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"system"}, plan.Deleted)
+	// 没有未被删除的祖先菜单时移动到根节点下，排在 reports 之后
+	assert.Equal(t, []*models.MenuPosition{{Code: "users", Order: 2}, {Code: "roles", Order: 3}}, plan.Reparented)
+
+	users := s.menu("users")
+	assert.Nil(t, users.ParentID)
+	assert.Equal(t, uint(2), users.Order)
+	assert.Equal(t, uint64(2), users.Version)
+	assert.Equal(t, []models.MenuRevisionAction{models.EnumMenuRevisionActionUpdate}, s.actions("users"))
+	assert.Equal(t, &users.MenuID, s.menu("user:create").ParentID)
+
+	// 按钮不能移动到根节点下
+	_, _, err = s.Delete(ctx, []string{"users"}, MenuDeleteOptions{Mode: models.EnumMenuDeleteModeReparent})
+	assertCode(t, err, code.ErrMenuParentInvalid)
+	assert.False(t, s.menu("users").DeletedAt.Deleted())
+}
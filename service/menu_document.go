@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"orca/models"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/repository"
+	"orca/pkg/validation"
+	"sort"
+)
+
+// errDryRun 用于在预览模式下回滚导入事务
+var errDryRun = errors.New("dry run")
+
+// Export 导出完整的菜单树及其角色绑定和翻译
+func (s *MenuService) Export(ctx context.Context) (*models.MenuDocument, error) {
+	menus, err := s.menus.List(ctx)
+	if err != nil {
+		return nil, errors.WrapC(err, code.ErrInternalServer, "导出菜单时，查询菜单发生错误")
+	}
+	if err := s.translations.Load(ctx, menus); err != nil {
+		return nil, errors.WrapC(err, code.ErrInternalServer, "导出菜单时，查询菜单翻译发生错误")
+	}
+	roles, err := s.bindings.RoleCodes(ctx)
+	if err != nil {
+		return nil, errors.WrapC(err, code.ErrInternalServer, "导出菜单时，查询角色绑定发生错误")
+	}
+	return buildDocument(menus, roles), nil
+}
+
+// Import 导入菜单文档，按 Code 匹配已有菜单进行创建或更新。
+// deleteMissing 为 true 时删除文档中不存在的菜单；dryRun 为 true 时在事务中执行导入并回滚，只返回变更结果。
+// 文档校验失败时返回的错误包装了以菜单编码为键的 validation.Errors。
+func (s *MenuService) Import(ctx context.Context, doc *models.MenuDocument, deleteMissing, dryRun bool) (*models.MenuImportResult, error) {
+	if doc.Version != models.MenuDocumentVersion {
+		return nil, errors.WithCode(code.ErrValidate, "不支持的文档版本：%d", doc.Version)
+	}
+
+	result := &models.MenuImportResult{
+		DryRun:  dryRun,
+		Created: make([]string, 0),
+		Updated: make([]*models.MenuImportChange, 0),
+		Deleted: make([]string, 0),
+	}
+	err := s.unit.Do(ctx, func(ctx context.Context) error {
+		if dryRun {
+			if err := s.importDocument(ctx, doc, deleteMissing, result); err != nil {
+				return err
+			}
+			return errDryRun
+		}
+
+		before, err := s.menuIDs(ctx, repository.ForUpdate())
+		if err != nil {
+			return errors.WrapC(err, code.ErrInternalServer, "导入菜单时，查询菜单发生错误")
+		}
+		recorder, err := s.newRevisionRecorder(ctx, before...)
+		if err != nil {
+			return err
+		}
+		if err := s.importDocument(ctx, doc, deleteMissing, result); err != nil {
+			return err
+		}
+
+		after, err := s.menuIDs(ctx)
+		if err != nil {
+			return errors.WrapC(err, code.ErrInternalServer, "导入菜单时，查询菜单发生错误")
+		}
+		return recorder.record(ctx, models.EnumMenuRevisionActionUpdate, after...)
+	})
+
+	if errors.Is(err, errDryRun) {
+		return result, nil
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, errors.WithCode(code.ErrMenuAlreadyExist, "导入菜单时，菜单名称或编码发生冲突")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// menuIDs 查询所有未删除菜单的ID
+func (s *MenuService) menuIDs(ctx context.Context, scopes ...repository.Scope) ([]uint64, error) {
+	menus, err := s.menus.List(ctx, append(scopes, repository.Select("menu_id"))...)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint64, 0, len(menus))
+	for _, menu := range menus {
+		ids = append(ids, menu.MenuID)
+	}
+	return ids, nil
+}
+
+// importDocument 在事务中导入文档并填充 result
+func (s *MenuService) importDocument(ctx context.Context, doc *models.MenuDocument, deleteMissing bool,
+	result *models.MenuImportResult) error {
+	items := doc.Flatten()
+	inDoc := make(map[string]*models.MenuDocumentItem, len(items))
+	var roleCodes []string
+	for _, item := range items {
+		if _, ok := inDoc[item.Code]; ok {
+			return errors.WithCode(code.ErrValidate, "菜单（code：%s）在文档中重复出现", item.Code)
+		}
+		inDoc[item.Code] = item
+		if item.Roles != nil {
+			sort.Strings(item.Roles)
+			roleCodes = append(roleCodes, item.Roles...)
+		}
+	}
+
+	menus, err := s.menus.List(ctx, repository.ForUpdate())
+	if err != nil {
+		return errors.WrapC(err, code.ErrInternalServer, "导入菜单时，查询菜单发生错误")
+	}
+	if err := s.translations.Load(ctx, menus); err != nil {
+		return errors.WrapC(err, code.ErrInternalServer, "导入菜单时，查询菜单翻译发生错误")
+	}
+	byCode := make(map[string]*models.Menu, len(menus))
+	byID := make(map[uint64]*models.Menu, len(menus))
+	for _, menu := range menus {
+		byCode[menu.Code] = menu
+		byID[menu.MenuID] = menu
+	}
+
+	roleIDs, err := s.roles.Resolve(ctx, roleCodes)
+	if err != nil {
+		return err
+	}
+
+	bindings, err := s.bindings.RoleCodes(ctx)
+	if err != nil {
+		return errors.WrapC(err, code.ErrInternalServer, "导入菜单时，查询角色绑定发生错误")
+	}
+
+	// 写入之前先校验所有节点，父级菜单可以是文档中的节点，也可以是数据库中已有的菜单
+	typeOf := func(code_ string) (models.MenuType, bool) {
+		if item, ok := inDoc[code_]; ok {
+			return item.Type, true
+		}
+		if menu, ok := byCode[code_]; ok && !deleteMissing {
+			return menu.Type, true
+		}
+		return "", false
+	}
+	fieldErrors := validation.Errors{}
+	for _, item := range items {
+		var menu models.Menu
+		item.ApplyTo(&menu)
+		if item.ParentCode != nil {
+			// 父级菜单的ID在写入时才能确定，这里只用于满足按钮必须有父级菜单的校验
+			menu.ParentID = new(uint64)
+		}
+		if err := menu.Validate(); err != nil {
+			fieldErrors[item.Code] = err
+			continue
+		}
+		if item.ParentCode != nil {
+			if parentType, ok := typeOf(*item.ParentCode); !ok {
+				fieldErrors[item.Code] = errParentNotFound
+			} else if parentType == models.EnumMenuTypeButton {
+				fieldErrors[item.Code] = errParentIsButton
+			}
+		}
+		for _, role := range item.Roles {
+			if _, ok := roleIDs[role]; !ok {
+				fieldErrors[item.Code] = validation.NewError("validation_menu_role_not_found", "角色（code："+role+"）不存在")
+			}
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return errors.WrapC(fieldErrors, code.ErrValidate, "导入菜单时，字段验证错误")
+	}
+
+	for _, item := range items {
+		var parentID *uint64
+		if item.ParentCode != nil {
+			parentID = &byCode[*item.ParentCode].MenuID
+		}
+
+		menu, exists := byCode[item.Code]
+		if !exists {
+			menu = &models.Menu{}
+			item.ApplyTo(menu)
+			menu.ParentID = parentID
+			if err := s.menus.Create(ctx, menu); err != nil {
+				return errors.WrapC(err, code.ErrInternalServer, "创建菜单（code：%s）时发生错误", item.Code)
+			}
+			byCode[menu.Code] = menu
+			byID[menu.MenuID] = menu
+			result.Created = append(result.Created, item.Code)
+			if err := s.bindRoles(ctx, menu.MenuID, item.Roles, roleIDs); err != nil {
+				return err
+			}
+			if err := s.translations.Save(ctx, menu.MenuID, item.Translations); err != nil {
+				return errors.WrapC(err, code.ErrInternalServer, "保存菜单（code：%s）的翻译时发生错误", item.Code)
+			}
+			continue
+		}
+
+		var parentCode *string
+		if menu.ParentID != nil {
+			if parent, ok := byID[*menu.ParentID]; ok {
+				parentCode = &parent.Code
+			}
+		}
+		current := models.NewMenuDocumentItem(menu, parentCode, rolesOf(bindings, menu.MenuID))
+		changes := current.Diff(item)
+		if len(changes) == 0 {
+			result.Unchanged++
+			continue
+		}
+		result.Updated = append(result.Updated, &models.MenuImportChange{Code: item.Code, Changes: changes})
+
+		item.ApplyTo(menu)
+		menu.ParentID = parentID
+		menu.Version++
+		if _, err := s.menus.Update(ctx, menu, nil); err != nil {
+			return errors.WrapC(err, code.ErrInternalServer, "更新菜单（code：%s）时发生错误", item.Code)
+		}
+		if _, ok := changes["roles"]; ok {
+			if err := s.bindRoles(ctx, menu.MenuID, item.Roles, roleIDs); err != nil {
+				return err
+			}
+		}
+		if _, ok := changes["translations"]; ok {
+			if err := s.translations.Save(ctx, menu.MenuID, item.Translations); err != nil {
+				return errors.WrapC(err, code.ErrInternalServer, "保存菜单（code：%s）的翻译时发生错误", item.Code)
+			}
+		}
+	}
+
+	if deleteMissing {
+		var ids []uint64
+		for _, menu := range menus {
+			if _, ok := inDoc[menu.Code]; !ok {
+				ids = append(ids, menu.MenuID)
+				result.Deleted = append(result.Deleted, menu.Code)
+			}
+		}
+		if len(ids) > 0 {
+			if _, err := s.menus.Delete(ctx, repository.Where("menu_id in ?", ids)); err != nil {
+				return errors.WrapC(err, code.ErrInternalServer, "导入菜单时，删除菜单发生错误")
+			}
+		}
+	}
+
+	// 文档中修改了菜单类型时，已有的子菜单可能会挂在按钮下
+	for _, menu := range byCode {
+		if menu.ParentID == nil || (deleteMissing && inDoc[menu.Code] == nil) {
+			continue
+		}
+		if parent, ok := byID[*menu.ParentID]; ok && parent.Type == models.EnumMenuTypeButton {
+			return errors.WithCode(code.ErrMenuParentInvalid, "导入后菜单（code：%s）的父级菜单是按钮", menu.Code)
+		}
+	}
+
+	sort.Strings(result.Created)
+	sort.Strings(result.Deleted)
+	sort.Slice(result.Updated, func(i, j int) bool { return result.Updated[i].Code < result.Updated[j].Code })
+	return nil
+}
+
+// bindRoles 将菜单的角色绑定替换为 roles，roles 为 nil 时不做修改
+func (s *MenuService) bindRoles(ctx context.Context, menuID uint64, roles []string, roleIDs map[string]uint64) error {
+	if roles == nil {
+		return nil
+	}
+	ids := make([]uint64, 0, len(roles))
+	for _, role := range roles {
+		ids = append(ids, roleIDs[role])
+	}
+	if err := s.bindings.Replace(ctx, menuID, ids); err != nil {
+		return errors.WrapC(err, code.ErrInternalServer, "更新菜单的角色绑定时发生错误")
+	}
+	return nil
+}
+
+// rolesOf 返回菜单绑定的角色编码，没有绑定时返回空列表而不是 nil
+func rolesOf(roles map[uint64][]string, menuID uint64) []string {
+	if codes, ok := roles[menuID]; ok {
+		return codes
+	}
+	return make([]string, 0)
+}
+
+// buildDocument 将菜单树和角色绑定转换为导出文档
+func buildDocument(menus []*models.Menu, roles map[uint64][]string) *models.MenuDocument {
+	var convert func(nodes []*models.MenuTree, parentCode *string) []*models.MenuDocumentItem
+	convert = func(nodes []*models.MenuTree, parentCode *string) []*models.MenuDocumentItem {
+		items := make([]*models.MenuDocumentItem, 0, len(nodes))
+		for _, node := range nodes {
+			item := models.NewMenuDocumentItem(node.Menu, parentCode, rolesOf(roles, node.MenuID))
+			item.Children = convert(node.Children, &node.Code)
+			items = append(items, item)
+		}
+		return items
+	}
+	return &models.MenuDocument{
+		Version: models.MenuDocumentVersion,
+		Menus:   convert(models.BuildMenuTree(menus), nil),
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"orca/models"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/validation"
+)
+
+func TestImport(t *testing.T) {
+	s := newFakeMenuService()
+	ctx := context.Background()
+	admin := &models.Role{Code: "admin", Label: "admin", Status: true}
+	require.NoError(t, s.roles.Create(ctx, admin))
+	system, legacy := directory("system", 0), directory("legacy", 1)
+	s.seed(system, legacy)
+	users := menu("users", system, 0)
+	s.seed(users)
+	require.NoError(t, s.bindings.Replace(ctx, users.MenuID, []uint64{admin.RoleID}))
+
+	root := models.NewMenuDocumentItem(system, nil, nil)
+	root.Label = "系统管理"
+	roles := models.NewMenuDocumentItem(menu("roles", nil, 1), nil, []string{"admin"})
+	roles.Translations = models.Translations{"en": {Label: "Roles"}}
+	root.Children = []*models.MenuDocumentItem{models.NewMenuDocumentItem(users, nil, []string{"admin"}), roles}
+	doc := &models.MenuDocument{Version: models.MenuDocumentVersion, Menus: []*models.MenuDocumentItem{root}}
+
+	expected := &models.MenuImportResult{
+		Created:   []string{"roles"},
+		Updated:   []*models.MenuImportChange{{Code: "system", Changes: map[string]*models.FieldChange{"label": {From: "system", To: "系统管理"}}}},
+		Deleted:   []string{"legacy"},
+		Unchanged: 1,
+	}
+
+	// 预览在事务中执行后回滚
+	result, err := s.Import(ctx, doc, true, true)
+	require.NoError(t, err)
+	expected.DryRun = true
+	assert.Equal(t, expected, result)
+	assert.Nil(t, s.menu("roles"))
+	assert.False(t, s.menu("legacy").DeletedAt.Deleted())
+	assert.Equal(t, "system", s.menu("system").Label)
+	assert.Empty(t, s.revisions.rows)
+
+	result, err = s.Import(ctx, doc, true, false)
+	require.NoError(t, err)
+	expected.DryRun = false
+	assert.Equal(t, expected, result)
+
+	created, err := s.Get(ctx, "roles")
+	require.NoError(t, err)
+	assert.Equal(t, &system.MenuID, created.ParentID)
+	assert.Equal(t, "Roles", created.Translations["en"].Label)
+	assert.Equal(t, []uint64{admin.RoleID}, s.bindings.bound[created.MenuID])
+	assert.Equal(t, "系统管理", s.menu("system").Label)
+	assert.Equal(t, uint64(2), s.menu("system").Version)
+	assert.True(t, s.menu("legacy").DeletedAt.Deleted())
+	assert.Equal(t, []models.MenuRevisionAction{models.EnumMenuRevisionActionCreate}, s.actions("roles"))
+	assert.Equal(t, []models.MenuRevisionAction{models.EnumMenuRevisionActionUpdate}, s.actions("system"))
+	assert.Equal(t, []models.MenuRevisionAction{models.EnumMenuRevisionActionDelete}, s.actions("legacy"))
+	assert.Empty(t, s.actions("users"))
+
+	// 再次导入时没有变化
+	result, err = s.Import(ctx, doc, true, false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Unchanged)
+	assert.Empty(t, result.Created)
+	assert.Empty(t, result.Updated)
+	assert.Empty(t, result.Deleted)
+}
+
+func TestImportRejectsInvalidDocuments(t *testing.T) {
+	s := newFakeMenuService()
+	ctx := context.Background()
+	system := directory("system", 0)
+	s.seed(system)
+
+	_, err := s.Import(ctx, &models.MenuDocument{Version: models.MenuDocumentVersion + 1}, false, false)
+	assertCode(t, err, code.ErrValidate)
+
+	save := models.NewMenuDocumentItem(button("save", system, 0), nil, nil)
+	orphan := models.NewMenuDocumentItem(menu("orphan", nil, 0), nil, nil)
+	orphan.Children = []*models.MenuDocumentItem{models.NewMenuDocumentItem(menu("child", nil, 0), nil, nil)}
+	orphan.Children[0].Roles = []string{"missing"}
+	underButton := models.NewMenuDocumentItem(menu("users", nil, 1), nil, nil)
+	save.Children = []*models.MenuDocumentItem{underButton}
+	doc := &models.MenuDocument{
+		Version: models.MenuDocumentVersion,
+		Menus:   []*models.MenuDocumentItem{save, orphan},
+	}
+	_, err = s.Import(ctx, doc, false, false)
+	assertCode(t, err, code.ErrValidate)
+	var errs validation.Errors
+	require.True(t, errors.As(err, &errs))
+	assert.Contains(t, errs, "save")
+	assert.Contains(t, errs, "users")
+	assert.Contains(t, errs, "child")
+	assert.NotContains(t, errs, "orphan")
+	assert.Nil(t, s.menu("orphan"))
+
+	// 文档中重复的编码
+	doc.Menus = []*models.MenuDocumentItem{orphan, models.NewMenuDocumentItem(menu("orphan", nil, 1), nil, nil)}
+	orphan.Children = nil
+	_, err = s.Import(ctx, doc, false, false)
+	assertCode(t, err, code.ErrValidate)
+}
+
+func TestImportKeepsMissingMenus(t *testing.T) {
+	s := newFakeMenuService()
+	ctx := context.Background()
+	system, legacy := directory("system", 0), directory("legacy", 1)
+	s.seed(system, legacy)
+	users := menu("users", legacy, 0)
+	s.seed(users)
+
+	// deleteMissing 为 false 时文档中不存在的 system 和 users 保持不变
+	item := models.NewMenuDocumentItem(menu("roles", nil, 1), nil, nil)
+	root := models.NewMenuDocumentItem(legacy, nil, nil)
+	root.Children = []*models.MenuDocumentItem{item}
+	doc := &models.MenuDocument{Version: models.MenuDocumentVersion, Menus: []*models.MenuDocumentItem{root}}
+	result, err := s.Import(ctx, doc, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"roles"}, result.Created)
+	assert.Empty(t, result.Deleted)
+	assert.Equal(t, 1, result.Unchanged)
+	assert.False(t, s.menu("system").DeletedAt.Deleted())
+	assert.False(t, s.menu("users").DeletedAt.Deleted())
+
+	// 只有 deleteMissing 为 true 时才删除文档中不存在的菜单
+	daily := models.NewMenuDocumentItem(menu("daily", nil, 0), nil, nil)
+	doc.Menus = []*models.MenuDocumentItem{root, models.NewMenuDocumentItem(system, nil, nil)}
+	doc.Menus[1].Children = []*models.MenuDocumentItem{daily}
+	result, err = s.Import(ctx, doc, true, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"daily"}, result.Created)
+	assert.Equal(t, []string{"users"}, result.Deleted)
+	assert.False(t, s.menu("users").DeletedAt.Deleted())
+
+	result, err = s.Import(ctx, doc, true, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"users"}, result.Deleted)
+	assert.True(t, s.menu("users").DeletedAt.Deleted())
+	assert.False(t, s.menu("system").DeletedAt.Deleted())
+}
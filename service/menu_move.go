@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"orca/models"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/repository"
+	"sort"
+)
+
+// Move 批量调整菜单的父级和排序，受影响的同级菜单会重新编号，返回移动后的菜单树
+func (s *MenuService) Move(ctx context.Context, positions []*models.MenuPosition) ([]*models.MenuTree, error) {
+	var tree []*models.MenuTree
+	err := s.unit.Do(ctx, func(ctx context.Context) error {
+		menus, err := s.menus.List(ctx, repository.ForUpdate())
+		if err != nil {
+			return errors.WrapC(err, code.ErrInternalServer, "查询菜单时发生错误")
+		}
+
+		changed, err := applyPositions(menus, positions)
+		if err != nil {
+			return err
+		}
+
+		ids := make([]uint64, 0, len(changed))
+		for _, menu := range changed {
+			ids = append(ids, menu.MenuID)
+		}
+		recorder, err := s.newRevisionRecorder(ctx, ids...)
+		if err != nil {
+			return err
+		}
+
+		for _, menu := range changed {
+			values := map[string]any{"parent_id": menu.ParentID, "order": menu.Order, "version": menu.Version}
+			if _, err := s.menus.UpdateColumns(ctx, values, repository.Where("menu_id = ?", menu.MenuID)); err != nil {
+				return errors.WrapC(err, code.ErrInternalServer, "更新菜单（code：%s）位置时发生错误", menu.Code)
+			}
+		}
+		if err := recorder.record(ctx, models.EnumMenuRevisionActionUpdate); err != nil {
+			return err
+		}
+		tree = models.BuildMenuTree(menus)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// applyPositions 将新的位置应用到 menus 上，并对受影响的同级菜单重新编号，返回父级或排序发生变化的菜单
+func applyPositions(menus []*models.Menu, positions []*models.MenuPosition) ([]*models.Menu, error) {
+	byCode := make(map[string]*models.Menu, len(menus))
+	parents := make(map[uint64]*uint64, len(menus))
+	for _, menu := range menus {
+		byCode[menu.Code] = menu
+		parents[menu.MenuID] = menu.ParentID
+	}
+
+	// 同级菜单以父级菜单ID分组，根节点使用 0
+	groupOf := func(parentID *uint64) uint64 {
+		if parentID == nil {
+			return 0
+		}
+		return *parentID
+	}
+
+	moved := make(map[uint64]uint, len(positions))
+	affected := make(map[uint64]struct{})
+	for _, pos := range positions {
+		menu, ok := byCode[pos.Code]
+		if !ok {
+			return nil, errors.WithCode(code.ErrMenuNotFound, "菜单（code：%s）不存在", pos.Code)
+		}
+		if _, ok := moved[menu.MenuID]; ok {
+			return nil, errors.WithCode(code.ErrValidate, "菜单（code：%s）重复出现", pos.Code)
+		}
+
+		var parentID *uint64
+		if pos.ParentCode != nil {
+			parent, ok := byCode[*pos.ParentCode]
+			if !ok {
+				return nil, errors.WithCode(code.ErrMenuParentInvalid, "父级菜单（code：%s）不存在", *pos.ParentCode)
+			}
+			if parent.Type == models.EnumMenuTypeButton {
+				return nil, errors.WithCode(code.ErrMenuParentInvalid, "按钮（code：%s）下不能包含子菜单", parent.Code)
+			}
+			parentID = &parent.MenuID
+		} else if menu.Type == models.EnumMenuTypeButton {
+			return nil, errors.WithCode(code.ErrMenuParentInvalid, "按钮（code：%s）必须有父级菜单", menu.Code)
+		}
+
+		affected[groupOf(parents[menu.MenuID])] = struct{}{}
+		affected[groupOf(parentID)] = struct{}{}
+		parents[menu.MenuID] = parentID
+		moved[menu.MenuID] = pos.Order
+	}
+
+	if id, ok := models.FindMenuCycle(parents); ok {
+		return nil, errors.WithCode(code.ErrMenuCycle, "移动后菜单（id：%d）的层级存在循环", id)
+	}
+
+	var changed []*models.Menu
+	for group := range affected {
+		var siblings []*models.Menu
+		for _, menu := range menus {
+			if groupOf(parents[menu.MenuID]) == group {
+				siblings = append(siblings, menu)
+			}
+		}
+
+		// 被移动的菜单使用请求中的排序，排序相同时被移动的菜单排在前面
+		orderOf := func(menu *models.Menu) uint {
+			if order, ok := moved[menu.MenuID]; ok {
+				return order
+			}
+			return menu.Order
+		}
+		sort.SliceStable(siblings, func(i, j int) bool {
+			oi, oj := orderOf(siblings[i]), orderOf(siblings[j])
+			if oi != oj {
+				return oi < oj
+			}
+			_, mi := moved[siblings[i].MenuID]
+			_, mj := moved[siblings[j].MenuID]
+			if mi != mj {
+				return mi
+			}
+			return siblings[i].MenuID < siblings[j].MenuID
+		})
+
+		for i, menu := range siblings {
+			parentID := parents[menu.MenuID]
+			if menu.Order == uint(i) && sameParent(menu.ParentID, parentID) {
+				continue
+			}
+			menu.Order = uint(i)
+			menu.ParentID = parentID
+			menu.Version++
+			changed = append(changed, menu)
+		}
+	}
+	return changed, nil
+}
+
+func sameParent(a, b *uint64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
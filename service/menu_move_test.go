@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"orca/models"
+	"orca/pkg/code"
+)
+
+func TestMove(t *testing.T) {
+	s := newFakeMenuService()
+	ctx := context.Background()
+	system, reports := directory("system", 0), directory("reports", 1)
+	s.seed(system, reports)
+	users, roles, daily := menu("users", system, 0), menu("roles", system, 1), menu("daily", reports, 0)
+	s.seed(users, roles, daily)
+	create := button("user:create", users, 0)
+	s.seed(create)
+
+	// roles 移动到 reports 的最前面，daily 顺延
+	tree, err := s.Move(ctx, []*models.MenuPosition{{Code: "roles", ParentCode: ptr("reports"), Order: 0}})
+	require.NoError(t, err)
+	require.Len(t, tree, 2)
+	require.Len(t, tree[1].Children, 2)
+	assert.Equal(t, "roles", tree[1].Children[0].Code)
+	assert.Equal(t, "daily", tree[1].Children[1].Code)
+
+	moved := s.menu("roles")
+	assert.Equal(t, &reports.MenuID, moved.ParentID)
+	assert.Equal(t, uint(0), moved.Order)
+	assert.Equal(t, uint64(2), moved.Version)
+	assert.Equal(t, uint(1), s.menu("daily").Order)
+	assert.Equal(t, uint64(2), s.menu("daily").Version)
+	// 未受影响的菜单不变，也不产生修订
+	assert.Equal(t, uint64(1), s.menu("users").Version)
+	assert.Empty(t, s.actions("users"))
+	assert.Equal(t, []models.MenuRevisionAction{models.EnumMenuRevisionActionUpdate}, s.actions("roles"))
+
+	revisions, err := s.revisions.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, &models.FieldChange{From: "system", To: "reports"}, revisions[0].Changes["parentCode"])
+}
+
+func TestMoveRejectsInvalidPositions(t *testing.T) {
+	s := newFakeMenuService()
+	ctx := context.Background()
+	system := directory("system", 0)
+	s.seed(system)
+	users := menu("users", system, 0)
+	s.seed(users)
+	s.seed(button("user:create", users, 0))
+
+	tests := map[string]struct {
+		positions []*models.MenuPosition
+		code      code.Code
+	}{
+		"cycle":         {[]*models.MenuPosition{{Code: "system", ParentCode: ptr("users")}}, code.ErrMenuCycle},
+		"under button":  {[]*models.MenuPosition{{Code: "users", ParentCode: ptr("user:create")}}, code.ErrMenuParentInvalid},
+		"button root":   {[]*models.MenuPosition{{Code: "user:create"}}, code.ErrMenuParentInvalid},
+		"not found":     {[]*models.MenuPosition{{Code: "roles"}}, code.ErrMenuNotFound},
+		"parent absent": {[]*models.MenuPosition{{Code: "users", ParentCode: ptr("roles")}}, code.ErrMenuParentInvalid},
+		"duplicated":    {[]*models.MenuPosition{{Code: "users"}, {Code: "users", Order: 1}}, code.ErrValidate},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := s.Move(ctx, tt.positions)
+			assertCode(t, err, tt.code)
+			assert.Equal(t, &system.MenuID, s.menu("users").ParentID)
+			assert.Equal(t, uint64(1), s.menu("users").Version)
+			assert.Nil(t, s.menu("system").ParentID)
+		})
+	}
+}
+
+func TestApplyPositions(t *testing.T) {
+	// 根节点下的 a、b、c 和 a 下的 a1、a2，a2 下的按钮 save
+	tree := func() []*models.Menu {
+		menus := []*models.Menu{
+			{MenuID: 1, Code: "a", Type: models.EnumMenuTypeDirectory, Order: 0},
+			{MenuID: 2, Code: "b", Type: models.EnumMenuTypeDirectory, Order: 1},
+			{MenuID: 3, Code: "c", Type: models.EnumMenuTypeDirectory, Order: 2},
+			{MenuID: 4, Code: "a1", Type: models.EnumMenuTypeMenu, ParentID: ptr(uint64(1)), Order: 0},
+			{MenuID: 5, Code: "a2", Type: models.EnumMenuTypeMenu, ParentID: ptr(uint64(1)), Order: 1},
+			{MenuID: 6, Code: "save", Type: models.EnumMenuTypeButton, ParentID: ptr(uint64(5)), Order: 0},
+		}
+		for _, menu := range menus {
+			menu.Version = 1
+		}
+		return menus
+	}
+
+	tests := map[string]struct {
+		positions []*models.MenuPosition
+		// expected 移动后每个菜单的父级编码和排序，格式为 父级/排序
+		expected map[string]string
+		changed  []string
+		code     code.Code
+	}{
+		"reorder siblings": {
+			positions: []*models.MenuPosition{{Code: "c", Order: 0}},
+			expected:  map[string]string{"a": "/1", "b": "/2", "c": "/0", "a1": "a/0", "a2": "a/1"},
+			changed:   []string{"a", "b", "c"},
+		},
+		"same order puts moved first": {
+			positions: []*models.MenuPosition{{Code: "a2", ParentCode: ptr("a"), Order: 0}},
+			expected:  map[string]string{"a1": "a/1", "a2": "a/0"},
+			changed:   []string{"a1", "a2"},
+		},
+		"move to another parent": {
+			positions: []*models.MenuPosition{{Code: "a1", ParentCode: ptr("b"), Order: 0}},
+			expected:  map[string]string{"a": "/0", "b": "/1", "a1": "b/0", "a2": "a/0"},
+			changed:   []string{"a1", "a2"},
+		},
+		"move to root": {
+			positions: []*models.MenuPosition{{Code: "a2", Order: 1}},
+			expected:  map[string]string{"a": "/0", "a2": "/1", "b": "/2", "c": "/3", "a1": "a/0"},
+			changed:   []string{"a2", "b", "c"},
+		},
+		"unchanged": {
+			positions: []*models.MenuPosition{{Code: "b", Order: 1}},
+			expected:  map[string]string{"a": "/0", "b": "/1", "c": "/2"},
+		},
+		"under button":   {positions: []*models.MenuPosition{{Code: "c", ParentCode: ptr("save")}}, code: code.ErrMenuParentInvalid},
+		"button to root": {positions: []*models.MenuPosition{{Code: "save"}}, code: code.ErrMenuParentInvalid},
+		"cycle":          {positions: []*models.MenuPosition{{Code: "a", ParentCode: ptr("a2")}}, code: code.ErrMenuCycle},
+		"swap cycle": {
+			positions: []*models.MenuPosition{{Code: "b", ParentCode: ptr("c")}, {Code: "c", ParentCode: ptr("b")}},
+			code:      code.ErrMenuCycle,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			menus := tree()
+			changed, err := applyPositions(menus, tt.positions)
+			if tt.code != 0 {
+				assertCode(t, err, tt.code)
+				return
+			}
+			require.NoError(t, err)
+
+			codes := make(map[uint64]string, len(menus))
+			for _, menu := range menus {
+				codes[menu.MenuID] = menu.Code
+			}
+			for _, menu := range menus {
+				expected, ok := tt.expected[menu.Code]
+				if !ok {
+					continue
+				}
+				parent := ""
+				if menu.ParentID != nil {
+					parent = codes[*menu.ParentID]
+				}
+				assert.Equal(t, expected, fmt.Sprintf("%s/%d", parent, menu.Order), menu.Code)
+			}
+
+			var changedCodes []string
+			for _, menu := range changed {
+				changedCodes = append(changedCodes, menu.Code)
+				assert.Equal(t, uint64(2), menu.Version, menu.Code)
+			}
+			assert.ElementsMatch(t, tt.changed, changedCodes)
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"orca/models"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"sort"
+	"strings"
+)
+
+// maxLabelLength 菜单名称的最大长度，与 Menu.Validate 中的规则一致
+const maxLabelLength = 20
+
+// SyncPermissions 将路由声明的权限同步到菜单中：缺少的权限在编码与模块相同的目录下创建为按钮，
+// 目录不存在时一起创建。已存在的菜单不会被修改，没有路由声明的按钮作为孤立的按钮返回，不会被删除。
+// 编码与模块相同的菜单是按钮时，按钮不能作为父级菜单，该模块作为冲突返回，其下缺少的权限不会被创建。
+// dryRun 为 true 时只返回将要创建的菜单。
+func (s *MenuService) SyncPermissions(ctx context.Context, permissions []*models.Permission,
+	dryRun bool) (*models.PermissionSyncResult, error) {
+	result := &models.PermissionSyncResult{DryRun: dryRun, Directories: []string{}, Created: []string{}, Orphans: []string{},
+		Conflicts: []string{}}
+	err := s.unit.Do(ctx, func(ctx context.Context) error {
+		menus, err := s.menus.List(ctx)
+		if err != nil {
+			return errors.WrapC(err, code.ErrInternalServer, "同步权限时，查询菜单发生错误")
+		}
+		byCode := make(map[string]*models.Menu, len(menus))
+		labels := make(map[string]bool, len(menus))
+		for _, menu := range menus {
+			byCode[menu.Code] = menu
+			labels[menu.Label] = true
+		}
+
+		declared := make(map[string]bool, len(permissions))
+		conflicts := make(map[string]bool)
+		for _, permission := range permissions {
+			declared[permission.Code] = true
+			if _, ok := byCode[permission.Code]; ok {
+				continue
+			}
+
+			directory, ok := byCode[permission.Module]
+			if ok && directory.Type == models.EnumMenuTypeButton {
+				if !conflicts[permission.Module] {
+					conflicts[permission.Module] = true
+					result.Conflicts = append(result.Conflicts, permission.Module)
+				}
+				continue
+			}
+			if !ok {
+				directory = &models.Menu{
+					Code:   permission.Module,
+					Label:  uniqueLabel(labels, permission.Module),
+					Type:   models.EnumMenuTypeDirectory,
+					Show:   true,
+					Status: true,
+				}
+				if err := s.createPermissionMenu(ctx, directory, dryRun); err != nil {
+					return err
+				}
+				byCode[directory.Code] = directory
+				result.Directories = append(result.Directories, directory.Code)
+			}
+
+			parentID := directory.MenuID
+			button := &models.Menu{
+				Code:        permission.Code,
+				Label:       uniqueLabel(labels, permission.Description, permission.Code),
+				Type:        models.EnumMenuTypeButton,
+				ParentID:    &parentID,
+				Status:      true,
+				Description: strings.Join(permission.Routes, "\n"),
+			}
+			if err := s.createPermissionMenu(ctx, button, dryRun); err != nil {
+				return err
+			}
+			byCode[button.Code] = button
+			result.Created = append(result.Created, button.Code)
+		}
+
+		for _, menu := range menus {
+			if menu.Type == models.EnumMenuTypeButton && !declared[menu.Code] {
+				result.Orphans = append(result.Orphans, menu.Code)
+			}
+		}
+		sort.Strings(result.Orphans)
+		sort.Strings(result.Conflicts)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// createPermissionMenu 创建同步权限时缺少的目录或按钮，dryRun 为 true 时不创建
+func (s *MenuService) createPermissionMenu(ctx context.Context, menu *models.Menu, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+	if err := s.Create(ctx, menu); err != nil {
+		return errors.Wrapf(err, "同步权限时，创建菜单（code：%s）发生错误", menu.Code)
+	}
+	return nil
+}
+
+// uniqueLabel 返回 candidates 中第一个未被使用的菜单名称，并将其标记为已使用。
+// 菜单名称不能重复且有长度限制，候选名称会被截断，都被使用时返回最后一个候选名称，由创建菜单时报告冲突。
+func uniqueLabel(used map[string]bool, candidates ...string) string {
+	var label string
+	for _, candidate := range candidates {
+		label = candidate
+		if runes := []rune(label); len(runes) > maxLabelLength {
+			label = string(runes[:maxLabelLength])
+		}
+		if label != "" && !used[label] {
+			break
+		}
+	}
+	used[label] = true
+	return label
+}
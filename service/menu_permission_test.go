@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"orca/models"
+)
+
+func TestSyncPermissions(t *testing.T) {
+	s := newFakeMenuService()
+	ctx := context.Background()
+	menus := directory("menu", 0)
+	s.seed(menus)
+	s.seed(button("menu:create", menus, 0), button("menu:legacy", menus, 1))
+
+	permissions := []*models.Permission{
+		{Code: "menu:create", Module: "menu", Description: "创建菜单", Routes: []string{"POST /menu"}},
+		{Code: "menu:read", Module: "menu", Description: "查询菜单", Routes: []string{"GET /menu", "GET /menu/:code"}},
+		{Code: "trash:read", Module: "trash", Description: "查询菜单", Routes: []string{"GET /trash/:resource"}},
+	}
+	expected := &models.PermissionSyncResult{
+		Directories: []string{"trash"},
+		Created:     []string{"menu:read", "trash:read"},
+		Orphans:     []string{"menu:legacy"},
+		Conflicts:   []string{},
+	}
+
+	result, err := s.SyncPermissions(ctx, permissions, true)
+	require.NoError(t, err)
+	expected.DryRun = true
+	assert.Equal(t, expected, result)
+	assert.Nil(t, s.menu("menu:read"))
+	assert.Nil(t, s.menu("trash"))
+
+	result, err = s.SyncPermissions(ctx, permissions, false)
+	require.NoError(t, err)
+	expected.DryRun = false
+	assert.Equal(t, expected, result)
+
+	read := s.menu("menu:read")
+	assert.Equal(t, models.EnumMenuTypeButton, read.Type)
+	assert.Equal(t, &menus.MenuID, read.ParentID)
+	assert.Equal(t, "查询菜单", read.Label)
+	assert.Equal(t, "GET /menu\nGET /menu/:code", read.Description)
+	trash := s.menu("trash")
+	assert.Equal(t, models.EnumMenuTypeDirectory, trash.Type)
+	// 名称已被使用时使用权限编码
+	assert.Equal(t, "trash:read", s.menu("trash:read").Label)
+	assert.Equal(t, &trash.MenuID, s.menu("trash:read").ParentID)
+	assert.Equal(t, []models.MenuRevisionAction{models.EnumMenuRevisionActionCreate}, s.actions("menu:read"))
+	// 已存在的按钮不被修改，孤立的按钮不被删除
+	assert.Equal(t, "menu:create", s.menu("menu:create").Label)
+	assert.False(t, s.menu("menu:legacy").DeletedAt.Deleted())
+
+	// 再次同步时没有需要创建的菜单
+	result, err = s.SyncPermissions(ctx, permissions, false)
+	require.NoError(t, err)
+	assert.Empty(t, result.Directories)
+	assert.Empty(t, result.Created)
+}
+
+func TestSyncPermissionsWithButtonModule(t *testing.T) {
+	s := newFakeMenuService()
+	ctx := context.Background()
+	system := directory("system", 0)
+	s.seed(system)
+	s.seed(button("export", system, 0))
+
+	permissions := []*models.Permission{
+		{Code: "export:csv", Module: "export", Description: "导出CSV"},
+		{Code: "export:pdf", Module: "export", Description: "导出PDF"},
+		{Code: "menu:read", Module: "menu", Description: "查询菜单"},
+	}
+	result, err := s.SyncPermissions(ctx, permissions, false)
+	require.NoError(t, err)
+	// 按钮不能作为父级菜单，该模块作为冲突返回，其他模块的权限照常创建
+	assert.Equal(t, []string{"export"}, result.Conflicts)
+	assert.Equal(t, []string{"menu"}, result.Directories)
+	assert.Equal(t, []string{"menu:read"}, result.Created)
+	assert.Nil(t, s.menu("export:csv"))
+	assert.Nil(t, s.menu("export:pdf"))
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"orca/models"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/repository"
+)
+
+// Restore 从回收站恢复ID为 menuIDs 的菜单，恢复的菜单版本号加一并记录修订。
+// 存在不在回收站中的菜单时返回 ErrNotFound，父级菜单仍在回收站中且没有一起恢复时返回 ErrMenuParentInvalid，
+// 与现有菜单的名称或编码冲突时返回 ErrMenuAlreadyExist。
+func (s *MenuService) Restore(ctx context.Context, menuIDs []uint64) error {
+	return s.unit.Do(ctx, func(ctx context.Context) error {
+		menus, err := s.menus.List(ctx, repository.Unscoped(), repository.ForUpdate(),
+			repository.Where("menu_id in ? and deleted_at <> ?", menuIDs, 0))
+		if err != nil {
+			return errors.WrapC(err, code.ErrInternalServer, "恢复菜单时，查询菜单发生错误")
+		}
+		if len(menus) != len(menuIDs) {
+			return errors.WithCode(code.ErrNotFound, "存在不在回收站中的记录")
+		}
+
+		if err := s.checkRestoredParents(ctx, menus); err != nil {
+			return err
+		}
+
+		recorder, err := s.newRevisionRecorder(ctx, menuIDs...)
+		if err != nil {
+			return err
+		}
+		_, err = s.menus.Restore(ctx, repository.Where("menu_id in ?", menuIDs))
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return errors.WithCode(code.ErrMenuAlreadyExist, "恢复菜单时，菜单名称或编码与其他菜单冲突")
+		}
+		if err != nil {
+			return errors.WrapC(err, code.ErrInternalServer, "恢复菜单时发生错误")
+		}
+		// 菜单已被锁定，版本号不会被其他请求修改
+		for _, menu := range menus {
+			values := map[string]any{"version": menu.Version + 1}
+			if _, err := s.menus.UpdateColumns(ctx, values, repository.Where("menu_id = ?", menu.MenuID)); err != nil {
+				return errors.WrapC(err, code.ErrInternalServer, "恢复菜单（code：%s）时发生错误", menu.Code)
+			}
+		}
+		return recorder.record(ctx, models.EnumMenuRevisionActionRestore)
+	})
+}
+
+// checkRestoredParents 确认恢复的菜单的父级菜单未被删除或者一起恢复
+func (s *MenuService) checkRestoredParents(ctx context.Context, menus []*models.Menu) error {
+	restored := make(map[uint64]bool, len(menus))
+	for _, menu := range menus {
+		restored[menu.MenuID] = true
+	}
+	var parentIDs []uint64
+	for _, menu := range menus {
+		if menu.ParentID != nil && !restored[*menu.ParentID] {
+			parentIDs = append(parentIDs, *menu.ParentID)
+		}
+	}
+	if len(parentIDs) == 0 {
+		return nil
+	}
+
+	trashed, err := s.menus.List(ctx, repository.Unscoped(), repository.Select("menu_id", "code"),
+		repository.Where("menu_id in ? and deleted_at <> ?", parentIDs, 0))
+	if err != nil {
+		return errors.WrapC(err, code.ErrInternalServer, "恢复菜单时，查询父级菜单发生错误")
+	}
+	if len(trashed) > 0 {
+		return errors.WithCode(code.ErrMenuParentInvalid, "父级菜单（code：%s）仍在回收站中，请一起恢复", trashed[0].Code)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"orca/models"
+	"orca/pkg/code"
+)
+
+func TestRestore(t *testing.T) {
+	s := newFakeMenuService()
+	ctx := context.Background()
+	system := directory("system", 0)
+	require.NoError(t, s.Create(ctx, system))
+	_, _, err := s.Delete(ctx, []string{"system"}, MenuDeleteOptions{Mode: models.EnumMenuDeleteModeRestrict})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Restore(WithActor(ctx, 7), []uint64{system.MenuID}))
+	restored := s.menu("system")
+	assert.False(t, restored.DeletedAt.Deleted())
+	assert.Equal(t, uint64(2), restored.Version)
+	assert.Equal(t, []models.MenuRevisionAction{models.EnumMenuRevisionActionCreate,
+		models.EnumMenuRevisionActionDelete, models.EnumMenuRevisionActionRestore}, s.actions("system"))
+
+	// 已恢复的菜单不在回收站中
+	assertCode(t, s.Restore(ctx, []uint64{system.MenuID}), code.ErrNotFound)
+}
+
+func TestRestoreConflict(t *testing.T) {
+	s := newFakeMenuService()
+	ctx := context.Background()
+	system := directory("system", 0)
+	require.NoError(t, s.Create(ctx, system))
+	_, _, err := s.Delete(ctx, []string{"system"}, MenuDeleteOptions{Mode: models.EnumMenuDeleteModeRestrict})
+	require.NoError(t, err)
+	require.NoError(t, s.Create(ctx, directory("system", 0)))
+
+	assertCode(t, s.Restore(ctx, []uint64{system.MenuID}), code.ErrMenuAlreadyExist)
+	assert.True(t, s.menus.rows[0].DeletedAt.Deleted())
+	assert.Len(t, s.actions("system"), 1)
+}
+
+func TestRestoreWithTrashedParent(t *testing.T) {
+	s := newFakeMenuService()
+	ctx := context.Background()
+	system := directory("system", 0)
+	require.NoError(t, s.Create(ctx, system))
+	users := menu("users", system, 0)
+	require.NoError(t, s.Create(ctx, users))
+	_, _, err := s.Delete(ctx, []string{"system"}, MenuDeleteOptions{Mode: models.EnumMenuDeleteModeCascade})
+	require.NoError(t, err)
+
+	assertCode(t, s.Restore(ctx, []uint64{users.MenuID}), code.ErrMenuParentInvalid)
+	assert.True(t, s.menu("users").DeletedAt.Deleted())
+
+	require.NoError(t, s.Restore(ctx, []uint64{users.MenuID, system.MenuID}))
+	assert.False(t, s.menu("system").DeletedAt.Deleted())
+	assert.False(t, s.menu("users").DeletedAt.Deleted())
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"orca/models"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/query"
+	"orca/pkg/repository"
+	"sort"
+)
+
+// menuState 菜单在某一时刻的状态，包括已删除的菜单
+type menuState struct {
+	item    *models.MenuDocumentItem
+	deleted bool
+}
+
+// revisionRecorder 在修改前保存菜单的状态，修改完成后为发生变化的菜单写入修订记录。
+// 修改前后的状态都在同一个事务中查询，因此修订记录与修改一起提交或回滚。
+type revisionRecorder struct {
+	s      *MenuService
+	before map[uint64]*menuState
+	// rollbackOf 回滚时恢复的修订ID
+	rollbackOf *uint64
+}
+
+// newRevisionRecorder 保存将要修改的菜单的当前状态，新建的菜单不需要传入
+func (s *MenuService) newRevisionRecorder(ctx context.Context, menuIDs ...uint64) (*revisionRecorder, error) {
+	before, err := s.loadStates(ctx, menuIDs)
+	if err != nil {
+		return nil, errors.WrapC(err, code.ErrInternalServer, "记录菜单修订时发生错误")
+	}
+	return &revisionRecorder{s: s, before: before}, nil
+}
+
+// record 查询修改后的状态，为发生变化的菜单写入修订记录，menuIDs 为本次新建的菜单。
+// 修改前不存在的菜单记为创建，修改后被删除的菜单记为删除，其余的记为 action。
+// 操作人从 ctx 中读取，见 WithActor。
+func (r *revisionRecorder) record(ctx context.Context, action models.MenuRevisionAction, menuIDs ...uint64) error {
+	ids := make([]uint64, 0, len(r.before)+len(menuIDs))
+	for id := range r.before {
+		ids = append(ids, id)
+	}
+	for _, id := range menuIDs {
+		if _, ok := r.before[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	after, err := r.s.loadStates(ctx, ids)
+	if err != nil {
+		return errors.WrapC(err, code.ErrInternalServer, "记录菜单修订时发生错误")
+	}
+
+	var revisions []*models.MenuRevision
+	for _, id := range ids {
+		current, ok := after[id]
+		if !ok {
+			continue
+		}
+		previous, existed := r.before[id]
+		if !existed {
+			previous = &menuState{item: emptyItem()}
+		}
+
+		revision := &models.MenuRevision{
+			MenuID:     id,
+			Action:     action,
+			ActorID:    actor(ctx),
+			Snapshot:   models.NewMenuSnapshot(current.item),
+			Changes:    previous.item.Diff(current.item),
+			RollbackOf: r.rollbackOf,
+		}
+		switch {
+		case !existed:
+			revision.Action = models.EnumMenuRevisionActionCreate
+		case current.deleted && !previous.deleted:
+			revision.Action = models.EnumMenuRevisionActionDelete
+		case len(revision.Changes) == 0 && current.deleted == previous.deleted:
+			continue
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err := r.s.revisions.Create(ctx, revisions...); err != nil {
+		return errors.WrapC(err, code.ErrInternalServer, "记录菜单修订时发生错误")
+	}
+	return nil
+}
+
+// emptyItem 新建菜单之前的状态，角色绑定和翻译为空而不是 nil，使 Diff 能够比较这两个字段
+func emptyItem() *models.MenuDocumentItem {
+	return &models.MenuDocumentItem{Roles: make([]string, 0), Translations: models.Translations{}}
+}
+
+// loadStates 查询菜单（包括已删除的菜单）的完整状态
+func (s *MenuService) loadStates(ctx context.Context, menuIDs []uint64) (map[uint64]*menuState, error) {
+	states := make(map[uint64]*menuState, len(menuIDs))
+	if len(menuIDs) == 0 {
+		return states, nil
+	}
+
+	menus, err := s.menus.List(ctx, repository.Unscoped(), repository.Where("menu_id in ?", menuIDs))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.translations.Load(ctx, menus); err != nil {
+		return nil, err
+	}
+
+	parentIDs := make([]uint64, 0, len(menus))
+	for _, menu := range menus {
+		if menu.ParentID != nil {
+			parentIDs = append(parentIDs, *menu.ParentID)
+		}
+	}
+	var parents []*models.Menu
+	if len(parentIDs) > 0 {
+		parents, err = s.menus.List(ctx, repository.Unscoped(), repository.Select("menu_id", "code"),
+			repository.Where("menu_id in ?", parentIDs))
+		if err != nil {
+			return nil, err
+		}
+	}
+	parentCodes := make(map[uint64]string, len(parents))
+	for _, parent := range parents {
+		parentCodes[parent.MenuID] = parent.Code
+	}
+
+	roles, err := s.bindings.RoleCodes(ctx, menuIDs...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, menu := range menus {
+		var parentCode *string
+		if menu.ParentID != nil {
+			if code_, ok := parentCodes[*menu.ParentID]; ok {
+				parentCode = &code_
+			}
+		}
+		if menu.Translations == nil {
+			menu.Translations = models.Translations{}
+		}
+		states[menu.MenuID] = &menuState{
+			item:    models.NewMenuDocumentItem(menu, parentCode, rolesOf(roles, menu.MenuID)),
+			deleted: menu.DeletedAt != 0,
+		}
+	}
+	return states, nil
+}
+
+// FindWithDeleted 根据编码查询菜单，不存在未删除的菜单时返回最近删除的同编码菜单
+func (s *MenuService) FindWithDeleted(ctx context.Context, code_ string, scopes ...repository.Scope) (*models.Menu, error) {
+	scopes = append(scopes, repository.Unscoped(),
+		repository.Order("deleted_at = 0 desc"), repository.Order("deleted_at desc"))
+	return s.Get(ctx, code_, scopes...)
+}
+
+// Revisions 分页查询菜单的修订记录，已删除的菜单也可以查询
+func (s *MenuService) Revisions(ctx context.Context, code_ string, req *query.Request) (*query.Result[*models.MenuRevision], error) {
+	menu, err := s.FindWithDeleted(ctx, code_)
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := s.revisions.Page(ctx, req, repository.Where("menu_id = ?", menu.MenuID))
+	if err != nil {
+		return nil, errors.WrapC(err, code.ErrInternalServer, "查询菜单修订记录时发生错误")
+	}
+	return revisions, nil
+}
+
+// Rollback 在一个事务中将菜单恢复到指定修订时的状态，包括父级菜单、角色绑定和翻译，
+// 已删除的菜单会被恢复。回滚本身也会产生一条修订记录。
+// precondition 在菜单被锁定后调用，返回错误时放弃回滚，用于校验 If-Match。
+func (s *MenuService) Rollback(ctx context.Context, code_ string, revisionID uint64,
+	precondition func(menu *models.Menu) error) (*models.Menu, error) {
+	var menu *models.Menu
+	err := s.unit.Do(ctx, func(ctx context.Context) error {
+		var err error
+		if menu, err = s.FindWithDeleted(ctx, code_, repository.ForUpdate()); err != nil {
+			return err
+		}
+		if err := precondition(menu); err != nil {
+			return err
+		}
+
+		revision, err := s.revisions.Get(ctx, repository.Where("revision_id = ? and menu_id = ?", revisionID, menu.MenuID))
+		if errors.Is(err, repository.ErrNotFound) {
+			return errors.WithCode(code.ErrMenuRevisionNotFound, "菜单（code：%s）的修订（id：%d）不存在", code_, revisionID)
+		}
+		if err != nil {
+			return errors.WrapC(err, code.ErrInternalServer, "查询菜单修订时发生错误")
+		}
+		if revision.Action == models.EnumMenuRevisionActionDelete {
+			return errors.WithCode(code.ErrValidate, "不能回滚到删除菜单的修订")
+		}
+
+		recorder, err := s.newRevisionRecorder(ctx, menu.MenuID)
+		if err != nil {
+			return err
+		}
+		recorder.rollbackOf = &revision.RevisionID
+
+		item := revision.Snapshot.Item()
+		menuID, version := menu.MenuID, menu.Version
+		item.ApplyTo(menu)
+		if menu.Translations == nil {
+			menu.Translations = models.Translations{}
+		}
+		menu.ParentID = nil
+		if item.ParentCode != nil {
+			parent, err := s.menus.Get(ctx, repository.Where("code = ?", *item.ParentCode))
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.WithCode(code.ErrMenuParentInvalid, "父级菜单（code：%s）不存在", *item.ParentCode)
+			}
+			if err != nil {
+				return errors.WrapC(err, code.ErrInternalServer, "回滚菜单时，查询父级菜单发生错误")
+			}
+			menu.ParentID = &parent.MenuID
+		}
+		if err := s.validate(ctx, menu, "回滚菜单"); err != nil {
+			return err
+		}
+
+		roleIDs, err := s.roles.Resolve(ctx, item.Roles)
+		if err != nil {
+			return err
+		}
+		for _, role := range item.Roles {
+			if _, ok := roleIDs[role]; !ok {
+				return errors.WithCode(code.ErrValidate, "角色（code：%s）不存在", role)
+			}
+		}
+
+		// 先恢复已删除的菜单，恢复时与其他菜单的唯一索引冲突说明名称或编码已被占用
+		menu.Version = version + 1
+		_, err = s.menus.Restore(ctx, repository.Where("menu_id = ?", menuID))
+		if err == nil {
+			_, err = s.menus.Update(ctx, menu, nil)
+		}
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return errors.WithCode(code.ErrMenuAlreadyExist, "回滚菜单时，菜单名称或编码与其他菜单冲突")
+		}
+		if err != nil {
+			return errors.WrapC(err, code.ErrInternalServer, "回滚菜单失败")
+		}
+		menu.DeletedAt = 0
+		if err := s.bindRoles(ctx, menuID, item.Roles, roleIDs); err != nil {
+			return err
+		}
+		if err := s.translations.Save(ctx, menuID, menu.Translations); err != nil {
+			return errors.WrapC(err, code.ErrInternalServer, "保存菜单翻译时发生错误")
+		}
+		return recorder.record(ctx, models.EnumMenuRevisionActionRollback)
+	})
+	return menu, err
+}
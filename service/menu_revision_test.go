@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"orca/models"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/repository"
+)
+
+// noPrecondition 不校验 If-Match 的回滚前置条件
+func noPrecondition(*models.Menu) error { return nil }
+
+// revisionsOf 返回菜单的修订记录，按写入顺序排列
+func revisionsOf(t *testing.T, s *fakeMenuService, code_ string) []*models.MenuRevision {
+	revisions, err := s.revisions.List(context.Background(), repository.Where("menu_id = ?", s.menu(code_).MenuID))
+	require.NoError(t, err)
+	return revisions
+}
+
+func TestRollback(t *testing.T) {
+	s := newFakeMenuService()
+	ctx := context.Background()
+	admin := &models.Role{Code: "admin", Label: "admin", Status: true}
+	require.NoError(t, s.roles.Create(ctx, admin))
+	system, reports := directory("system", 0), directory("reports", 1)
+	require.NoError(t, s.Create(ctx, system))
+	require.NoError(t, s.Create(ctx, reports))
+	users := menu("users", system, 0)
+	users.Translations = models.Translations{"en": {Label: "Users"}}
+	require.NoError(t, s.Create(ctx, users))
+	created := revisionsOf(t, s, "users")[0]
+
+	users.Label = "用户"
+	users.ParentID = &reports.MenuID
+	users.Translations = models.Translations{}
+	require.NoError(t, s.Update(ctx, users, 1))
+	_, _, err := s.Delete(ctx, []string{"users"}, MenuDeleteOptions{Mode: models.EnumMenuDeleteModeRestrict})
+	require.NoError(t, err)
+
+	// 回滚到创建时的状态，已删除的菜单被恢复
+	rolledBack, err := s.Rollback(WithActor(ctx, 7), "users", created.RevisionID, noPrecondition)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), rolledBack.Version)
+	current, err := s.Get(ctx, "users")
+	require.NoError(t, err)
+	assert.Equal(t, "users", current.Label)
+	assert.Equal(t, &system.MenuID, current.ParentID)
+	assert.Equal(t, "Users", current.Translations["en"].Label)
+
+	revisions := revisionsOf(t, s, "users")
+	require.Len(t, revisions, 4)
+	last := revisions[3]
+	assert.Equal(t, models.EnumMenuRevisionActionRollback, last.Action)
+	assert.Equal(t, &created.RevisionID, last.RollbackOf)
+	assert.Equal(t, ptr(uint64(7)), last.ActorID)
+	assert.Equal(t, &models.FieldChange{From: "reports", To: "system"}, last.Changes["parentCode"])
+
+	// 不能回滚到删除的修订
+	_, err = s.Rollback(ctx, "users", revisions[2].RevisionID, noPrecondition)
+	assertCode(t, err, code.ErrValidate)
+	_, err = s.Rollback(ctx, "users", 100, noPrecondition)
+	assertCode(t, err, code.ErrMenuRevisionNotFound)
+	_, err = s.Rollback(ctx, "users", created.RevisionID, func(*models.Menu) error {
+		return errors.WithCode(code.ErrPreconditionFailed, "菜单已被修改")
+	})
+	assertCode(t, err, code.ErrPreconditionFailed)
+	assert.Len(t, revisionsOf(t, s, "users"), 4)
+}
+
+func TestRollbackWithDeletedParent(t *testing.T) {
+	s := newFakeMenuService()
+	ctx := context.Background()
+	system, reports := directory("system", 0), directory("reports", 1)
+	require.NoError(t, s.Create(ctx, system))
+	require.NoError(t, s.Create(ctx, reports))
+	users := menu("users", system, 0)
+	require.NoError(t, s.Create(ctx, users))
+	created := revisionsOf(t, s, "users")[0]
+
+	users.ParentID = &reports.MenuID
+	require.NoError(t, s.Update(ctx, users, 1))
+	_, _, err := s.Delete(ctx, []string{"system"}, MenuDeleteOptions{Mode: models.EnumMenuDeleteModeRestrict})
+	require.NoError(t, err)
+
+	_, err = s.Rollback(ctx, "users", created.RevisionID, noPrecondition)
+	assertCode(t, err, code.ErrMenuParentInvalid)
+	assert.Equal(t, &reports.MenuID, s.menu("users").ParentID)
+	assert.Equal(t, uint64(2), s.menu("users").Version)
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"orca/models"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/repository"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// directory 创建目录，menu 创建菜单，button 创建 parent 下的按钮
+func directory(code_ string, order uint) *models.Menu {
+	return &models.Menu{Code: code_, Label: code_, Type: models.EnumMenuTypeDirectory, Order: order, Show: true, Status: true}
+}
+
+func menu(code_ string, parent *models.Menu, order uint) *models.Menu {
+	m := &models.Menu{Code: code_, Label: code_, Type: models.EnumMenuTypeMenu, Order: order, Show: true, Status: true,
+		Route: ptr("/" + code_), Component: ptr(code_ + "/index")}
+	if parent != nil {
+		m.ParentID = &parent.MenuID
+	}
+	return m
+}
+
+func button(code_ string, parent *models.Menu, order uint) *models.Menu {
+	return &models.Menu{Code: code_, Label: code_, Type: models.EnumMenuTypeButton, Order: order, Status: true,
+		ParentID: &parent.MenuID}
+}
+
+// assertCode 断言 err 带有错误码 expected
+func assertCode(t *testing.T, err error, expected code.Code) {
+	t.Helper()
+	require.Error(t, err)
+	assert.True(t, errors.IsCode(err, expected), "%+v", err)
+}
+
+func TestCreate(t *testing.T) {
+	s := newFakeMenuService()
+	ctx := WithActor(context.Background(), 7)
+	system := directory("system", 0)
+	require.NoError(t, s.Create(ctx, system))
+
+	users := menu("users", system, 0)
+	users.Translations = models.Translations{"en": {Label: "Users"}}
+	require.NoError(t, s.Create(ctx, users))
+	assert.NotZero(t, users.MenuID)
+	assert.Equal(t, uint64(1), users.Version)
+
+	created, err := s.Get(ctx, "users")
+	require.NoError(t, err)
+	assert.Equal(t, &system.MenuID, created.ParentID)
+	assert.Equal(t, "Users", created.Translations["en"].Label)
+
+	revisions, err := s.revisions.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, models.EnumMenuRevisionActionCreate, revisions[1].Action)
+	assert.Equal(t, ptr(uint64(7)), revisions[1].ActorID)
+	assert.Equal(t, "system", *revisions[1].Snapshot.ParentCode)
+}
+
+func TestCreateRejectsInvalidMenus(t *testing.T) {
+	s := newFakeMenuService()
+	ctx := context.Background()
+	system := directory("system", 0)
+	require.NoError(t, s.Create(ctx, system))
+	save := button("save", system, 0)
+	require.NoError(t, s.Create(ctx, save))
+
+	assertCode(t, s.Create(ctx, directory("system", 1)), code.ErrMenuAlreadyExist)
+	assertCode(t, s.Create(ctx, menu("users", save, 0)), code.ErrValidate)
+	assertCode(t, s.Create(ctx, &models.Menu{Code: "x", Label: "x", Type: models.EnumMenuTypeButton}), code.ErrValidate)
+
+	// 失败的创建不会留下菜单或修订
+	menus, err := s.menus.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, menus, 2)
+	revisions, err := s.revisions.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, revisions, 2)
+}
+
+func TestNavigation(t *testing.T) {
+	s := newFakeMenuService()
+	ctx := context.Background()
+	admin := &models.Role{Code: "admin", Label: "admin", Status: true}
+	removed := &models.Role{Code: "removed", Label: "removed", Status: true, Model: models.Model{DeletedAt: 1}}
+	require.NoError(t, s.roles.Create(ctx, admin, removed))
+
+	system := directory("system", 0)
+	s.seed(system)
+	users, disabled := menu("users", system, 0), menu("disabled", system, 1)
+	disabled.Status = false
+	s.seed(users, disabled)
+	create, reports := button("user:create", users, 0), menu("reports", nil, 1)
+	s.seed(create, reports)
+	users.Translations = models.Translations{"en": {Label: "Users"}}
+	require.NoError(t, s.translations.Save(ctx, users.MenuID, users.Translations))
+
+	for _, m := range []*models.Menu{system, users, disabled, create} {
+		require.NoError(t, s.bindings.Replace(ctx, m.MenuID, []uint64{admin.RoleID}))
+	}
+	// 已删除的角色的绑定不生效
+	require.NoError(t, s.bindings.Replace(ctx, reports.MenuID, []uint64{removed.RoleID}))
+
+	nav, err := s.Navigation(ctx, []uint64{admin.RoleID, removed.RoleID}, "en")
+	require.NoError(t, err)
+	require.Len(t, nav.Routes, 1)
+	assert.Equal(t, "system", nav.Routes[0].Name)
+	require.Len(t, nav.Routes[0].Children, 1)
+	assert.Equal(t, "Users", nav.Routes[0].Children[0].Meta.Title)
+	assert.Equal(t, []string{"user:create"}, nav.Permissions)
+
+	nav, err = s.Navigation(ctx, []uint64{removed.RoleID}, "en")
+	require.NoError(t, err)
+	assert.Empty(t, nav.Routes)
+	assert.Empty(t, nav.Permissions)
+}
+
+func TestUpdate(t *testing.T) {
+	s := newFakeMenuService()
+	ctx := context.Background()
+	system, reports := directory("system", 0), directory("reports", 1)
+	require.NoError(t, s.Create(ctx, system))
+	require.NoError(t, s.Create(ctx, reports))
+	users := menu("users", system, 0)
+	require.NoError(t, s.Create(ctx, users))
+
+	users.Label = "用户管理"
+	users.Translations = models.Translations{"en": {Label: "Users"}}
+	require.NoError(t, s.Update(ctx, users, 1))
+	updated, err := s.Get(ctx, "users")
+	require.NoError(t, err)
+	assert.Equal(t, "用户管理", updated.Label)
+	assert.Equal(t, uint64(2), updated.Version)
+	assert.Equal(t, "Users", updated.Translations["en"].Label)
+
+	revisions, err := s.revisions.List(ctx, repository.Where("menu_id = ?", users.MenuID))
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, models.EnumMenuRevisionActionUpdate, revisions[1].Action)
+	assert.Equal(t, &models.FieldChange{From: "users", To: "用户管理"}, revisions[1].Changes["label"])
+
+	// 版本号已变化时不覆盖其他请求的修改
+	stale := menu("users", system, 0)
+	stale.MenuID = users.MenuID
+	assertCode(t, s.Update(ctx, stale, 1), code.ErrPreconditionFailed)
+	// 名称与其他菜单冲突
+	updated.Label = "reports"
+	assertCode(t, s.Update(ctx, updated, 2), code.ErrMenuAlreadyExist)
+	// 父级菜单不能是子孙菜单
+	system.ParentID = &users.MenuID
+	assertCode(t, s.Update(ctx, system, 1), code.ErrValidate)
+
+	current := s.menu("users")
+	assert.Equal(t, "用户管理", current.Label)
+	assert.Equal(t, uint64(2), current.Version)
+	assert.Equal(t, []models.MenuRevisionAction{models.EnumMenuRevisionActionCreate, models.EnumMenuRevisionActionUpdate},
+		s.actions("users"))
+}
+
+func TestPatch(t *testing.T) {
+	s := newFakeMenuService()
+	ctx := context.Background()
+	system := directory("system", 0)
+	require.NoError(t, s.Create(ctx, system))
+	users := menu("users", system, 0)
+	users.Translations = models.Translations{"en": {Label: "Users"}}
+	require.NoError(t, s.Create(ctx, users))
+
+	// 只更新 fields 中的字段
+	patched := *users
+	patched.Label = "用户管理"
+	patched.IconName = "user"
+	patched.Translations = nil
+	require.NoError(t, s.Patch(ctx, &patched, 1, []string{"label"}))
+	current, err := s.Get(ctx, "users")
+	require.NoError(t, err)
+	assert.Equal(t, "用户管理", current.Label)
+	assert.Equal(t, "", current.IconName)
+	assert.Equal(t, uint64(2), current.Version)
+	assert.Equal(t, "Users", current.Translations["en"].Label)
+
+	// translations 为 null 时删除所有翻译
+	require.NoError(t, s.Patch(ctx, &patched, 2, []string{"translations"}))
+	current, err = s.Get(ctx, "users")
+	require.NoError(t, err)
+	assert.Empty(t, current.Translations)
+
+	assertCode(t, s.Patch(ctx, &patched, 3, []string{"version"}), code.ErrValidate)
+	assertCode(t, s.Patch(ctx, &patched, 1, []string{"label"}), code.ErrPreconditionFailed)
+	assert.Equal(t, uint64(3), s.menu("users").Version)
+}
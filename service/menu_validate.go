@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"orca/models"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/repository"
+	"orca/pkg/validation"
+)
+
+var (
+	errParentNotFound = validation.NewError("validation_menu_parent_not_found", "父级菜单不存在")
+	errParentIsButton = validation.NewError("validation_menu_parent_is_button", "按钮下不能包含子菜单")
+	errParentCycle    = validation.NewError("validation_menu_parent_cycle", "父级菜单不能是自身或子孙菜单")
+)
+
+// validate 校验菜单字段，并查询数据库校验父级菜单存在、不是按钮且不会构成循环。
+// 字段验证错误使用 ErrValidate 包装，可以通过 errors.Cause 取得 validation.Errors。
+func (s *MenuService) validate(ctx context.Context, menu *models.Menu, action string) error {
+	err := menu.Validate()
+	if err == nil {
+		err = validation.ValidateStruct(
+			menu,
+			validation.Field(&menu.ParentID, validation.By(func(value any) error {
+				return s.validateParent(ctx, menu)
+			})))
+	}
+
+	var errs validation.Errors
+	if errors.As(err, &errs) {
+		return errors.WrapC(errs, code.ErrValidate, "%s时，字段验证错误", action)
+	}
+	return errors.WrapC(err, code.ErrInternalServer, "%s时，校验父级菜单发生错误", action)
+}
+
+func (s *MenuService) validateParent(ctx context.Context, menu *models.Menu) error {
+	if menu.ParentID == nil {
+		return nil
+	}
+
+	parent, err := s.menus.Get(ctx, repository.Where("menu_id = ?", *menu.ParentID))
+	if errors.Is(err, repository.ErrNotFound) {
+		return errParentNotFound
+	}
+	if err != nil {
+		return validation.NewInternalError(err)
+	}
+	if parent.Type == models.EnumMenuTypeButton {
+		return errParentIsButton
+	}
+
+	// 新建的菜单还没有ID，不会构成循环
+	if menu.MenuID == 0 {
+		return nil
+	}
+	visited := make(map[uint64]struct{})
+	for current := parent; ; {
+		if current.MenuID == menu.MenuID {
+			return errParentCycle
+		}
+		if _, ok := visited[current.MenuID]; ok || current.ParentID == nil {
+			return nil
+		}
+		visited[current.MenuID] = struct{}{}
+
+		next, err := s.menus.Get(ctx, repository.Where("menu_id = ?", *current.ParentID))
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return validation.NewInternalError(err)
+		}
+		current = next
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"orca/models"
+	"orca/pkg/uow"
+)
+
+// MenuTranslationRepository 菜单翻译的仓储
+type MenuTranslationRepository interface {
+	// Load 查询 menus 的翻译并填充到 Translations 中
+	Load(ctx context.Context, menus []*models.Menu) error
+	// Save 将菜单的翻译替换为 translations，translations 为 nil 时不做修改
+	Save(ctx context.Context, menuID uint64, translations models.Translations) error
+}
+
+// RoleMenuRepository 角色与菜单的绑定关系（role_menu）的仓储，查询时忽略已删除的角色
+type RoleMenuRepository interface {
+	// MenuIDs 查询角色绑定的菜单ID
+	MenuIDs(ctx context.Context, roleIDs []uint64) ([]uint64, error)
+	// RoleCodes 查询每个菜单绑定的角色编码，角色编码按字典序排列。menuIDs 为空时查询所有菜单。
+	RoleCodes(ctx context.Context, menuIDs ...uint64) (map[uint64][]string, error)
+	// Replace 将菜单的角色绑定替换为 roleIDs
+	Replace(ctx context.Context, menuID uint64, roleIDs []uint64) error
+}
+
+// UserRoleRepository 用户与角色的绑定关系（user_role）的仓储
+type UserRoleRepository interface {
+	// RoleIDs 查询用户拥有的已启用且未删除的角色ID
+	RoleIDs(ctx context.Context, userID uint64) ([]uint64, error)
+}
+
+type menuTranslationRepository struct {
+	db *gorm.DB
+}
+
+// NewMenuTranslationRepository 创建使用数据库连接 db 的菜单翻译仓储
+func NewMenuTranslationRepository(db *gorm.DB) MenuTranslationRepository {
+	return &menuTranslationRepository{db: db}
+}
+
+func (r *menuTranslationRepository) Load(ctx context.Context, menus []*models.Menu) error {
+	return models.LoadMenuTranslations(uow.Conn(ctx, r.db), menus)
+}
+
+func (r *menuTranslationRepository) Save(ctx context.Context, menuID uint64, translations models.Translations) error {
+	return models.SaveMenuTranslations(uow.Conn(ctx, r.db), menuID, translations)
+}
+
+type roleMenuRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleMenuRepository 创建使用数据库连接 db 的角色菜单绑定仓储
+func NewRoleMenuRepository(db *gorm.DB) RoleMenuRepository {
+	return &roleMenuRepository{db: db}
+}
+
+func (r *roleMenuRepository) bound(ctx context.Context) *gorm.DB {
+	return uow.Conn(ctx, r.db).Table("role_menu").
+		Joins("join roles on roles.role_id = role_menu.role_id and roles.deleted_at = 0")
+}
+
+func (r *roleMenuRepository) MenuIDs(ctx context.Context, roleIDs []uint64) ([]uint64, error) {
+	menuIDs := make([]uint64, 0)
+	if len(roleIDs) == 0 {
+		return menuIDs, nil
+	}
+	err := r.bound(ctx).Distinct().Where("role_menu.role_id in ?", roleIDs).
+		Pluck("role_menu.menu_id", &menuIDs).Error
+	return menuIDs, err
+}
+
+func (r *roleMenuRepository) RoleCodes(ctx context.Context, menuIDs ...uint64) (map[uint64][]string, error) {
+	var rows []struct {
+		MenuID uint64
+		Code   string
+	}
+	query := r.bound(ctx).Select("role_menu.menu_id, roles.code")
+	if len(menuIDs) > 0 {
+		query = query.Where("role_menu.menu_id in ?", menuIDs)
+	}
+	if err := query.Order("roles.code").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	roles := make(map[uint64][]string)
+	for _, row := range rows {
+		roles[row.MenuID] = append(roles[row.MenuID], row.Code)
+	}
+	return roles, nil
+}
+
+func (r *roleMenuRepository) Replace(ctx context.Context, menuID uint64, roleIDs []uint64) error {
+	tx := uow.Conn(ctx, r.db)
+	if err := tx.Exec("delete from role_menu where menu_id = ?", menuID).Error; err != nil {
+		return err
+	}
+	for _, roleID := range roleIDs {
+		if err := tx.Exec("insert into role_menu (role_id, menu_id) values (?, ?)", roleID, menuID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type userRoleRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRoleRepository 创建使用数据库连接 db 的用户角色绑定仓储
+func NewUserRoleRepository(db *gorm.DB) UserRoleRepository {
+	return &userRoleRepository{db: db}
+}
+
+func (r *userRoleRepository) RoleIDs(ctx context.Context, userID uint64) ([]uint64, error) {
+	var roleIDs []uint64
+	err := uow.Conn(ctx, r.db).Table("user_role").
+		Joins("join roles on roles.role_id = user_role.role_id").
+		Where("user_role.user_id = ? and roles.status = ? and roles.deleted_at = 0", userID, true).
+		Pluck("user_role.role_id", &roleIDs).Error
+	return roleIDs, err
+}
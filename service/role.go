@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"orca/models"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/repository"
+)
+
+// RoleService 角色相关的业务逻辑
+type RoleService struct {
+	roles repository.Interface[models.Role]
+}
+
+// NewRoleService 创建角色服务
+func NewRoleService(roles repository.Interface[models.Role]) *RoleService {
+	return &RoleService{roles: roles}
+}
+
+// Get 根据编码查询角色
+func (s *RoleService) Get(ctx context.Context, code_ string) (*models.Role, error) {
+	role, err := s.roles.Get(ctx, repository.Where("code = ?", code_))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errors.WithCode(code.ErrNotFound, "角色（code：%s）不存在", code_)
+	}
+	if err != nil {
+		return nil, errors.WrapC(err, code.ErrInternalServer, "查询角色时发生错误")
+	}
+	return role, nil
+}
+
+// Resolve 查询角色编码对应的角色ID，不存在的编码不会出现在结果中
+func (s *RoleService) Resolve(ctx context.Context, codes []string) (map[string]uint64, error) {
+	ids := make(map[string]uint64, len(codes))
+	if len(codes) == 0 {
+		return ids, nil
+	}
+	roles, err := s.roles.List(ctx, repository.Select("role_id", "code"), repository.Where("code in ?", codes))
+	if err != nil {
+		return nil, errors.WrapC(err, code.ErrInternalServer, "查询角色时发生错误")
+	}
+	for _, role := range roles {
+		ids[role.Code] = role.RoleID
+	}
+	return ids, nil
+}
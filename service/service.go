@@ -0,0 +1,22 @@
+// Package service 实现与 HTTP 无关的业务逻辑，可以被控制器、定时任务和命令行工具复用。
+//
+// 服务的方法都接收 context.Context，需要事务的操作通过构造时传入的工作单元（uow.Interface）执行，服务之间互相调用时共享同一个事务。
+// 服务返回的错误都带有 pkg/code 中的错误码，字段验证错误等详细信息可以通过 errors.Cause 取得。
+package service
+
+import "context"
+
+type actorKey struct{}
+
+// WithActor 返回携带操作人ID的 context，服务在记录修订等操作时从中读取操作人
+func WithActor(ctx context.Context, userID uint64) context.Context {
+	return context.WithValue(ctx, actorKey{}, userID)
+}
+
+// actor 返回 ctx 中的操作人ID，没有操作人时返回 nil
+func actor(ctx context.Context) *uint64 {
+	if userID, ok := ctx.Value(actorKey{}).(uint64); ok && userID != 0 {
+		return &userID
+	}
+	return nil
+}
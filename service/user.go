@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"orca/models"
+	"orca/pkg/code"
+	"orca/pkg/errors"
+	"orca/pkg/repository"
+)
+
+// UserService 用户相关的业务逻辑
+type UserService struct {
+	users repository.Interface[models.User]
+	roles UserRoleRepository
+}
+
+// NewUserService 创建用户服务
+func NewUserService(users repository.Interface[models.User], roles UserRoleRepository) *UserService {
+	return &UserService{users: users, roles: roles}
+}
+
+// Get 根据ID查询用户
+func (s *UserService) Get(ctx context.Context, userID uint64) (*models.User, error) {
+	user, err := s.users.Get(ctx, repository.Where("user_id = ?", userID))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errors.WithCode(code.ErrNotFound, "用户（id：%d）不存在", userID)
+	}
+	if err != nil {
+		return nil, errors.WrapC(err, code.ErrInternalServer, "查询用户时发生错误")
+	}
+	return user, nil
+}
+
+// RoleIDs 查询用户拥有的已启用且未删除的角色ID
+func (s *UserService) RoleIDs(ctx context.Context, userID uint64) ([]uint64, error) {
+	roleIDs, err := s.roles.RoleIDs(ctx, userID)
+	if err != nil {
+		return nil, errors.WrapC(err, code.ErrInternalServer, "查询用户角色时发生错误")
+	}
+	return roleIDs, nil
+}
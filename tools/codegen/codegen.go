@@ -56,6 +56,10 @@ var (
 	trimprefix = flag.String("trimprefix", "", "trim the `prefix` from the generated constant names")
 	buildTags  = flag.String("tags", "", "comma-separated list of build tags to apply")
 	doc        = flag.Bool("doc", false, "if true only generate error code documentation in markdown format")
+	scaffold   = flag.Bool("scaffold", false, "if true generate controller, routes, error codes and DDL for the model named by -type")
+	root       = flag.String("root", ".", "repository root used by -scaffold")
+	label      = flag.String("label", "", "human readable name of the model used by -scaffold; default type name")
+	force      = flag.Bool("force", false, "if true -scaffold overwrites existing files")
 )
 
 // Usage is a replacement usage function for the flags package.
@@ -63,6 +67,7 @@ func Usage() {
 	fmt.Fprintf(os.Stderr, "Usage of codegen:\n")
 	fmt.Fprintf(os.Stderr, "\tcodegen [flags] -type T [directory]\n")
 	fmt.Fprintf(os.Stderr, "\tcodegen [flags] -type T files... # Must be a single package\n")
+	fmt.Fprintf(os.Stderr, "\tcodegen -scaffold [-root dir] [-label name] [-force] -type T\n")
 	fmt.Fprintf(os.Stderr, "Flags:\n")
 	flag.PrintDefaults()
 }
@@ -76,6 +81,17 @@ func main() {
 		flag.Usage()
 		os.Exit(2)
 	}
+	if *scaffold {
+		names, err := generateScaffold(*root, *typeNames, *label, *force)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, name := range names {
+			log.Printf("生成 %s", name)
+		}
+		log.Printf("在 pkg/code 中执行 go generate 以注册新的错误码")
+		return
+	}
 	types := strings.Split(*typeNames, ",")
 	var tags []string
 	if len(*buildTags) > 0 {
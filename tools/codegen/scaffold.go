@@ -0,0 +1,772 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// scaffoldField 模型中对应数据库列的字段
+type scaffoldField struct {
+	Name       string // Go 字段名
+	Column     string // 数据库列名
+	JSON       string // JSON 字段名，同时作为列表接口的查询参数名
+	GoType     string // Go 类型，例如 string、*uint64、time.Time
+	SQLType    string // DDL 中的列定义，不包括列名和注释
+	Comment    string
+	PrimaryKey bool
+	Unique     bool
+	Operators  string // 列表接口允许的过滤操作符
+	Sortable   bool
+}
+
+// scaffoldModel 生成代码所需的模型信息
+type scaffoldModel struct {
+	Module   string // Go 模块路径
+	Type     string // 模型类型名，例如 Role
+	Package  string // 控制器包名，例如 role
+	Receiver string // 控制器方法的接收者名
+	Var      string // 模型变量名
+	Label    string // 资源的中文名称，用于错误信息和错误码描述
+	Table    string
+	Soft     bool // 是否嵌入 Model，嵌入时支持软删除
+	Validate bool // 模型是否实现了 Validate 方法
+	CodeBase int  // 错误码的起始值
+
+	PK     *scaffoldField   // 主键
+	Key    *scaffoldField   // 接口中用于定位资源的字段，存在 Code 字段时使用 Code，否则使用主键
+	Fields []*scaffoldField // 所有对应数据库列的字段，包括主键
+}
+
+// ParamName 路由中定位资源的参数名
+func (m *scaffoldModel) ParamName() string {
+	if m.Key.PrimaryKey {
+		return "id"
+	}
+	return m.Key.JSON
+}
+
+// Var 保存字段值的变量名
+func (f *scaffoldField) Var() string {
+	return strings.ToLower(f.Name[:1]) + f.Name[1:]
+}
+
+// ParamVar 保存路由参数的变量名，避免与 code 包重名
+func (m *scaffoldModel) ParamVar() string {
+	if name := m.ParamName(); name != "code" {
+		return name
+	}
+	return "code_"
+}
+
+// generateScaffold 读取 root/models 中名为 typeName 的模型，生成控制器、路由、错误码和 DDL，返回生成的文件。
+// 任意一个目标文件已存在且 force 为 false 时不写入任何文件并返回错误。
+func generateScaffold(root, typeName, label string, force bool) ([]string, error) {
+	model, err := parseModel(root, typeName)
+	if err != nil {
+		return nil, err
+	}
+	model.Label = label
+	if model.Label == "" {
+		model.Label = model.Type
+	}
+	if model.Module, err = modulePath(root); err != nil {
+		return nil, err
+	}
+	if model.CodeBase, err = nextCodeBase(filepath.Join(root, "pkg", "code"), model.Package+".go"); err != nil {
+		return nil, err
+	}
+
+	files := map[string]string{
+		filepath.Join("controller", model.Package, model.Package+".go"): controllerTemplate,
+		filepath.Join("controller", model.Package, "create.go"):         createTemplate,
+		filepath.Join("controller", model.Package, "get.go"):            getTemplate,
+		filepath.Join("controller", model.Package, "list.go"):           listTemplate,
+		filepath.Join("controller", model.Package, "update.go"):         updateTemplate,
+		filepath.Join("controller", model.Package, "delete.go"):         deleteTemplate,
+		filepath.Join("router", model.Package+".go"):                    routerTemplate,
+		filepath.Join("pkg", "code", model.Package+".go"):               codeTemplate,
+		filepath.Join("scripts", "sql", model.Table+".sql"):             sqlTemplate,
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if !force {
+		var existing []string
+		for _, name := range names {
+			if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+				existing = append(existing, name)
+			}
+		}
+		if len(existing) > 0 {
+			return nil, fmt.Errorf("以下文件已存在，使用 -force 覆盖：\n\t%s", strings.Join(existing, "\n\t"))
+		}
+	}
+
+	// 先渲染所有文件，任意一个模板出错时不写入文件
+	rendered := make(map[string][]byte, len(files))
+	for _, name := range names {
+		src, err := render(files[name], model, strings.HasSuffix(name, ".go"))
+		if err != nil {
+			return nil, fmt.Errorf("生成 %s 时发生错误：%v", name, err)
+		}
+		rendered[name] = src
+	}
+	for _, name := range names {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, rendered[name], 0o644); err != nil {
+			return nil, fmt.Errorf("写入 %s 时发生错误：%v", name, err)
+		}
+	}
+	return names, nil
+}
+
+func render(text string, model *scaffoldModel, gofmt bool) ([]byte, error) {
+	tmpl, err := template.New("scaffold").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, model); err != nil {
+		return nil, err
+	}
+	if !gofmt {
+		return buf.Bytes(), nil
+	}
+	return format.Source(buf.Bytes())
+}
+
+// parseModel 解析 root/models 目录，查找名为 typeName 的结构体
+func parseModel(root, typeName string) (*scaffoldModel, error) {
+	fset := token.NewFileSet()
+	paths, err := filepath.Glob(filepath.Join(root, "models", "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	model := &scaffoldModel{
+		Type:     typeName,
+		Package:  strings.ToLower(typeName),
+		Receiver: strings.ToLower(typeName[:1]),
+		Var:      strings.ToLower(typeName[:1]) + typeName[1:],
+		Table:    snakeCase(typeName) + "s",
+	}
+	var spec *ast.StructType
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, s := range decl.Specs {
+					if ts, ok := s.(*ast.TypeSpec); ok && ts.Name.Name == typeName {
+						if st, ok := ts.Type.(*ast.StructType); ok {
+							spec = st
+						}
+					}
+				}
+			case *ast.FuncDecl:
+				if decl.Recv == nil || receiverType(decl.Recv) != typeName {
+					continue
+				}
+				switch decl.Name.Name {
+				case "TableName":
+					if table, ok := returnedString(decl); ok {
+						model.Table = table
+					}
+				case "Validate":
+					model.Validate = true
+				}
+			}
+		}
+	}
+	if spec == nil {
+		return nil, fmt.Errorf("models 中不存在结构体 %s", typeName)
+	}
+
+	for _, field := range spec.Fields.List {
+		tag := reflect.StructTag("")
+		if field.Tag != nil {
+			value, _ := strconv.Unquote(field.Tag.Value)
+			tag = reflect.StructTag(value)
+		}
+		if len(field.Names) == 0 {
+			if ident, ok := field.Type.(*ast.Ident); ok && ident.Name == "Model" {
+				model.Soft = true
+			}
+			continue
+		}
+		f, ok := parseField(field, tag)
+		if !ok {
+			continue
+		}
+		model.Fields = append(model.Fields, f)
+		if f.PrimaryKey {
+			model.PK = f
+		}
+		if f.Name == "Code" && f.GoType == "string" {
+			model.Key = f
+		}
+	}
+	if model.PK == nil {
+		return nil, fmt.Errorf("结构体 %s 没有标记 primaryKey 的字段", typeName)
+	}
+	if model.Key == nil {
+		model.Key = model.PK
+	}
+	return model, nil
+}
+
+// parseField 解析对应数据库列的字段，关联关系、切片和被忽略的字段返回 false
+func parseField(field *ast.Field, tag reflect.StructTag) (*scaffoldField, bool) {
+	name := field.Names[0].Name
+	if !ast.IsExported(name) {
+		return nil, false
+	}
+	settings := gormSettings(tag.Get("gorm"))
+	if _, ok := settings["-"]; ok {
+		return nil, false
+	}
+	for _, key := range []string{"many2many", "foreignkey", "references", "serializer"} {
+		if _, ok := settings[key]; ok {
+			return nil, false
+		}
+	}
+
+	goType := typeString(field.Type)
+	base := strings.TrimPrefix(goType, "*")
+	switch {
+	case strings.HasPrefix(goType, "[]"), strings.HasPrefix(goType, "map["):
+		return nil, false
+	case goType != base && !isBasic(base):
+		// 指向结构体的指针是关联关系
+		return nil, false
+	}
+
+	f := &scaffoldField{
+		Name:       name,
+		Column:     settings["column"],
+		JSON:       strings.Split(tag.Get("json"), ",")[0],
+		GoType:     goType,
+		PrimaryKey: hasKey(settings, "primarykey"),
+	}
+	if f.Column == "" {
+		f.Column = snakeCase(name)
+	}
+	if f.JSON == "" || f.JSON == "-" {
+		f.JSON = strings.ToLower(name[:1]) + name[1:]
+	}
+	if field.Doc != nil {
+		f.Comment = strings.TrimSpace(strings.SplitN(field.Doc.Text(), "\n", 2)[0])
+	} else if field.Comment != nil {
+		f.Comment = strings.TrimSpace(field.Comment.Text())
+	}
+	if f.Comment == "" {
+		f.Comment = name
+	}
+	f.Unique = (name == "Code" || name == "Label") && base == "string"
+
+	sqlType := settings["type"]
+	switch {
+	case base == "string":
+		if sqlType == "" {
+			sqlType = "varchar(255)"
+		}
+		f.Operators = "query.Eq, query.In, query.Like"
+		f.Sortable = !strings.HasPrefix(goType, "*") && strings.HasPrefix(sqlType, "varchar")
+	case base == "bool":
+		if sqlType == "" {
+			sqlType = "boolean"
+		}
+		f.Operators = "query.Eq"
+	case base == "time.Time":
+		if sqlType == "" {
+			sqlType = "datetime"
+		}
+		f.Operators = "query.Gt, query.Gte, query.Lt, query.Lte"
+		f.Sortable = goType == base
+	default:
+		if sqlType == "" {
+			sqlType = "bigint"
+		}
+		if strings.HasPrefix(base, "uint") {
+			sqlType += " unsigned"
+		}
+		f.Operators = "query.Eq, query.In, query.Gt, query.Gte, query.Lt, query.Lte"
+		f.Sortable = goType == base
+	}
+
+	switch {
+	case f.PrimaryKey:
+		sqlType += " auto_increment"
+		f.Operators = "query.Eq, query.In"
+		f.Sortable = true
+	case strings.HasPrefix(goType, "*"), sqlType == "text":
+		sqlType += " default null"
+	case base == "bool":
+		sqlType += " not null default false"
+	case base == "string":
+		sqlType += " not null"
+	case base == "time.Time":
+		sqlType += " not null default current_timestamp"
+	default:
+		sqlType += " not null default 0"
+	}
+	f.SQLType = sqlType
+	return f, true
+}
+
+// gormSettings 解析 gorm 标签，键统一为小写
+func gormSettings(tag string) map[string]string {
+	settings := make(map[string]string)
+	for _, part := range strings.Split(tag, ";") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(part, ":")
+		settings[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+	return settings
+}
+
+func hasKey(settings map[string]string, key string) bool {
+	_, ok := settings[key]
+	return ok
+}
+
+func isBasic(goType string) bool {
+	switch goType {
+	case "string", "bool", "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64", "time.Time":
+		return true
+	}
+	return false
+}
+
+func typeString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return "*" + typeString(t.X)
+	case *ast.SelectorExpr:
+		return typeString(t.X) + "." + t.Sel.Name
+	case *ast.ArrayType:
+		return "[]" + typeString(t.Elt)
+	case *ast.MapType:
+		return "map[" + typeString(t.Key) + "]" + typeString(t.Value)
+	default:
+		return ""
+	}
+}
+
+func receiverType(recv *ast.FieldList) string {
+	if len(recv.List) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(typeString(recv.List[0].Type), "*")
+}
+
+// returnedString 返回形如 func (Role) TableName() string { return "roles" } 的方法返回的字符串
+func returnedString(decl *ast.FuncDecl) (string, bool) {
+	if decl.Body == nil || len(decl.Body.List) != 1 {
+		return "", false
+	}
+	ret, ok := decl.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return "", false
+	}
+	lit, ok := ret.Results[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	return value, err == nil
+}
+
+// snakeCase 与 GORM 默认的命名策略一致，例如 RoleID 转换为 role_id
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := unicode.IsLower(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (nextLower && unicode.IsUpper(runes[i-1])) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+var moduleRegexp = regexp.MustCompile(`(?m)^module\s+(\S+)`)
+
+// modulePath 读取 root/go.mod 中的模块路径
+func modulePath(root string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	groups := moduleRegexp.FindSubmatch(data)
+	if groups == nil {
+		return "", fmt.Errorf("go.mod 中没有 module 声明")
+	}
+	return string(groups[1]), nil
+}
+
+var codeBaseRegexp = regexp.MustCompile(`iota\s*\+\s*(\d+)`)
+
+// nextCodeBase 每个模块的错误码占用一段长度为 100 的区间，返回下一个未使用区间的起始值。
+// 覆盖已生成的错误码文件时忽略该文件，使重新生成的错误码保持不变。
+func nextCodeBase(dir, exclude string) (int, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return 0, err
+	}
+	max := 0
+	for _, path := range paths {
+		if filepath.Base(path) == exclude {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return 0, err
+		}
+		for _, groups := range codeBaseRegexp.FindAllSubmatch(data, -1) {
+			if value, _ := strconv.Atoi(string(groups[1])); value > max {
+				max = value
+			}
+		}
+	}
+	if max == 0 {
+		return 0, fmt.Errorf("%s 中没有错误码", dir)
+	}
+	return (max-1)/100*100 + 101, nil
+}
+
+var controllerTemplate = `package {{.Package}}
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"{{.Module}}/controller/menu"
+	"{{.Module}}/controller/navigation"
+	"{{.Module}}/models"
+	"{{.Module}}/pkg/cache"
+	"{{.Module}}/pkg/code"
+	"{{.Module}}/pkg/errors"
+	"{{.Module}}/pkg/repository"
+	"{{.Module}}/pkg/tracing"
+)
+
+type {{.Var}}Controller struct {
+	{{.Var}}s    repository.Interface[models.{{.Type}}]
+	navigation *navigation.Cache
+	responses  *cache.Cache
+}
+
+// New 创建{{.Label}}控制器，控制器只通过仓储接口访问数据，测试时可以传入假的实现。
+// {{.Label}}发生变化后通过 navigation 和 responses 使导航缓存和菜单的响应缓存失效。
+func New({{.Var}}s repository.Interface[models.{{.Type}}], navigation *navigation.Cache, responses *cache.Cache) *{{.Var}}Controller {
+	return &{{.Var}}Controller{ {{- .Var}}s: {{.Var}}s, navigation: navigation, responses: responses}
+}
+
+// invalidate 在创建、更新和删除{{.Label}}后调用，使导航缓存和菜单的响应缓存失效，失败时只记录警告。
+// TODO: 生成的代码假设{{.Label}}会影响导航（例如角色的删除会使其绑定的菜单不再可见），
+// 不影响导航和菜单时删除这里的失效；{{.Label}}的接口使用响应缓存时在这里使其标签失效。
+func ({{.Receiver}} *{{.Var}}Controller) invalidate(c *gin.Context) {
+	if err := {{.Receiver}}.navigation.Invalidate(c); err != nil {
+		tracing.Logger(c).Warn("清除导航缓存失败", zap.Error(err))
+	}
+	if err := {{.Receiver}}.responses.Invalidate(c, menu.CacheTag); err != nil {
+		tracing.Logger(c).Warn("清除菜单的响应缓存失败", zap.Error(err))
+	}
+}
+
+// get{{.Type}} 根据{{.ParamName}}查询{{.Label}}，返回的错误带有错误码
+func ({{.Receiver}} *{{.Var}}Controller) get{{.Type}}(ctx context.Context, {{.ParamVar}} string) (*models.{{.Type}}, error) {
+	{{.Var}}, err := {{.Receiver}}.{{.Var}}s.Get(ctx, repository.Where("{{.Key.Column}} = ?", {{.ParamVar}}))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errors.WithCode(code.Err{{.Type}}NotFound, "{{.Label}}（{{.ParamName}}：%s）不存在", {{.ParamVar}})
+	}
+	if err != nil {
+		return nil, errors.WrapC(err, code.ErrInternalServer, "查询{{.Label}}时发生错误")
+	}
+	return {{.Var}}, nil
+}
+`
+
+var createTemplate = `package {{.Package}}
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"{{.Module}}/models"
+	"{{.Module}}/pkg/code"
+	"{{.Module}}/pkg/errors"
+	"{{.Module}}/pkg/response"
+)
+
+func ({{.Receiver}} *{{.Var}}Controller) Create(c *gin.Context) {
+	var {{.Var}} models.{{.Type}}
+	if err := c.ShouldBind(&{{.Var}}); err != nil {
+		response.Fail(c, errors.WithCode(code.ErrBind, "创建{{.Label}}时，数据绑定错误"))
+		return
+	}
+	{{.Var}}.{{.PK.Name}} = 0
+{{if .Validate}}
+	if err := {{.Var}}.Validate(); err != nil {
+		response.FailWithData(c, errors.WithCode(code.ErrValidate, "创建{{.Label}}时，字段验证错误"), err)
+		return
+	}
+{{end}}
+	err := {{.Receiver}}.{{.Var}}s.Create(c, &{{.Var}})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		response.Fail(c, errors.WithCode(code.Err{{.Type}}AlreadyExist, "创建{{.Label}}时，资源发生冲突"))
+		return
+	}
+	if err != nil {
+		response.Fail(c, errors.WithCode(code.ErrInternalServer, "将{{.Label}}数据插入到数据库时发生错误"))
+		return
+	}
+
+	{{.Receiver}}.invalidate(c)
+
+	response.Success(c, {{.Var}}, "创建{{.Label}}成功")
+}
+`
+
+var getTemplate = `package {{.Package}}
+
+import (
+	"github.com/gin-gonic/gin"
+	"{{.Module}}/pkg/response"
+)
+
+func ({{.Receiver}} *{{.Var}}Controller) Get(c *gin.Context) {
+	{{.Var}}, err := {{.Receiver}}.get{{.Type}}(c, c.Param("{{.ParamName}}"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c, {{.Var}}, "查询{{.Label}}成功")
+}
+`
+
+var listTemplate = `package {{.Package}}
+
+import (
+	"github.com/gin-gonic/gin"
+	"{{.Module}}/models"
+	"{{.Module}}/pkg/errors"
+	"{{.Module}}/pkg/query"
+	"{{.Module}}/pkg/response"
+)
+
+// ListOptions {{.Label}}列表允许的排序和过滤字段，也用于生成接口文档中的查询参数
+var ListOptions = &query.Options{
+	Model: &models.{{.Type}}{},
+	Fields: map[string]query.Field{
+{{- range .Fields}}
+		"{{.JSON}}": {Column: "{{.Column}}", Operators: []query.Operator{ {{- .Operators -}} }{{if .Sortable}}, Sortable: true{{end}}},
+{{- end}}
+{{- if .Soft}}
+		"createdAt": {Column: "created_at", Operators: query.Range, Sortable: true},
+		"updatedAt": {Column: "updated_at", Operators: query.Range, Sortable: true},
+{{- end}}
+	},
+	Key: "{{.PK.Column}}",
+}
+
+// List 分页查询{{.Label}}列表，支持页码和游标分页，参数格式见 query 包
+func ({{.Receiver}} *{{.Var}}Controller) List(c *gin.Context) {
+	req, err := query.Parse(c.Request.URL.Query(), ListOptions)
+	if err != nil {
+		response.FailWithData(c, err, errors.Cause(err))
+		return
+	}
+
+	result, err := {{.Receiver}}.{{.Var}}s.Page(c, req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.Success(c, result, "查询{{.Label}}列表成功")
+}
+`
+
+var updateTemplate = `package {{.Package}}
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"{{.Module}}/pkg/code"
+	"{{.Module}}/pkg/errors"
+	"{{.Module}}/pkg/response"
+)
+
+func ({{.Receiver}} *{{.Var}}Controller) Update(c *gin.Context) {
+	{{.Var}}, err := {{.Receiver}}.get{{.Type}}(c, c.Param("{{.ParamName}}"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	{{.PK.Var}} := {{.Var}}.{{.PK.Name}}
+	if err := c.ShouldBind({{.Var}}); err != nil {
+		response.Fail(c, errors.WithCode(code.ErrBind, "更新{{.Label}}时，数据绑定错误"))
+		return
+	}
+	{{.Var}}.{{.PK.Name}} = {{.PK.Var}}
+{{if .Validate}}
+	if err := {{.Var}}.Validate(); err != nil {
+		response.FailWithData(c, errors.WithCode(code.ErrValidate, "更新{{.Label}}时，字段验证错误"), err)
+		return
+	}
+{{end}}
+	_, err = {{.Receiver}}.{{.Var}}s.Update(c, {{.Var}}, nil)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		response.Fail(c, errors.WithCode(code.Err{{.Type}}AlreadyExist, "更新{{.Label}}时，资源发生冲突"))
+		return
+	}
+	if err != nil {
+		response.Fail(c, errors.WithCode(code.ErrInternalServer, "更新{{.Label}}失败"))
+		return
+	}
+
+	{{.Receiver}}.invalidate(c)
+
+	response.Success(c, {{.Var}}, "更新{{.Label}}成功")
+}
+`
+
+var deleteTemplate = `package {{.Package}}
+
+import (
+	"github.com/gin-gonic/gin"
+	"{{.Module}}/pkg/code"
+	"{{.Module}}/pkg/errors"
+	"{{.Module}}/pkg/repository"
+	"{{.Module}}/pkg/response"
+)
+
+{{if .Soft}}// Delete 软删除{{.Label}}，已删除的记录保留在数据库中
+{{end}}func ({{.Receiver}} *{{.Var}}Controller) Delete(c *gin.Context) {
+	{{.ParamVar}} := c.Param("{{.ParamName}}")
+	deleted, err := {{.Receiver}}.{{.Var}}s.Delete(c, repository.Where("{{.Key.Column}} = ?", {{.ParamVar}}))
+	if err != nil {
+		response.Fail(c, errors.WithCode(code.ErrInternalServer, "删除{{.Label}}时，发生错误"))
+		return
+	}
+	if deleted == 0 {
+		response.Fail(c, errors.WithCode(code.Err{{.Type}}NotFound, "{{.Label}}（{{.ParamName}}：%s）不存在", {{.ParamVar}}))
+		return
+	}
+
+	{{.Receiver}}.invalidate(c)
+
+	response.Success(c, nil, "删除{{.Label}}成功")
+}
+`
+
+var routerTemplate = `package router
+
+import (
+	"{{.Module}}/app"
+	"{{.Module}}/controller/navigation"
+	"{{.Module}}/controller/{{.Package}}"
+	"{{.Module}}/models"
+	"{{.Module}}/pkg/code"
+	"{{.Module}}/pkg/openapi"
+	"{{.Module}}/pkg/query"
+	"{{.Module}}/pkg/repository"
+	"{{.Module}}/pkg/route"
+)
+
+func init() {
+	routes = append(routes, func(r *route.Router, a *app.App) {
+		{{.Var}}Controller := {{.Package}}.New(repository.New[models.{{.Type}}](a.Mysql), navigation.NewCache(a.Redis), a.Cache)
+
+		{{.Var}}s := r.Group("/{{.Package}}")
+		{{.Var}}s.POST("", "{{.Package}}:create", "创建{{.Label}}", &openapi.Operation{
+			Parameters: []*openapi.Parameter{idempotencyKey},
+			Request:    &models.{{.Type}}{},
+			Response:   &models.{{.Type}}{},
+			Errors:     append([]code.Code{code.ErrBind, code.ErrValidate, code.Err{{.Type}}AlreadyExist}, idempotencyErrors...),
+		}, {{.Var}}Controller.Create)
+		{{.Var}}s.GET("", "{{.Package}}:read", "查询{{.Label}}", &openapi.Operation{
+			Summary:  "分页查询{{.Label}}列表",
+			Query:    {{.Package}}.ListOptions,
+			Response: &query.Result[*models.{{.Type}}]{},
+			Errors:   []code.Code{code.ErrValidate},
+		}, {{.Var}}Controller.List)
+		{{.Var}}s.GET("/:{{.ParamName}}", "{{.Package}}:read", "查询{{.Label}}", &openapi.Operation{
+			Response: &models.{{.Type}}{},
+			Errors:   []code.Code{code.Err{{.Type}}NotFound},
+		}, {{.Var}}Controller.Get)
+		{{.Var}}s.PUT("/:{{.ParamName}}", "{{.Package}}:update", "更新{{.Label}}", &openapi.Operation{
+			Request:  &models.{{.Type}}{},
+			Response: &models.{{.Type}}{},
+			Errors:   []code.Code{code.ErrBind, code.ErrValidate, code.Err{{.Type}}NotFound, code.Err{{.Type}}AlreadyExist},
+		}, {{.Var}}Controller.Update)
+		{{.Var}}s.DELETE("/:{{.ParamName}}", "{{.Package}}:delete", "删除{{.Label}}", &openapi.Operation{
+			Errors: []code.Code{code.Err{{.Type}}NotFound},
+		}, {{.Var}}Controller.Delete)
+	})
+}
+`
+
+var codeTemplate = `package code
+
+// {{.Label}}相关的错误码，修改后需要执行 go generate 重新生成 register.go 和错误码文档
+const (
+	// Err{{.Type}}NotFound - 404: 未找到{{.Label}}。
+	Err{{.Type}}NotFound Code = iota + {{.CodeBase}}
+
+	// Err{{.Type}}AlreadyExist - 409: 已存在相同的{{.Label}}。
+	Err{{.Type}}AlreadyExist
+)
+`
+
+var sqlTemplate = "create table if not exists {{.Table}}\n" +
+	"(\n" +
+	"{{- range .Fields}}{{if .PrimaryKey}}\n    {{.Column}} {{.SQLType}} comment '{{.Comment}}',{{end}}{{end}}\n" +
+	"{{- if .Soft}}\n" +
+	"    created_at datetime not null default current_timestamp comment '创建时间',\n" +
+	"    updated_at datetime not null default current_timestamp on update current_timestamp comment '最后更新时间',\n" +
+	"    deleted_at bigint   not null default 0 comment '删除时间（毫秒时间戳，0表示未删除）',\n" +
+	"{{- end}}\n" +
+	"{{- range .Fields}}{{if not .PrimaryKey}}\n    `{{.Column}}` {{.SQLType}} comment '{{.Comment}}',{{end}}{{end}}\n" +
+	"\n" +
+	"    primary key ({{.PK.Column}})\n" +
+	"{{- if .Soft}},\n    index idx_{{.Table}}_created_at (created_at),\n    index idx_{{.Table}}_deleted_at (deleted_at){{end}}\n" +
+	"{{- $model := .}}{{range .Fields}}{{if .Unique}},\n    unique index idx_{{$model.Table}}_{{.Column}}{{if $model.Soft}}_deleted_at{{end}} ({{.Column}}{{if $model.Soft}}, deleted_at{{end}}){{end}}{{end}}\n" +
+	") engine = InnoDB\n" +
+	"  default charset = utf8mb4 comment ='{{.Label}}表';\n"
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// copyFiles 将 pattern 匹配的文件（不包括测试文件）复制到 dir
+func copyFiles(t *testing.T, pattern, dir string) {
+	t.Helper()
+	paths, err := filepath.Glob(pattern)
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("没有匹配 %s 的文件：%v", pattern, err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, filepath.Base(path)), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGenerateScaffold(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module orca\n\ngo 1.23\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	copyFiles(t, filepath.Join("..", "..", "models", "*.go"), filepath.Join(root, "models"))
+	copyFiles(t, filepath.Join("..", "..", "pkg", "code", "*.go"), filepath.Join(root, "pkg", "code"))
+
+	names, err := generateScaffold(root, "Role", "角色", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"controller/role/create.go", "controller/role/delete.go", "controller/role/get.go", "controller/role/list.go",
+		"controller/role/role.go", "controller/role/update.go", "pkg/code/role.go", "router/role.go", "scripts/sql/roles.sql",
+	}
+	if strings.Join(names, ",") != filepath.FromSlash(strings.Join(expected, ",")) {
+		t.Fatalf("生成的文件为 %v，期望 %v", names, expected)
+	}
+
+	generated := make(map[string][]byte, len(names))
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		generated[name] = data
+		if !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		// 生成的代码可以解析且已经格式化
+		formatted, err := format.Source(data)
+		if err != nil {
+			t.Fatalf("%s 无法解析：%v", name, err)
+		}
+		if !bytes.Equal(formatted, data) {
+			t.Errorf("%s 没有格式化", name)
+		}
+	}
+	router := string(generated[filepath.Join("router", "role.go")])
+	if !strings.Contains(router, `roles.POST("", "role:create", "创建角色", &openapi.Operation{`) {
+		t.Errorf("router/role.go 没有注册创建角色的路由：\n%s", router)
+	}
+
+	// 不使用 -force 时拒绝覆盖已有的文件，也不写入其他文件
+	modified := filepath.Join(root, "controller", "role", "get.go")
+	if err := os.WriteFile(modified, []byte("package role\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(root, "router", "role.go")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := generateScaffold(root, "Role", "角色", false); err == nil || !strings.Contains(err.Error(), "-force") {
+		t.Fatalf("覆盖已有文件时应返回错误，得到 %v", err)
+	}
+	if data, _ := os.ReadFile(modified); string(data) != "package role\n" {
+		t.Errorf("已有的文件被覆盖")
+	}
+	if _, err := os.Stat(filepath.Join(root, "router", "role.go")); !os.IsNotExist(err) {
+		t.Errorf("拒绝覆盖时写入了其他文件")
+	}
+
+	// 使用 -force 时重新生成，错误码保持不变
+	if _, err := generateScaffold(root, "Role", "角色", true); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, generated[name]) {
+			t.Errorf("重新生成的 %s 与第一次不同", name)
+		}
+	}
+}
+
+// copyTree 将 src 中除 .git 和 tools 以外的文件复制到 dir
+func copyTree(t *testing.T, src, dir string) {
+	t.Helper()
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if rel == ".git" || rel == "tools" {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(filepath.Join(dir, rel), 0o755)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, rel), data, 0o644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestGenerateScaffoldBuilds 在项目的副本中生成代码并编译，模板与项目中的包不一致时测试失败
+func TestGenerateScaffoldBuilds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("编译生成的代码较慢")
+	}
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("没有找到 go 命令")
+	}
+	root := t.TempDir()
+	copyTree(t, filepath.Join("..", ".."), root)
+
+	if _, err := generateScaffold(root, "Role", "角色", true); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(gobin, "build", "./...")
+	cmd.Dir = root
+	cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("生成的代码无法编译：%v\n%s", err, output)
+	}
+}